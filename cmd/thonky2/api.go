@@ -0,0 +1,264 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/internal/commands"
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	botstate "github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+)
+
+const (
+	// apiPath is where the HTTP API is served under.
+	apiPath = "/api/"
+	// maxEditBody is how many bytes the body of a request to edit a schedule can be.
+	maxEditBody = 1 << 16
+)
+
+// api serves teams and their schedules as JSON, and edits schedules like !set and !set_note do.
+// Every request needs a token from !api_token in its Authorization header, and can only see the token's team:
+//
+//	GET  /api/teams                    the token's team, in a list
+//	GET  /api/teams/<id>               a team
+//	GET  /api/teams/<id>/week          the team's week schedule
+//	GET  /api/teams/<id>/players       the players on the schedule and their availability
+//	GET  /api/teams/<id>/activities    the activities the week schedule can have
+//	GET  /api/teams/<id>/reminders     the team's reminder config
+//	GET  /api/teams/<id>/tournaments   links to the team's tournaments
+//	POST /api/teams/<id>/set           set activities or availability, ex. {"day": "monday", "hours": "4-6", "values": "Scrim"}
+//	POST /api/teams/<id>/set_note      set notes, ex. {"day": "monday", "hours": "4-6", "note": "Inked"}
+type api struct {
+	state *botstate.State
+}
+
+// apiTeam is a team as the API shows it.
+type apiTeam struct {
+	ID       int      `json:"id"`
+	GuildID  string   `json:"guild_id"`
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+}
+
+// apiReminders is a reminder config as the API shows it.
+type apiReminders struct {
+	Intervals       []int64  `json:"intervals"`
+	Activities      []string `json:"activities"`
+	AnnounceChannel string   `json:"announce_channel"`
+	RoleMention     string   `json:"role_mention"`
+}
+
+// apiEdit is the body of a request to edit a schedule. Player and Hours are optional, like in !set.
+type apiEdit struct {
+	Player string `json:"player"`
+	Day    string `json:"day"`
+	Hours  string `json:"hours"`
+	Values string `json:"values"`
+	Note   string `json:"note"`
+}
+
+func (a *api) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t, err := a.authenticate(r)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusUnauthorized, "missing or invalid token")
+		return
+	} else if err != nil {
+		log.Printf("error checking API token: %s\n", err)
+		writeError(w, http.StatusInternalServerError, "error checking token")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, apiPath), "/"), "/")
+	if parts[0] != "teams" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if len(parts) == 1 {
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, []apiTeam{teamJSON(t)})
+		}
+		return
+	}
+	// other teams are hidden instead of forbidden, so tokens can't find out which teams exist
+	if id, err := strconv.Atoi(parts[1]); err != nil || id != t.ID {
+		writeError(w, http.StatusNotFound, "no such team")
+		return
+	}
+	if len(parts) == 2 {
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, teamJSON(t))
+		}
+		return
+	}
+
+	switch parts[2] {
+	case "reminders":
+		if allowMethod(w, r, http.MethodGet) {
+			a.reminders(w, t)
+		}
+		return
+	case "tournaments":
+		if allowMethod(w, r, http.MethodGet) {
+			a.tournaments(w, t)
+		}
+		return
+	}
+
+	sched, err := a.schedule(t)
+	if err != nil {
+		log.Printf("error grabbing schedule for API: %s\n", err)
+		writeError(w, http.StatusInternalServerError, "error grabbing schedule")
+		return
+	} else if sched == nil {
+		writeError(w, http.StatusNotFound, "no schedule for this team")
+		return
+	}
+	switch parts[2] {
+	case "week":
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, sched.Week)
+		}
+	case "players":
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, sched.Players)
+		}
+	case "activities":
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, sched.ValidActivities)
+		}
+	case "set", "set_note":
+		if allowMethod(w, r, http.MethodPost) {
+			a.edit(w, r, sched, parts[2] == "set_note")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// authenticate returns the team a request's token is for, or sql.ErrNoRows if it doesn't have a valid token.
+func (a *api) authenticate(r *http.Request) (team.Team, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return team.Team{}, sql.ErrNoRows
+	}
+	return a.state.DB.APITokenTeam(commands.HashAPIToken(token))
+}
+
+// schedule returns a team's schedule, or nil if it doesn't have one.
+func (a *api) schedule(t team.Team) (*schedule.Schedule, error) {
+	spreadsheetID, err := a.state.DB.SpreadsheetID(t.ID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a.state.Schedules[spreadsheetID], err
+}
+
+func (a *api) reminders(w http.ResponseWriter, t team.Team) {
+	c, err := a.state.DB.ReminderConfig(t.ID)
+	if err != nil {
+		log.Printf("error grabbing reminders for API: %s\n", err)
+		writeError(w, http.StatusInternalServerError, "error grabbing reminders")
+		return
+	}
+	writeJSON(w, apiReminders{
+		Intervals:       c.Intervals,
+		Activities:      c.Activities,
+		AnnounceChannel: c.AnnounceChannel,
+		RoleMention:     c.RoleMention.String,
+	})
+}
+
+func (a *api) tournaments(w http.ResponseWriter, t team.Team) {
+	battlefy, gamebattles, err := a.state.DB.TournamentLinks(t.ID)
+	if err != nil {
+		log.Printf("error grabbing tournaments for API: %s\n", err)
+		writeError(w, http.StatusInternalServerError, "error grabbing tournaments")
+		return
+	}
+	writeJSON(w, map[string]string{"battlefy": battlefy, "gamebattles": gamebattles})
+}
+
+// edit makes the same change to a schedule as !set, or !set_note if note is true.
+func (a *api) edit(w http.ResponseWriter, r *http.Request, sched *schedule.Schedule, note bool) {
+	var body apiEdit
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEditBody)).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	day, err := command.ParseDay(body.Day)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	e := commands.Edit{Day: sched.Week.Weekday(int(day)), Values: body.Values, Note: note}
+	if note {
+		e.Values = body.Note
+	}
+	if body.Hours != "" {
+		hours, err := command.ParseTimeRange(body.Hours)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		e.Hours = &hours
+	}
+	for _, p := range sched.Players {
+		if strings.EqualFold(p.Name, body.Player) {
+			e.Player = p.Name
+		}
+	}
+	if e.Player == "" {
+		e.Player = body.Player
+	}
+
+	err = commands.EditSchedule(sched, e)
+	if invalid, ok := err.(*commands.InvalidEditError); ok {
+		writeError(w, http.StatusBadRequest, invalid.Reason)
+		return
+	} else if err != nil {
+		log.Printf("error editing schedule from API: %s\n", err)
+		writeError(w, http.StatusInternalServerError, "error editing schedule")
+		return
+	}
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+func teamJSON(t team.Team) apiTeam {
+	channels := []string(t.Channels)
+	if channels == nil {
+		channels = []string{}
+	}
+	return apiTeam{ID: t.ID, GuildID: t.GuildID, Name: t.Name, Channels: channels}
+}
+
+// allowMethod returns whether a request uses a method, responding with an error if it doesn't.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, "use "+method)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Printf("error writing API response: %s\n", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
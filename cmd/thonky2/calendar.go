@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/internal/commands"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	botstate "github.com/bigheadgeorge/thonky2/pkg/state"
+)
+
+// calendarPath is where calendar feeds are served under, followed by a feed's token and ".ics".
+const calendarPath = "/calendar/"
+
+// calendarFeed serves teams' week schedules as iCalendar files calendar apps can subscribe to.
+// Files are only made again after the schedule they're for changes.
+type calendarFeed struct {
+	state *botstate.State
+
+	mu    sync.Mutex
+	files map[string]*calendarFile
+}
+
+// calendarFile is a calendar made for a feed.
+type calendarFile struct {
+	spreadsheetID string
+	made          time.Time
+	b             []byte
+}
+
+func newCalendarFeed(s *botstate.State) *calendarFeed {
+	return &calendarFeed{state: s, files: make(map[string]*calendarFile)}
+}
+
+// changed forgets the calendars made from a schedule, so they're made again with its changes.
+func (f *calendarFeed) changed(sched *schedule.Schedule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for token, file := range f.files {
+		if file.spreadsheetID == sched.ID {
+			delete(f.files, token)
+		}
+	}
+}
+
+// file returns the calendar for a feed's token, making it if it hasn't been made since the schedule last changed.
+// It returns nil if there's no feed with the token.
+func (f *calendarFeed) file(token string) (*calendarFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if file, ok := f.files[token]; ok {
+		return file, nil
+	}
+
+	t, spreadsheetID, err := f.state.DB.CalendarTeam(token)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	sched := f.state.Schedules[spreadsheetID]
+	if sched == nil || sched.Week.Container == nil {
+		return nil, nil
+	}
+	file := &calendarFile{spreadsheetID: spreadsheetID, made: time.Now(), b: commands.TeamCalendar(f.state, t, sched)}
+	f.files[token] = file
+	return file, nil
+}
+
+func (f *calendarFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, calendarPath)
+	if !strings.HasSuffix(name, ".ics") {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := f.file(strings.TrimSuffix(name, ".ics"))
+	if err != nil {
+		log.Printf("error making calendar: %s\n", err)
+		http.Error(w, "error making calendar", http.StatusInternalServerError)
+		return
+	} else if file == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	http.ServeContent(w, r, name, file.made, bytes.NewReader(file.b))
+}
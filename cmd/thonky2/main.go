@@ -6,13 +6,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/bigheadgeorge/spreadsheet"
-	_ "github.com/bigheadgeorge/thonky2/internal/commands"
 	"github.com/bigheadgeorge/thonky2/pkg/command"
 	"github.com/bigheadgeorge/thonky2/pkg/db"
 	"github.com/bigheadgeorge/thonky2/pkg/reminders"
@@ -20,12 +21,14 @@ import (
 	botstate "github.com/bigheadgeorge/thonky2/pkg/state"
 	"github.com/bigheadgeorge/thonky2/pkg/team"
 	"github.com/bwmarrin/discordgo"
-	"github.com/jmoiron/sqlx"
 	"golang.org/x/oauth2/google"
 )
 
 var state botstate.State
 
+// calendars serves calendar feeds for teams' schedules, if they're turned on.
+var calendars = newCalendarFeed(&state)
+
 type config struct {
 	Token        string
 	GoogleAPIKey string `json:"google_api_key"`
@@ -33,10 +36,34 @@ type config struct {
 	Pw           string
 	Host         string
 	Database     string
+	// CalendarAddr is the address to serve calendar feeds on, ex. ":8080", or empty to not serve them.
+	CalendarAddr string `json:"calendar_addr"`
+	// CalendarURL is the public URL calendar feeds are served at. It defaults to http://<CalendarAddr>/calendar/.
+	CalendarURL string `json:"calendar_url"`
+	// APIAddr is the address to serve the HTTP API on, or empty to not serve it. It can be the same as CalendarAddr.
+	APIAddr string `json:"api_addr"`
+	// Storage is where teams and schedules are kept, either "postgres" (the default) or "memory". Nothing kept in
+	// memory is saved between runs, so it's only for trying thonky out.
+	Storage string
+	// Teams are the teams to start with when Storage is "memory".
+	Teams []seedTeam
+}
+
+// seedTeam is a team and its schedule to add to an in-memory store.
+type seedTeam struct {
+	GuildID string `json:"guild_id"`
+	// Name is the team's name, or empty for the guild's team.
+	Name     string
+	Channels []string
+	// Source and SpreadsheetID are where the team's schedule is, ex. "file" and "testdata/schedule.yaml".
+	Source        string
+	SpreadsheetID string `json:"spreadsheet_id"`
+	Timezone      string
 }
 
 func main() {
 	state.Schedules = make(map[string]*schedule.Schedule)
+	state.Voice = botstate.NewVoice()
 
 	if _, err := os.Open("config.json"); os.IsNotExist(err) {
 		panic(fmt.Errorf("no config file; rename config.json.example to config.json and fill the fields"))
@@ -50,36 +77,70 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	if config.Token == "" {
-		panic(fmt.Errorf("no token in config.json"))
-	} else if config.GoogleAPIKey == "" {
-		panic("no google api key in config.json")
+
+	switch config.Storage {
+	case "", "postgres":
+		handler, err := db.Open(config.User, config.Pw, config.Host, config.Database)
+		if err != nil {
+			panic(err)
+		}
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			err = migrate(handler, os.Args[2:])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+		err = handler.MigrateUp()
+		if err != nil {
+			panic(err)
+		}
+		state.DB = handler
+	case "memory":
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			fmt.Fprintln(os.Stderr, "nothing to migrate; storage is in memory")
+			os.Exit(1)
+		}
+		state.DB = seedMemory(config.Teams)
+		log.Printf("keeping %d teams in memory; nothing will be saved\n", len(config.Teams))
+	default:
+		panic(fmt.Errorf("unknown storage %q in config.json; use postgres or memory", config.Storage))
 	}
 
-	d, err := sqlx.Open("postgres", fmt.Sprintf("user=%s password=%s host=%s dbname=%s", config.User, config.Pw, config.Host, config.Database))
-	if err != nil {
-		panic(err)
+	if config.Token == "" {
+		panic(fmt.Errorf("no token in config.json"))
 	}
-	state.DB = &db.Handler{d}
 
+	// without a service account, only teams with schedules in files work
 	b, err = ioutil.ReadFile("service_account.json")
-	if err != nil {
-		panic(err)
-	}
-	c, err := google.JWTConfigFromJSON(b, spreadsheet.Scope, schedule.DriveScope)
-	if err != nil {
+	if err == nil {
+		c, err := google.JWTConfigFromJSON(b, spreadsheet.Scope, schedule.DriveScope)
+		if err != nil {
+			panic(err)
+		}
+		state.Client = c.Client(context.Background())
+		state.Service = spreadsheet.NewServiceWithClient(state.Client)
+	} else if os.IsNotExist(err) {
+		log.Println("no service_account.json; Google Sheets schedules are disabled")
+	} else {
 		panic(err)
 	}
-	state.Client = c.Client(context.Background())
-	state.Service = spreadsheet.NewServiceWithClient(state.Client)
 
 	state.Session, err = discordgo.New("Bot " + config.Token)
 	if err != nil {
 		panic(err)
 	}
+	// commands are read from message content, which is a privileged intent that has to be turned on for the bot
+	state.Session.Identify.Intents = discordgo.IntentsAllWithoutPrivileged | discordgo.IntentMessageContent
+
+	state.Messenger = state.Session
 
 	state.Session.AddHandler(messageCreate)
 	state.Session.AddHandler(ready)
+	state.Session.AddHandler(interactionCreate)
+	state.Session.AddHandler(guildCreate)
+	state.Session.AddHandler(voiceStateUpdate)
 
 	err = state.Session.Open()
 	if err != nil {
@@ -93,6 +154,32 @@ func main() {
 	reminders.Init()
 	reminders.Start()
 
+	// calendar feeds and the API share a server if they're on the same address
+	servers := make(map[string]*http.ServeMux)
+	serve := func(addr, pattern string, h http.Handler) {
+		if servers[addr] == nil {
+			servers[addr] = http.NewServeMux()
+		}
+		servers[addr].Handle(pattern, h)
+	}
+	if config.CalendarAddr != "" {
+		state.CalendarURL = config.CalendarURL
+		if state.CalendarURL == "" {
+			host := config.CalendarAddr
+			if strings.HasPrefix(host, ":") {
+				host = "localhost" + host
+			}
+			state.CalendarURL = "http://" + host + calendarPath
+		}
+		serve(config.CalendarAddr, calendarPath, calendars)
+	}
+	if config.APIAddr != "" {
+		serve(config.APIAddr, apiPath, &api{state: &state})
+	}
+	for addr, mux := range servers {
+		go serveHTTP(addr, mux)
+	}
+
 	log.Println("running")
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, os.Kill)
@@ -102,34 +189,46 @@ func main() {
 func ready(s *discordgo.Session, r *discordgo.Ready) {
 	log.Println("ready")
 
+	err := command.Register(&state)
+	if err != nil {
+		log.Printf("error registering slash commands: %s\n", err)
+	}
+
 	for _, guild := range r.Guilds {
-		var teams []*team.Team
-		err := state.DB.Select(&teams, "SELECT * FROM teams WHERE server_id = $1", guild.ID)
+		teams, err := state.DB.GuildTeams(guild.ID)
 		if err != nil {
 			log.Printf("error grabbing teams in guild [%s]: %s\n", guild.ID, err)
 			continue
 		}
 
-		var config reminders.Config
-		var spreadsheetID string
-		var updateInterval int
-		for _, team := range teams {
-			err = state.DB.Get(&config, "SELECT * FROM reminders WHERE team = $1", team.ID)
+		for i := range teams {
+			team := &teams[i]
+			config, err := state.DB.ReminderConfig(team.ID)
 			if err != nil {
 				log.Printf("error grabbing reminders for team %d: %s\n", team.ID, err)
 			} else {
-				reminders.AddReminder(reminders.Reminder{State: &state, Team: team, Config: &config})
+				reminders.AddReminder(reminders.Reminder{State: &state, Team: team, Config: config})
+			}
+			nag, err := state.DB.NagConfig(team.ID)
+			if err != nil {
+				log.Printf("error grabbing nag for team %d: %s\n", team.ID, err)
+			} else {
+				reminders.AddNag(reminders.Nag{State: &state, Team: team, Config: nag})
+			}
+			voice, err := state.DB.VoiceChannel(team.ID)
+			if err == nil && voice != "" {
+				reminders.AddAttendance(reminders.Attendance{State: &state, Team: team, Channel: voice})
 			}
 
-			err = state.DB.QueryRow("SELECT spreadsheet_id, update_interval FROM schedules WHERE team = $1", team.ID).Scan(&spreadsheetID, &updateInterval)
+			c, err := state.DB.ScheduleConfig(team.ID)
 			if err != nil {
 				log.Printf("error grabbing spreadsheet info for team %d: %s\n", team.ID, err)
-			} else if state.Schedules[spreadsheetID] == nil {
-				state.Schedules[spreadsheetID], err = fetchSchedule(&state, spreadsheetID, updateInterval)
+			} else if state.Schedules[c.SpreadsheetID] == nil {
+				state.Schedules[c.SpreadsheetID], err = fetchSchedule(&state, c)
 				if err != nil {
 					log.Printf("error grabbing spreadsheet for team %d: %s\n", team.ID, err)
 				} else {
-					log.Printf("grabbed spreadsheet [%s]\n", spreadsheetID)
+					log.Printf("grabbed spreadsheet [%s]\n", c.SpreadsheetID)
 				}
 			}
 
@@ -138,23 +237,67 @@ func ready(s *discordgo.Session, r *discordgo.Ready) {
 	}
 }
 
+// seedMemory returns an in-memory store with teams and their schedules in it.
+func seedMemory(teams []seedTeam) *db.Memory {
+	m := db.NewMemory()
+	for _, seed := range teams {
+		t := m.NewTeam(team.Team{GuildID: seed.GuildID, Name: seed.Name, Channels: seed.Channels})
+		if seed.SpreadsheetID == "" {
+			continue
+		}
+		source := seed.Source
+		if source == "" {
+			source = "sheets"
+		}
+		m.SetScheduleConfig(team.ScheduleConfig{
+			Team:           t.ID,
+			SpreadsheetID:  seed.SpreadsheetID,
+			Source:         source,
+			Timezone:       seed.Timezone,
+			UpdateInterval: 5,
+		})
+	}
+	return m
+}
+
+// serveHTTP serves a handler on an address until the server fails. Slow clients are cut off instead of holding
+// connections open.
+func serveHTTP(addr string, h http.Handler) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	log.Printf("serving HTTP on %s\n", addr)
+	log.Println(server.ListenAndServe())
+}
+
 func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID {
 		return
 	}
 
-	if strings.HasPrefix(m.Content, "!") {
-		args := strings.Split(m.Content, " ")
-		for _, c := range command.Commands {
-			if c.Match(args[0][1:]) {
-				msg, err := c.Call(&state, m, args)
-				if err != nil {
-					log.Println(err)
-				}
-				if msg != "" {
-					s.ChannelMessageSend(m.ChannelID, msg)
-				}
-			}
+	command.Dispatch(&state, m)
+}
+
+// guildCreate records who's already in voice in a guild when it becomes available, since voice state updates only
+// come when someone joins, moves or leaves.
+func guildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
+	for _, vs := range g.VoiceStates {
+		if vs.GuildID == "" {
+			vs.GuildID = g.ID
 		}
+		state.Voice.Update(vs)
 	}
 }
+
+func voiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	state.Voice.Update(v.VoiceState)
+}
+
+func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	command.HandleInteraction(&state, i)
+}
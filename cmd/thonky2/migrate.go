@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bigheadgeorge/thonky2/pkg/db"
+)
+
+const migrateUsage = `usage: thonky2 migrate <command>
+
+commands:
+  status        show the schema version and the migrations it has
+  up            apply every migration the database doesn't have
+  down          undo the last migration
+  to <version>  apply or undo migrations until the schema is at a version`
+
+// migrate runs the migrate subcommand, which shows or changes the database's schema version.
+func migrate(d *db.Handler, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	migrations, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "status" && len(args) == 1:
+		fmt.Printf("schema version %d of %d\n", version, db.LatestVersion())
+		for _, m := range migrations {
+			applied := " "
+			if m.Version <= version {
+				applied = "x"
+			}
+			fmt.Printf("[%s] %04d_%s\n", applied, m.Version, m.Name)
+		}
+		return nil
+	case args[0] == "up" && len(args) == 1:
+		return d.Migrate(db.LatestVersion())
+	case args[0] == "down" && len(args) == 1:
+		if version == 0 {
+			return fmt.Errorf("no migrations to undo")
+		}
+		return d.Migrate(version - 1)
+	case args[0] == "to" && len(args) == 2:
+		target, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return d.Migrate(target)
+	}
+	return errors.New(migrateUsage)
+}
@@ -2,22 +2,55 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"time"
 
-	"github.com/bigheadgeorge/thonky2/pkg/db"
+	"github.com/bigheadgeorge/thonky2/internal/commands"
 	"github.com/bigheadgeorge/thonky2/pkg/schedule"
 	botstate "github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
 )
 
-func fetchSchedule(s *botstate.State, spreadsheetID string, updateInterval int) (*schedule.Schedule, error) {
-	schedule, err := schedule.New(s.Service, s.Client, spreadsheetID)
+// scheduleSource returns the source a schedule is kept in.
+// Sheets sources are spreadsheet IDs laid out like layout, and file sources are paths to JSON or YAML files.
+func scheduleSource(s *botstate.State, source, id string, layout []byte) (schedule.Source, error) {
+	switch source {
+	case "sheets":
+		if s.Service == nil {
+			return nil, fmt.Errorf("no Google service account, can't grab spreadsheet [%s]", id)
+		}
+		l, err := schedule.ParseLayout(layout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid layout for [%s]: %s", id, err)
+		}
+		return schedule.NewSheets(s.Service, s.Client, id, l)
+	case "file":
+		return schedule.NewFile(id), nil
+	}
+	return nil, fmt.Errorf("unknown schedule source %q", source)
+}
+
+// fetchSchedule grabs a team's schedule from its source or the cache, and keeps it updated in the background.
+func fetchSchedule(s *botstate.State, c team.ScheduleConfig) (*schedule.Schedule, error) {
+	src, err := scheduleSource(s, c.Source, c.SpreadsheetID, c.Layout)
 	if err != nil {
 		return nil, err
 	}
+	var loc *time.Location
+	if c.Timezone != "" {
+		loc, err = schedule.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone for [%s]: %s", c.SpreadsheetID, err)
+		}
+	}
+	schedule, err := schedule.New(src)
+	if err != nil {
+		return nil, err
+	}
+	schedule.SetLocation(loc)
 
-	var modified time.Time
-	err = s.DB.Get(&modified, "SELECT modified FROM cache WHERE id = $1", schedule.ID)
+	modified, err := s.DB.CacheModified(schedule.ID)
 	var update bool
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -49,11 +82,23 @@ func fetchSchedule(s *botstate.State, spreadsheetID string, updateInterval int)
 		}
 	}
 
-	go monitorSchedule(s.DB, schedule, updateInterval)
+	schedule.OnChange(refreshPosts(s))
+	schedule.OnChange(calendars.changed)
+	go monitorSchedule(s, schedule, c.UpdateInterval)
 	return schedule, nil
 }
 
-func monitorSchedule(db *db.Handler, schedule *schedule.Schedule, updateInterval int) {
+// refreshPosts returns a listener that keeps the messages pinned with !pin_schedule up to date.
+func refreshPosts(s *botstate.State) func(*schedule.Schedule) {
+	return func(sched *schedule.Schedule) {
+		commands.RefreshSchedulePosts(s, sched)
+	}
+}
+
+// maxChanges is the most changes announced at once, so announcements fit in a message.
+const maxChanges = 20
+
+func monitorSchedule(s *botstate.State, schedule *schedule.Schedule, updateInterval int) {
 	for {
 		time.Sleep(time.Duration(updateInterval) * time.Minute)
 		updated, err := schedule.Updated()
@@ -61,14 +106,40 @@ func monitorSchedule(db *db.Handler, schedule *schedule.Schedule, updateInterval
 			log.Println(err)
 		} else if !updated {
 			log.Printf("bg updating [%s]\n", schedule.ID)
+			oldWeek, oldPlayers := schedule.Week, schedule.Players
 			err = schedule.Update()
 			if err != nil {
 				log.Println(err)
+				continue
 			}
-			err = db.CacheSchedule(schedule)
+			err = s.DB.CacheSchedule(schedule)
 			if err != nil {
 				log.Println(err)
 			}
+			announceChanges(s, schedule, &oldWeek, oldPlayers)
+		}
+	}
+}
+
+// announceChanges posts what changed on a schedule since the week and players it had before in each team's changes channel.
+func announceChanges(s *botstate.State, sched *schedule.Schedule, oldWeek *schedule.Week, oldPlayers []schedule.Player) {
+	if oldWeek.Container == nil {
+		return
+	}
+	changes := schedule.Diff(oldWeek, oldPlayers, &sched.Week, sched.Players)
+	if len(changes) == 0 {
+		return
+	}
+	channels, err := s.DB.ChangesChannels(sched.ID)
+	if err != nil {
+		log.Printf("error grabbing changes channels for [%s]: %s\n", sched.ID, err)
+		return
+	}
+	msg := "**Schedule changes:**\n" + schedule.FormatChanges(&sched.Week, changes, maxChanges)
+	for _, channel := range channels {
+		_, err = s.Messenger.ChannelMessageSend(channel, msg)
+		if err != nil {
+			log.Printf("error announcing changes in [%s]: %s\n", channel, err)
 		}
 	}
 }
@@ -1,14 +1,16 @@
 module github.com/bigheadgeorge/thonky2
 
-go 1.13
+go 1.16
 
 require (
 	github.com/bigheadgeorge/goverbuff v0.0.0-20200219035635-7d5592e4a0a6
 	github.com/bigheadgeorge/spreadsheet v0.0.0-20191122095212-08231195c43b
-	github.com/bwmarrin/discordgo v0.20.2
+	github.com/bwmarrin/discordgo v0.27.1
 	github.com/jmoiron/sqlx v1.2.0
 	github.com/lib/pq v1.3.0
-	github.com/robfig/cron v1.2.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.4.0 // indirect
+	golang.org/x/image v0.18.0
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	gopkg.in/yaml.v2 v2.4.0
 )
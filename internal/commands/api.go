@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	examples := [][2]string{
+		{"!api_token", "DM you a new token for the team's HTTP API."},
+		{"!api_token revoke", "Stop every token for the team from working."},
+	}
+	command.AddCommand("api_token", "Make or revoke tokens for reading and editing the schedule over HTTP.", examples, APIToken).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+	).SetPermission(command.Admin)
+}
+
+// APIToken DMs a new HTTP API token for a team, or revokes all of them.
+func APIToken(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+
+	switch option := strings.ToLower(args.String("option")); option {
+	case "":
+		token, err := newToken()
+		if err != nil {
+			return "Error making a token.", err
+		}
+		channel, err := s.Messenger.UserChannelCreate(m.Author.ID)
+		if err != nil {
+			return "Error DMing you; are DMs from server members turned on?", err
+		}
+		err = s.DB.AddAPIToken(t.ID, HashAPIToken(token))
+		if err != nil {
+			return "Error saving the token.", err
+		}
+		_, err = s.Messenger.ChannelMessageSend(channel.ID, "Your API token is `"+token+"`. Send it in the Authorization header as `Bearer "+token+"`, and keep it secret; it can edit the schedule.")
+		if err != nil {
+			return "Error DMing you the token.", err
+		}
+		return "Sent you a token in DMs.", nil
+	case "revoke":
+		err := s.DB.RemoveAPITokens(t.ID)
+		if err != nil {
+			return "Error revoking tokens.", err
+		}
+		return "Revoked every API token for the team.", nil
+	default:
+		return fmt.Sprintf("Invalid option for !api_token: %q; use revoke or nothing.", option), nil
+	}
+}
+
+// HashAPIToken returns the hash API tokens are saved as.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/reminders"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// attendanceDays is how many days back !attendance reports on by default.
+	attendanceDays = 30
+	// maxNoShows is how many of each player's latest no-shows !attendance lists.
+	maxNoShows = 3
+)
+
+func init() {
+	examples := [][2]string{
+		{"!attendance", fmt.Sprintf("Show how reliably players showed up over the last %d days, and their no-shows.", attendanceDays)},
+		{"!attendance 7", "Only count the last 7 days."},
+		{"!attendance channel <#500000000000000000>", "Take attendance in a voice channel during blocks with an activity on."},
+		{"!attendance off", "Stop taking attendance."},
+	}
+	command.AddCommand("attendance", "Show or take attendance in the team's voice channel.", examples, Attendance).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+		command.Arg{Name: "values", Type: command.ArgRest, Optional: true},
+	).SetPermission(command.Captain)
+}
+
+// Attendance reports how reliably the linked players on the team in a channel showed up to blocks they said they
+// were available for, or sets the voice channel attendance is taken in.
+func Attendance(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	option := strings.ToLower(args.String("option"))
+	var channel, reply string
+	switch option {
+	case "channel":
+		id, err := command.ParseChannel(args.String("values"))
+		if err != nil {
+			return err.Error(), nil
+		}
+		c, err := s.Messenger.Channel(id)
+		if err != nil || c.GuildID != m.GuildID {
+			return "No channel <#" + id + "> in this server.", nil
+		} else if c.Type != discordgo.ChannelTypeGuildVoice {
+			return "<#" + id + "> isn't a voice channel.", nil
+		}
+		channel, reply = id, "Attendance will be taken in <#"+id+"> during blocks with an activity on. Players need to link themselves to the schedule with `!link` to be counted."
+	case "off":
+		reply = "Stopped taking attendance."
+	default:
+		days := attendanceDays
+		if option != "" {
+			var err error
+			days, err = strconv.Atoi(option)
+			if err != nil || days < 1 {
+				return fmt.Sprintf("Invalid option for !attendance: %q; use channel, off or a number of days.", option), nil
+			}
+		}
+		return attendanceReport(s, t.ID, sched, days)
+	}
+
+	err := s.DB.SetVoiceChannel(t.ID, channel)
+	if err != nil {
+		return "Error saving the voice channel.", err
+	}
+	err = reminders.AddAttendance(reminders.Attendance{State: s, Team: &t, Channel: channel})
+	if err != nil {
+		return "Saved the voice channel, but couldn't schedule taking attendance. :(", err
+	}
+	log.Printf("taking attendance for team %d in [%s]\n", t.ID, channel)
+	return reply, nil
+}
+
+// attendanceReport lists each player's reliability over the last few days, least reliable first.
+func attendanceReport(s *state.State, teamID int, sched *schedule.Schedule, days int) (string, error) {
+	records, err := s.DB.Attendance(teamID, sched.Week.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return "Error grabbing attendance.", err
+	}
+	records = sched.Week.Finished(records)
+	if len(records) == 0 {
+		channel, err := s.DB.VoiceChannel(teamID)
+		if err != nil {
+			return "Error grabbing the voice channel.", err
+		} else if channel == "" {
+			return "Attendance isn't being taken; start with `!attendance channel <voice channel>`.", nil
+		}
+		return fmt.Sprintf("No attendance taken in the last %d days.", days), nil
+	}
+
+	blocks := make(map[int64]bool)
+	for _, a := range records {
+		blocks[a.Block.Unix()] = true
+	}
+	lines := []string{fmt.Sprintf("**Attendance over the last %d days** (%d blocks):", days, len(blocks))}
+	for _, r := range schedule.Reliabilities(records) {
+		line := fmt.Sprintf("%s: %.0f%% reliable, showed up to %d of %d blocks they said yes to, in voice for %d of %d blocks", r.Player, r.Rate()*100, r.Kept, r.Promised, r.Attended, r.Blocks)
+		noShows := r.NoShows
+		if len(noShows) > maxNoShows {
+			noShows = noShows[len(noShows)-maxNoShows:]
+		}
+		if len(noShows) > 0 {
+			missed := make([]string, len(noShows))
+			for i, a := range noShows {
+				missed[i] = fmt.Sprintf("%s (%s)", a.Block.In(sched.Week.Now().Location()).Format("Monday 1/2 3pm"), a.Activity)
+			}
+			line += "\n    no-shows: " + strings.Join(missed, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
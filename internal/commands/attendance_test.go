@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/reminders"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestAttendance(t *testing.T) {
+	reminders.Init()
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	h := commandtest.New()
+	tm := h.AddTeam("Ascension", sched)
+
+	if sent := h.Send("!attendance"); len(sent) != 1 || !strings.Contains(sent[0].Content, "isn't being taken") {
+		t.Fatalf("expected attendance not to be taken yet, got %+v", sent)
+	}
+	h.Messenger.Channels["500000000000000000"] = &discordgo.Channel{ID: "500000000000000000", GuildID: h.GuildID, Type: discordgo.ChannelTypeGuildVoice}
+	h.Messenger.Channels["510000000000000000"] = &discordgo.Channel{ID: "510000000000000000", GuildID: h.GuildID, Type: discordgo.ChannelTypeGuildText}
+	h.Messenger.Channels["520000000000000000"] = &discordgo.Channel{ID: "520000000000000000", GuildID: "110000000000000000", Type: discordgo.ChannelTypeGuildVoice}
+	for channel, want := range map[string]string{
+		"510000000000000000": "isn't a voice channel",
+		"520000000000000000": "No channel",
+		"530000000000000000": "No channel",
+	} {
+		if sent := h.Send("!attendance channel <#" + channel + ">"); len(sent) != 1 || !strings.Contains(sent[0].Content, want) {
+			t.Errorf("expected <#%s> to be rejected with %q, got %+v", channel, want, sent)
+		}
+	}
+	if sent := h.Send("!attendance channel <#500000000000000000>"); len(sent) != 1 || !strings.Contains(sent[0].Content, "<#500000000000000000>") {
+		t.Fatalf("voice channel wasn't set: %+v", sent)
+	}
+	if channel, err := h.Store.VoiceChannel(tm.ID); err != nil || channel != "500000000000000000" {
+		t.Fatalf("wrong voice channel: %q (%v)", channel, err)
+	}
+
+	// Taub is in voice for Monday's second block, and Tydra isn't
+	for _, p := range []team.PlayerLink{{Team: tm.ID, UserID: "1", Player: "Taub"}, {Team: tm.ID, UserID: "2", Player: "Tydra"}} {
+		if err := h.Store.SetPlayerLink(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links, _ := h.Store.PlayerLinks(tm.ID)
+	now := sched.Week.BlockStart(0, 1).Add(10 * time.Minute)
+	taken, err := reminders.TakeAttendance(h.Store, tm.ID, sched, links, map[string]bool{"1": true}, now)
+	if err != nil || !taken {
+		t.Fatalf("attendance wasn't taken: %v, %v", taken, err)
+	}
+	records, _ := h.Store.Attendance(tm.ID, sched.Week.BlockStart(0, 0))
+	if len(records) != 2 {
+		t.Fatalf("wrong amount of records: %+v", records)
+	}
+
+	sent := h.Send("!attendance 36500")
+	if len(sent) != 1 {
+		t.Fatalf("expected a report, got %+v", sent)
+	}
+	for _, r := range schedule.Reliabilities(records) {
+		if !strings.Contains(sent[0].Content, r.Player+": ") {
+			t.Errorf("%s isn't in the report:\n%s", r.Player, sent[0].Content)
+		}
+	}
+	if !strings.Contains(sent[0].Content, "(1 blocks)") || !strings.Contains(sent[0].Content, "Taub: 100% reliable") {
+		t.Errorf("wrong report:\n%s", sent[0].Content)
+	}
+
+	if sent = h.Send("!attendance off"); len(sent) != 1 || sent[0].Content != "Stopped taking attendance." {
+		t.Errorf("attendance wasn't stopped: %+v", sent)
+	}
+	if channel, _ := h.Store.VoiceChannel(tm.ID); channel != "" {
+		t.Errorf("voice channel wasn't cleared: %q", channel)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/bigheadgeorge/thonky2/pkg/command"
 	"github.com/bigheadgeorge/thonky2/pkg/db"
 	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -19,13 +20,15 @@ func init() {
 		{"!battlefy Feeders", "Search the current tournament for teams with \"Feeders\" in their name."},
 		{"!bf Feeders", "Same as above, but it's a shortcut. :)"},
 	}
-	command.AddCommand("battlefy", "Get team info from the current Battlefy tournament.", examples, Battlefy).AddAliases("bf", "od")
+	command.AddCommand("battlefy", "Get team info from the current Battlefy tournament.", examples, Battlefy).SetArgs(
+		command.Arg{Name: "team", Type: command.ArgRest},
+	).AddAliases("bf", "od")
 }
 
 // Battlefy gets team information from Battlefy.
-func Battlefy(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
+func Battlefy(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
 	var teamStats TeamStats
-	msg, err := getTeamStats(s, m, searchBattlefy, matchBattlefy, &teamStats)
+	msg, err := getTeamStats(s, m, args.String("team"), searchBattlefy, matchBattlefy, &teamStats)
 	if len(msg) > 0 || err != nil {
 		return msg, err
 	}
@@ -45,7 +48,7 @@ func Battlefy(s *state.State, m *discordgo.MessageCreate, args []string) (string
 	embed := formatTeamStats(teamStats.Team, players)
 	embed.Color = 0xe74c3c
 	embed.Author.IconURL = battlefyLogo
-	s.Session.ChannelMessageSendEmbed(m.ChannelID, &embed)
+	s.Messenger.ChannelMessageSendEmbed(m.ChannelID, &embed)
 	return "", nil
 }
 
@@ -59,18 +62,17 @@ func battlefyPlayers(players []battlefy.Player) []Player {
 }
 
 // searchBattlefy populates the given []Player and ODTeam with Battlefy search results.
-func searchBattlefy(db *db.Handler, team_id int, name string, teamStats *TeamStats) (string, error) {
-	var tournamentLink string
-	err := db.QueryRow("SELECT tournament_link FROM battlefy WHERE team = $1", team_id).Scan(&tournamentLink)
+func searchBattlefy(db db.Store, team_id int, name string, teamStats *TeamStats) (string, error) {
+	tournament, err := db.Tournament(team.Battlefy, team_id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "No Battlefy config for this guild; use !set_tournament.", nil
 		}
 		return fmt.Sprintf("Error getting Battlefy config: %s", err), err
 	}
-	tournamentID := strings.Split(tournamentLink, "/")[5]
-	var team battlefy.Team
-	names, err := battlefy.FindTeam(tournamentID, name, &team)
+	tournamentID := strings.Split(tournament.Link, "/")[5]
+	var found battlefy.Team
+	names, err := battlefy.FindTeam(tournamentID, name, &found)
 	if err != nil {
 		return fmt.Sprintf("Error searching Battlefy: %s", err), err
 	}
@@ -79,23 +81,21 @@ func searchBattlefy(db *db.Handler, team_id int, name string, teamStats *TeamSta
 		return formatNames(names), nil
 	}
 
-	teamStats.Team = team
-	teamStats.Players = battlefyPlayers(team.Players)
+	teamStats.Team = found
+	teamStats.Players = battlefyPlayers(found.Players)
 	return "", nil
 }
 
 // matchBattlefy gets stats on the opposing team in the given round of the tournament.
-func matchBattlefy(db *db.Handler, team_id int, round int, teamStats *TeamStats) (string, error) {
-	var tournamentLink string
-	var teamID string
-	err := db.QueryRow("SELECT tournament_link, team_id FROM battlefy WHERE team = $1", team_id).Scan(&tournamentLink, &teamID)
+func matchBattlefy(db db.Store, team_id int, round int, teamStats *TeamStats) (string, error) {
+	tournament, err := db.Tournament(team.Battlefy, team_id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "No Battlefy config; use !set_tournament.", nil
 		}
 		return fmt.Sprintf("Error getting Battlefy config: %s", err), err
 	}
-	t, err := battlefy.FindMatch(tournamentLink, teamID, round)
+	t, err := battlefy.FindMatch(tournament.Link, tournament.TeamID, round)
 	if err != nil {
 		return fmt.Sprintf("Error grabbing team info from Battlefy: %s", err), err
 	}
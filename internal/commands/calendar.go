@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	examples := [][2]string{
+		{"!calendar", "Attach this week's schedule as a file calendar apps can import."},
+		{"!calendar feed", "Get a link calendar apps can subscribe to, which stays up to date with the schedule."},
+	}
+	command.AddCommand("calendar", "Export the week schedule to your calendar.", examples, Calendar).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+	)
+}
+
+// Calendar attaches the week schedule as an iCalendar file, or links to the team's calendar feed.
+func Calendar(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	switch option := strings.ToLower(args.String("option")); option {
+	case "":
+		if sched.Week.Container == nil {
+			return "No week schedule, something broke", nil
+		}
+		_, err := s.Messenger.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+			Content: "Open this file to add the week to your calendar.",
+			Files: []*discordgo.File{{
+				Name:        "schedule.ics",
+				ContentType: "text/calendar",
+				Reader:      bytes.NewReader(TeamCalendar(s, t, sched)),
+			}},
+		})
+		if err != nil {
+			return "Error sending the calendar.", err
+		}
+		return "", nil
+	case "feed":
+		if s.CalendarURL == "" {
+			return "Calendar feeds aren't turned on for this bot; use `!calendar` to get a file instead.", nil
+		}
+		token, err := s.DB.CalendarToken(t.ID)
+		if err != nil {
+			return "Error grabbing the calendar feed.", err
+		}
+		if token == "" {
+			token, err = newToken()
+			if err != nil {
+				return "Error making a calendar feed.", err
+			}
+			err = s.DB.SetCalendarToken(t.ID, token)
+			if err != nil {
+				return "Error saving the calendar feed.", err
+			}
+		}
+		return "Subscribe to this link in your calendar app to keep the schedule in it up to date: <" + CalendarLink(s, token) + ">", nil
+	default:
+		return fmt.Sprintf("Invalid option for !calendar: %q; use feed or nothing.", option), nil
+	}
+}
+
+// TeamCalendar returns a team's week schedule as an iCalendar file, named after the team or its server.
+func TeamCalendar(s *state.State, t team.Team, sched *schedule.Schedule) []byte {
+	return schedule.Calendar(&sched.Week, sched.ID, strings.TrimSpace(s.TeamName(t)+" Schedule"))
+}
+
+// CalendarLink returns the URL of the calendar feed with a token.
+func CalendarLink(s *state.State, token string) string {
+	return strings.TrimSuffix(s.CalendarURL, "/") + "/" + token + ".ics"
+}
+
+// newToken returns a random secret, ex. for a calendar feed's URL, so only people it's shared with can find it.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	return hex.EncodeToString(b), err
+}
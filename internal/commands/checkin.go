@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+// checkInBlocks is how many blocks fit on one check-in message, since Discord allows five rows of buttons.
+const checkInBlocks = 5
+
+// checkInResponses are the availability responses a player can tap on a check-in, with their buttons' emoji and style.
+var checkInResponses = []struct {
+	Value string
+	Emoji string
+	Style discordgo.ButtonStyle
+}{
+	{"Yes", "✅", discordgo.SuccessButton},
+	{"Maybe", "❔", discordgo.SecondaryButton},
+	{"No", "❌", discordgo.DangerButton},
+}
+
+func init() {
+	examples := [][2]string{
+		{"!checkin monday", "Post buttons for players to fill in their availability on Monday with."},
+	}
+	command.AddCommand("checkin", "Post a check-in players can fill in their availability for a day with.", examples, CheckIn).SetArgs(
+		command.Arg{Name: "day", Type: command.ArgDay},
+	).SetPermission(command.Captain)
+
+	command.AddComponent("checkin", command.Player, checkInClick)
+	// reminders are DM'd, so the handler checks that whoever tapped is linked to the team
+	command.AddComponent("remind", command.Everyone, remindClick)
+}
+
+// CheckIn posts buttons for each block on a day that players can tap to set their availability.
+// Days with more blocks than fit on a message are split over several messages.
+func CheckIn(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+	day := sched.Week.Weekday(int(args.Day("day")))
+	if len(sched.Week.Container[day]) == 0 {
+		return "No blocks on " + sched.Week.Days[day] + ".", nil
+	}
+
+	for first := 0; first < len(sched.Week.Container[day]); first += checkInBlocks {
+		content, components := checkInMessage(sched, day, first)
+		_, err := s.Messenger.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{Content: content, Components: components})
+		if err != nil {
+			return "Error posting the check-in.", err
+		}
+	}
+	return "", nil
+}
+
+// checkInDate identifies the week a check-in was posted for, so buttons on an old check-in don't change a new week.
+func checkInDate(week *schedule.Week, day int) string {
+	return week.DayDate(day).Format("0102")
+}
+
+// checkInMessage returns the content and buttons of the check-in message for the blocks on a day starting at first.
+func checkInMessage(sched *schedule.Schedule, day, first int) (string, []discordgo.MessageComponent) {
+	week := &sched.Week
+	last := first + checkInBlocks
+	if last > len(week.Container[day]) {
+		last = len(week.Container[day])
+	}
+
+	lines := []string{fmt.Sprintf("**Availability for %s**; tap a button to answer for yourself.", week.Days[day])}
+	var components []discordgo.MessageComponent
+	for block := first; block < last; block++ {
+		start := week.BlockStart(day, block).Format("3pm")
+		counts := make(map[string]int)
+		for _, p := range sched.Players {
+			counts[strings.Title(strings.ToLower(p.AvailabilityOn(day)[block]))]++
+		}
+
+		line := fmt.Sprintf("`%s` %s:", start, week.ActivitiesOn(day)[block])
+		row := discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: start, Style: discordgo.SecondaryButton, Disabled: true, CustomID: command.ComponentID("checkin", "label", strconv.Itoa(block))},
+		}}
+		for _, r := range checkInResponses {
+			line += fmt.Sprintf(" %s %d", r.Emoji, counts[r.Value])
+			row.Components = append(row.Components, discordgo.Button{
+				Label:    r.Value,
+				Style:    r.Style,
+				Emoji:    discordgo.ComponentEmoji{Name: r.Emoji},
+				CustomID: command.ComponentID("checkin", checkInDate(week, day), strconv.Itoa(day), strconv.Itoa(first), strconv.Itoa(block), r.Value),
+			})
+		}
+		if missing := counts[""]; missing > 0 {
+			line += fmt.Sprintf(" (%d haven't answered)", missing)
+		}
+		lines = append(lines, line)
+		components = append(components, row)
+	}
+	return strings.Join(lines, "\n"), components
+}
+
+// checkIn sets a player's availability for a block and writes it back to the schedule, like !set does.
+func checkIn(sched *schedule.Schedule, player string, day, block int, value string) error {
+	for _, p := range sched.Players {
+		if p.Name != player {
+			continue
+		}
+		if day >= len(p.Container) || block >= len(p.Container[day]) {
+			return fmt.Errorf("no block %d on day %d for %s", block, day, player)
+		}
+		updateCell(sched, p.Name, p.Container[day][block], value)
+		return sched.Sync()
+	}
+	return fmt.Errorf("no player %q on the schedule", player)
+}
+
+// checkInClick sets the availability of whoever tapped a button on a check-in, and refreshes the check-in's counts.
+func checkInClick(s *state.State, i *discordgo.InteractionCreate, args []string) (*discordgo.InteractionResponse, error) {
+	if len(args) != 5 {
+		return nil, nil
+	}
+	day, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, err
+	}
+	first, err := strconv.Atoi(args[2])
+	if err != nil {
+		return nil, err
+	}
+	block, err := strconv.Atoi(args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	t := s.FindTeam(i.GuildID, i.ChannelID)
+	if t.ID == 0 {
+		return command.Ephemeral("No team in this channel or server."), nil
+	}
+	spreadsheetID, err := s.DB.SpreadsheetID(t.ID)
+	if err != nil {
+		return command.Ephemeral("Error grabbing spreadsheet ID."), err
+	}
+	sched := s.Schedules[spreadsheetID]
+	if sched == nil || day < 0 || day >= len(sched.Week.Container) {
+		return command.Ephemeral("No schedule for this team."), nil
+	}
+	if checkInDate(&sched.Week, day) != args[0] {
+		return command.Ephemeral("This check-in is for an old week; ask for a new one with `!checkin`."), nil
+	}
+
+	link, err := s.DB.PlayerLink(t.ID, i.Member.User.ID)
+	if err == sql.ErrNoRows {
+		return command.Ephemeral("Link yourself to your name on the schedule first, ex. `!link Tydra`"), nil
+	} else if err != nil {
+		return command.Ephemeral("Error grabbing your link."), err
+	}
+	err = checkIn(sched, link.Player, day, block, args[4])
+	if err != nil {
+		return command.Ephemeral("Error updating your availability. :("), err
+	}
+
+	content, components := checkInMessage(sched, day, first)
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{Content: content, Components: components},
+	}, nil
+}
+
+// remindClick sets the availability of a player who tapped Yes or No on a reminder for a block they said maybe to,
+// and takes the buttons off the reminder.
+func remindClick(s *state.State, i *discordgo.InteractionCreate, args []string) (*discordgo.InteractionResponse, error) {
+	if len(args) != 5 {
+		return nil, nil
+	}
+	teamID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, err
+	}
+	day, err := strconv.Atoi(args[2])
+	if err != nil {
+		return nil, err
+	}
+	block, err := strconv.Atoi(args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	sched := s.TeamSchedule(teamID)
+	if sched == nil || day < 0 || day >= len(sched.Week.Container) {
+		return command.Ephemeral("Your team doesn't have a schedule anymore."), nil
+	}
+	if checkInDate(&sched.Week, day) != args[1] {
+		return command.Ephemeral("This reminder is for an old week."), nil
+	}
+
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	link, err := s.DB.PlayerLink(teamID, user.ID)
+	if err == sql.ErrNoRows {
+		return command.Ephemeral("You aren't linked to your team's schedule anymore."), nil
+	} else if err != nil {
+		return command.Ephemeral("Error grabbing your link."), err
+	}
+	err = checkIn(sched, link.Player, day, block, args[4])
+	if err != nil {
+		return command.Ephemeral("Error updating your availability. :("), err
+	}
+
+	var content string
+	if i.Message != nil {
+		content = i.Message.Content + "\n"
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content + fmt.Sprintf("You said %s; your team's schedule is updated.", strings.ToLower(args[4])),
+			Components: []discordgo.MessageComponent{},
+		},
+	}, nil
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+// fileSchedule copies the test schedule file into a temporary directory and loads it.
+// The returned function removes the directory.
+func fileSchedule(t *testing.T) (*schedule.Schedule, string, func()) {
+	dir, err := ioutil.TempDir("", "thonky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	b, err := ioutil.ReadFile("../../pkg/schedule/testdata/schedule.yaml")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "schedule.yaml")
+	if err = ioutil.WriteFile(path, b, 0644); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	sched, err := schedule.New(schedule.NewFile(path))
+	if err == nil {
+		err = sched.Update()
+	}
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	sched.SetLocation(time.UTC)
+	return sched, path, cleanup
+}
+
+func TestCheckIn(t *testing.T) {
+	sched, path, cleanup := fileSchedule(t)
+	defer cleanup()
+
+	content, components := checkInMessage(sched, 5, 0)
+	if len(components) != checkInBlocks {
+		t.Fatalf("wrong amount of rows: %d != %d", len(components), checkInBlocks)
+	}
+	if !strings.Contains(content, "`4pm` Free: ✅ 0 ❔ 1 ❌ 0 (1 haven't answered)") {
+		t.Errorf("wrong counts before checking in:\n%s", content)
+	}
+	row := components[0].(discordgo.ActionsRow)
+	yes := row.Components[1].(discordgo.Button)
+	if !strings.HasPrefix(yes.CustomID, "checkin:") || !strings.HasSuffix(yes.CustomID, ":5:0:0:Yes") {
+		t.Errorf("wrong custom ID for Yes: %q", yes.CustomID)
+	}
+	if _, components = checkInMessage(sched, 5, checkInBlocks); len(components) != 1 {
+		t.Errorf("wrong amount of rows on the second message: %d != 1", len(components))
+	}
+
+	if err := checkIn(sched, "Tydra", 5, 0, "Yes"); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ = checkInMessage(sched, 5, 0); !strings.Contains(content, "`4pm` Free: ✅ 1 ❔ 1 ❌ 0\n") {
+		t.Errorf("wrong counts after checking in:\n%s", content)
+	}
+	if err := checkIn(sched, "Nobody", 5, 0, "Yes"); err == nil {
+		t.Error("expected an error checking in for a player that isn't on the schedule")
+	}
+
+	reloaded, err := schedule.New(schedule.NewFile(path))
+	if err == nil {
+		err = reloaded.Update()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := reloaded.Players[1].AvailabilityOn(5)[0]; v != "Yes" {
+		t.Errorf("check-in wasn't written to the schedule: %q != %q", v, "Yes")
+	}
+}
+
+func TestRemindClick(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	h := commandtest.New()
+	added := h.AddTeam("Team Rocket", sched)
+	if err := h.Store.SetPlayerLink(team.PlayerLink{Team: added.ID, UserID: "1", Player: "Tydra"}); err != nil {
+		t.Fatal(err)
+	}
+
+	click := func(userID, date string) *discordgo.InteractionResponse {
+		t.Helper()
+		i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			User:    &discordgo.User{ID: userID},
+			Message: &discordgo.Message{Content: "Scrim in 15 minutes, and you said maybe. Are you coming?"},
+		}}
+		args := []string{strconv.Itoa(added.ID), date, "5", "0", "No"}
+		response, err := remindClick(h.State, i, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	date := checkInDate(&sched.Week, 5)
+	if r := click("2", date); !strings.Contains(r.Data.Content, "aren't linked") {
+		t.Errorf("expected someone who isn't linked to be turned away, got %q", r.Data.Content)
+	}
+	if r := click("1", "0101"); !strings.Contains(r.Data.Content, "old week") {
+		t.Errorf("expected a reminder from another week to be turned away, got %q", r.Data.Content)
+	}
+	r := click("1", date)
+	if r.Type != discordgo.InteractionResponseUpdateMessage || len(r.Data.Components) != 0 || !strings.HasSuffix(r.Data.Content, "You said no; your team's schedule is updated.") {
+		t.Errorf("wrong response: %+v", r.Data)
+	}
+	if v := sched.Players[1].AvailabilityOn(5)[0]; v != "No" {
+		t.Errorf("availability wasn't set: %q != %q", v, "No")
+	}
+}
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+)
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// want is part of the only message that should be sent back
+		want string
+	}{
+		{"help for one command", "!help add_team", "!add_team \"Team Rocket\" #general"},
+		{"help for an alias", "!help add_channels", "!add_channel: "},
+		{"help for a missing command", "!help nope", `No command named "nope"`},
+		{"get needs an option", "!get", "Usage: `!get <option> [date]`"},
+		{"update takes one option", "!update force now", "Unexpected argument \"now\"."},
+		{"add_team needs a channel", `!add_team "Team Rocket" general`, "Invalid channel \"general\"."},
+		{"add_channel needs channels", "!add_channel", "Missing channels."},
+		{"set_roles needs a level", "!set_roles", "Usage: `!set_roles <level> [roles...]`"},
+		{"owl takes one option", "!owl today tomorrow", "Unexpected argument \"tomorrow\"."},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sent := commandtest.New().Send(test.content)
+			if len(sent) != 1 {
+				t.Fatalf("wrong amount of messages sent: %d != 1", len(sent))
+			}
+			if !strings.Contains(sent[0].Content, test.want) {
+				t.Errorf("%q doesn't contain %q", sent[0].Content, test.want)
+			}
+		})
+	}
+}
+
+func TestHelpList(t *testing.T) {
+	sent := commandtest.New().Send("!help")
+	if len(sent) == 0 {
+		t.Fatal("no command list sent")
+	}
+	var names []string
+	var list string
+	for _, msg := range sent {
+		list += msg.Content
+		if len(msg.Content) > maxMessage {
+			t.Errorf("message is over Discord's limit: %d > %d", len(msg.Content), maxMessage)
+		}
+		for _, line := range strings.Split(msg.Content, "\n") {
+			if strings.HasPrefix(line, "!") && strings.Contains(line, ":\t") {
+				names = append(names, line[:strings.Index(line, ":")])
+			}
+		}
+	}
+	if len(names) != len(command.Commands) {
+		t.Errorf("wrong amount of commands listed: %d != %d", len(names), len(command.Commands))
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("commands aren't sorted: %v", names)
+	}
+	if want := "!set:\tUpdate information on the configured spreadsheet."; !strings.Contains(list, want) {
+		t.Errorf("list doesn't contain %q", want)
+	}
+}
+
+func TestNotACommand(t *testing.T) {
+	h := commandtest.New()
+	for _, content := range []string{"hello", "!", "!notacommand"} {
+		if sent := h.Send(content); len(sent) != 0 {
+			t.Errorf("%q got a reply: %+v", content, sent)
+		}
+	}
+}
+
+func TestReminderConfig(t *testing.T) {
+	config := &team.ReminderConfig{Team: 1}
+	if msg := formatReminders(config); !strings.Contains(msg, "No reminders") {
+		t.Errorf("expected no reminders, got %q", msg)
+	}
+
+	if _, err := changeIntervals(config, true, "45, 50 45"); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Intervals) != 2 || config.Intervals[0] != 45 || config.Intervals[1] != 50 {
+		t.Errorf("wrong intervals: %v", config.Intervals)
+	}
+	if _, err := changeIntervals(config, true, "60"); err == nil {
+		t.Error("expected an error for an interval past the hour")
+	}
+	if _, err := changeIntervals(config, false, "45"); err != nil || len(config.Intervals) != 1 {
+		t.Errorf("expected 45 to be removed: %v (%v)", config.Intervals, err)
+	}
+
+	config.Activities = []string{"Scrim"}
+	config.AnnounceChannel = "1"
+	want := "Reminders for Scrim are sent 50 minutes into the hour before each block in <#1>."
+	if msg := formatReminders(config); msg != want {
+		t.Errorf("%q != %q", msg, want)
+	}
+}
+
+func TestDefaultPermissions(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	h := commandtest.New()
+	added := h.AddTeam("Team Rocket", sched)
+	h.Messenger.Permissions = 0
+
+	tests := []struct {
+		name    string
+		players []string
+		roles   []string
+		content string
+		// want is part of the last reply
+		want string
+	}{
+		{"players without roles", nil, nil, "!set monday 16 free", "Updated schedule."},
+		{"captains without roles", nil, nil, "!attendance", "You need to be a captain to use !attendance."},
+		{"player role needed", []string{"player"}, nil, "!set monday 16 free", "You need to be a player to use !set."},
+		{"player role", []string{"player"}, []string{"player"}, "!set monday 16 free", "Updated schedule."},
+		{"player role isn't a captain", []string{"player"}, []string{"player"}, "!attendance", "You need to be a captain to use !attendance."},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := h.Store.SetTeamRoles(team.Roles{Team: added.ID, Players: test.players}); err != nil {
+				t.Fatal(err)
+			}
+			h.Messenger.Roles = test.roles
+			sent := h.Send(test.content)
+			if len(sent) == 0 || !strings.Contains(sent[len(sent)-1].Content, test.want) {
+				t.Errorf("%+v doesn't end with %q", sent, test.want)
+			}
+		})
+	}
+
+	if err := h.Store.SetTeamRoles(team.Roles{Team: added.ID, Captains: []string{"captain"}}); err != nil {
+		t.Fatal(err)
+	}
+	h.Messenger.Roles = []string{"captain"}
+	if sent := h.Send("!attendance"); len(sent) == 0 || !strings.Contains(sent[len(sent)-1].Content, "Attendance isn't being taken") {
+		t.Errorf("captain role didn't grant !attendance: %+v", sent)
+	}
+}
+
+func TestCommandEffects(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	h := commandtest.New()
+	added := h.AddTeam("Ascension", sched)
+
+	send := func(content, want string) []commandtest.Sent {
+		t.Helper()
+		sent := h.Send(content)
+		if len(sent) != 1 || !strings.Contains(sent[0].Content, want) {
+			t.Errorf("%s: expected one reply containing %q, got %+v", content, want, sent)
+		}
+		return sent
+	}
+
+	send("!set monday 16-18 free, scrim", "Updated schedule.")
+	if got := sched.Week.ActivitiesOn(0)[:2]; got[0] != "Free" || got[1] != "Scrim" {
+		t.Errorf("!set didn't update the week: %v", got)
+	}
+	send("!set Taub monday 16 no", "Updated schedule.")
+	if got := sched.Players[0].AvailabilityOn(0)[0]; got != "No" {
+		t.Errorf("!set didn't update Taub's availability: %q", got)
+	}
+
+	sent := h.Send("!get week")
+	if len(sent) != 1 || sent[0].Embed == nil || len(sent[0].Files) != 1 || sent[0].Files[0].Name != "week.png" {
+		t.Errorf("!get week didn't send the week's image: %+v", sent)
+	}
+
+	send("!update", "Nothing to update.")
+	send("!update force", "Finished updating. :)")
+
+	send("!add_channel <#500000000000000000>", "Added Channels.")
+	if name, err := h.Store.GetName("500000000000000000"); err != nil || name != "Ascension" {
+		t.Errorf("!add_channel didn't add the channel to the team: %q (%v)", name, err)
+	}
+
+	send("!set_roles captain <@&600000000000000000>", "")
+	if roles, err := h.Store.TeamRoles(added.ID); err != nil || len(roles.Captains) != 1 || roles.Captains[0] != "600000000000000000" {
+		t.Errorf("!set_roles didn't set the captain role: %+v (%v)", roles, err)
+	}
+}
@@ -2,55 +2,82 @@ package commands
 
 import (
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log"
 	"regexp"
 	"strings"
 
 	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
 	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
 	"github.com/bwmarrin/discordgo"
 )
 
 func init() {
 	examples := [][2]string{
 		{"!add_team Test #general", "Add a team with the name \"Test\" in #general chat"},
+		{"!add_team \"Team Rocket\" #general", "Quote team names with spaces in them"},
 	}
-	command.AddCommand("add_team", "Add a team to the server.", examples, AddTeam)
+	command.AddCommand("add_team", "Add a team to the server.", examples, AddTeam).SetArgs(
+		command.Arg{Name: "name", Type: command.ArgString},
+		command.Arg{Name: "channel", Type: command.ArgChannel},
+	).SetPermission(command.Admin)
 
 	examples = [][2]string{
 		{"!add_channel #general-2", "Add #general-2 to the team in this channel."},
 		{"!add_channels #general-2 #general-3", "Add #general-2 and #general-3 to the team in this channel."},
 	}
-	command.AddCommand("add_channel", "Add one or more channels to a team.", examples, AddChannels).AddAliases("add_channels")
+	command.AddCommand("add_channel", "Add one or more channels to a team.", examples, AddChannels).SetArgs(
+		command.Arg{Name: "channels", Type: command.ArgChannel, Multiple: true},
+	).SetPermission(command.Admin).AddAliases("add_channels")
 
 	examples = [][2]string{
 		{"!save", "Save the current week schedule as default"},
 	}
-	command.AddCommand("save", "Save the week schedule", examples, Save)
+	command.AddCommand("save", "Save the week schedule", examples, Save).SetPermission(command.Captain)
 
 	examples = [][2]string{
 		{"!set_tournament https://battlefy.com/overwatch-open-division-north-america/2019-overwatch-open-division-practice-season-north-america/5d6fdb02c747ff732da36eb4/stage/5d7b716bb7758c268b771f83/bracket/1", "Update the current tournament to a Battlefy tournament."},
 		{"!set_tournament https://gamebattles.majorleaguegaming.com/pc/overwatch/tournament/Breakable-Barriers-EMEA-2", "Update the current tournament to a Gamebattles tournament."},
-		{"!set tournament https://gamebattles.majorleaguegaming.com/pc/overwatch/tournament/Breakable-Barriers-EMEA-2 https://gamebattles.majorleaguegaming.com/pc/overwatch/team/33834248", "Update the current tournament and team."},
+		{"!set_tournament https://gamebattles.majorleaguegaming.com/pc/overwatch/tournament/Breakable-Barriers-EMEA-2 https://gamebattles.majorleaguegaming.com/pc/overwatch/team/33834248", "Update the current tournament and team."},
 	}
-	command.AddCommand("set_tournament", "Update the current tournament and team.", examples, SetTournament).AddAliases("set_tourney")
-}
+	command.AddCommand("set_tournament", "Update the current tournament and team.", examples, SetTournament).SetArgs(
+		command.Arg{Name: "tournament link", Type: command.ArgString},
+		command.Arg{Name: "team link", Type: command.ArgString, Optional: true},
+	).SetPermission(command.Captain).AddAliases("set_tourney")
 
-func isChannel(s string) bool {
-	match, _ := regexp.MatchString(`<#\d{18}>`, s)
-	return match
-}
+	examples = [][2]string{
+		{"!set_roles player @Tanks @DPS @Supports", "Only let people with these roles update the sheet."},
+		{"!set_roles captain @Captain", "Only let captains save and reset the week schedule, or change the tournament."},
+		{"!set_roles admin @Manager", "Let managers configure the team like server admins can."},
+		{"!set_roles player", "Let anyone update the sheet again."},
+	}
+	command.AddCommand("set_roles", "Set the roles that can use commands for a team.", examples, SetRoles).SetArgs(
+		command.Arg{Name: "level", Type: command.ArgString},
+		command.Arg{Name: "roles", Type: command.ArgRole, Optional: true, Multiple: true},
+	).SetPermission(command.Admin)
+
+	examples = [][2]string{
+		{"!set_timezone America/Los_Angeles", "Put the schedule's blocks in Pacific time."},
+		{"!set_timezone Europe/Berlin", "Put the schedule's blocks in Central European time."},
+	}
+	command.AddCommand("set_timezone", "Set the timezone the schedule is in.", examples, SetTimezone).SetArgs(
+		command.Arg{Name: "timezone", Type: command.ArgString},
+	).SetPermission(command.Captain)
 
-// channelID gets the channelID from a channel mention, ex. <#477928874450354176> returns 477928874450354176
-func channelID(s string) string {
-	return s[2 : len(s)-1]
+	examples = [][2]string{
+		{"!set_changes_channel #schedule-changes", "Announce changes made on the sheet in #schedule-changes."},
+		{"!set_changes_channel none", "Stop announcing changes made on the sheet."},
+	}
+	command.AddCommand("set_changes_channel", "Set the channel changes made on the sheet are announced in.", examples, SetChangesChannel).SetArgs(
+		command.Arg{Name: "channel", Type: command.ArgString},
+	).SetPermission(command.Captain)
 }
 
 // sendPermission checks whether the bot has permission to send messages in a channel
-func sendPermission(s *discordgo.Session, channelID string) (bool, error) {
-	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+func sendPermission(s *state.State, channelID string) (bool, error) {
+	perms, err := s.Messenger.UserChannelPermissions(s.BotID(), channelID)
 	if err != nil {
 		return false, err
 	}
@@ -58,24 +85,15 @@ func sendPermission(s *discordgo.Session, channelID string) (bool, error) {
 }
 
 // AddTeam adds a team to a guild
-func AddTeam(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
+func AddTeam(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
 	team := s.GuildTeam(m.GuildID)
 	if team.ID == 0 {
 		return "No team for this guild.", fmt.Errorf("no team for [%s]\n", m.GuildID)
 	}
 
-	if len(args) != 3 {
-		if len(args) == 2 {
-			return "Bad amount of args; no channel given!", nil
-		} else {
-			return "Bad amount of args.", nil
-		}
-	}
-	if !isChannel(args[2]) {
-		return "Invalid channel", nil
-	}
-	chanID := channelID(args[2])
-	canSend, err := sendPermission(s.Session, chanID)
+	chanID := args.Channel("channel")
+	name := args.String("name")
+	canSend, err := sendPermission(s, chanID)
 	if err != nil {
 		return err.Error(), err
 	} else if !canSend {
@@ -86,21 +104,17 @@ func AddTeam(s *state.State, m *discordgo.MessageCreate, args []string) (string,
 		return fmt.Sprintf("Channel already occupied by %q", name), nil
 	}
 
-	err = s.DB.AddTeam(m.GuildID, args[1], chanID)
+	err = s.DB.AddTeam(m.GuildID, name, chanID)
 	if err != nil {
 		return err.Error(), err
 	}
 
-	log.Printf("added team %q to guild [%s]\n", args[1], m.GuildID)
+	log.Printf("added team %q to guild [%s]\n", name, m.GuildID)
 	return "Added team.", nil
 }
 
 // AddChannels adds channels to the team in the channel the command is called from
-func AddChannels(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
-	if len(args) < 2 {
-		return "No channels given!", nil
-	}
-
+func AddChannels(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
 	team := s.FindTeam(m.GuildID, m.ChannelID)
 	if team.ID == 0 {
 		return "No config for this guild.", nil
@@ -110,18 +124,16 @@ func AddChannels(s *state.State, m *discordgo.MessageCreate, args []string) (str
 		return "No team in this channel.", nil
 	}
 
-	givenChannels := args[1:]
-	for _, arg := range givenChannels {
-		if !isChannel(arg) {
-			return fmt.Sprintf("Invalid channel %q.", arg), nil
-		} else if name, err := s.DB.GetName(channelID(arg)); err == nil {
+	givenChannels := args.Strings("channels")
+	for _, id := range givenChannels {
+		if name, err := s.DB.GetName(id); err == nil {
 			if name == team.Name {
-				return arg + " already added.", nil
+				return "<#" + id + "> already added.", nil
 			} else {
-				return fmt.Sprintf("%s already occupied by %q.", arg, name), nil
+				return fmt.Sprintf("<#%s> already occupied by %q.", id, name), nil
 			}
 		}
-		canSend, err := sendPermission(s.Session, arg[2:len(arg)-1])
+		canSend, err := sendPermission(s, id)
 		if err != nil {
 			log.Println(err)
 		} else if !canSend {
@@ -129,24 +141,11 @@ func AddChannels(s *state.State, m *discordgo.MessageCreate, args []string) (str
 		}
 	}
 
-	for _, id := range team.Channels {
-		for i, givenID := range givenChannels {
-			if id == givenID[2:len(givenID)-1] {
-				givenChannels = append(givenChannels[:i], givenChannels[i+1:]...)
-			}
-		}
-	}
-
-	for i, channel := range givenChannels {
-		givenChannels[i] = channelID(channel)
-	}
-
 	for _, id := range givenChannels {
 		team.Channels = append(team.Channels, id)
 	}
 
-	r, err := s.DB.Query("UPDATE teams SET channels = $1 WHERE server_id = $2 AND team_name = $3", team.Channels, m.GuildID, team.Name)
-	defer r.Close()
+	err := s.DB.SetTeamChannels(team.ID, team.Channels)
 	if err != nil {
 		return "Error updating channels.", err
 	}
@@ -155,50 +154,26 @@ func AddChannels(s *state.State, m *discordgo.MessageCreate, args []string) (str
 }
 
 // Save saves a sheet's current week schedule for resetting to
-func Save(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
+func Save(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
 	sched := s.FindSchedule(m.GuildID, m.ChannelID)
 	if sched == nil {
 		return "", nil
 	}
 
-	b, err := json.Marshal(sched.Week)
+	err := s.DB.SetDefaultWeek(sched.ID, sched.Week)
 	if err != nil {
-		return "Error encoding schedule, something stupid happened", err
-	}
-
-	r, err := s.DB.Query("SELECT id FROM sheet_info WHERE id = $1", sched.ID)
-	if err != nil {
-		return "Error querying database, something stupid happened", nil
-	}
-	defer r.Close()
-
-	if r.Next() {
-		_, err := s.DB.Query("UPDATE sheet_info SET default_week = $1 WHERE id = $2", b, sched.ID)
-		if err != nil {
-			return "Error updating default", err
-		}
-	} else {
-		_, err := s.DB.Query("INSERT INTO sheet_info (id, default_week) VALUES ($1, $2)", sched.ID, b)
-		if err != nil {
-			return "Error setting default", err
-		}
+		return "Error saving default week schedule.", err
 	}
 	return "Updated default week schedule. :)", nil
 }
 
 // SetTournament updates the tournament a team is participating in and, optionally, their team on the tournament site.
-func SetTournament(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
-	team := s.FindTeam(m.GuildID, m.ChannelID)
-	if team.ID == 0 {
+func SetTournament(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
 		return "Error grabbing team", nil
 	}
 
-	if len(args) > 3 {
-		return "Too many arguments.", nil
-	} else if len(args) == 1 {
-		return "No arguments; missing tournament link and team link.", nil
-	}
-
 	tournamentRegexes := []string{
 		`https://battlefy.com/[\w\d-]{1,}/[\w\d-]{1,}/[\d\w]{24}/stage/[\d\w]{24}`,
 		`https://gamebattles.majorleaguegaming.com/pc/.+/tournament/[\w\d-]+`,
@@ -206,52 +181,133 @@ func SetTournament(s *state.State, m *discordgo.MessageCreate, args []string) (s
 	var url, re string
 	var site int
 	for site, re = range tournamentRegexes {
-		url = regexp.MustCompile(re).FindString(args[1])
+		url = regexp.MustCompile(re).FindString(args.String("tournament link"))
 		if len(url) > 0 {
 			break
 		}
 	}
-	var siteTable string
-	switch site {
-	case 0:
-		siteTable = "battlefy"
-	case 1:
-		siteTable = "gamebattles"
-	}
 	if len(url) == 0 {
 		return "Invalid / unsupported tournament URL.", nil
-	} else if len(args) == 2 {
-		err := s.DB.QueryRow(fmt.Sprintf("SELECT team FROM %s WHERE team = $1", siteTable), team.ID).Scan(&team.ID)
-		if err == sql.ErrNoRows {
-			return "No config yet; give me both your tournament link AND your team link.", err
+	}
+	tournament, err := s.DB.Tournament([]string{team.Battlefy, team.Gamebattles}[site], t.ID)
+	if err == sql.ErrNoRows {
+		if !args.Has("team link") {
+			return "No config yet; give me both your tournament link AND your team link.", nil
 		}
+	} else if err != nil {
+		return "Error grabbing tournament.", err
 	}
 
-	var teamURL string
-	if len(args) == 3 {
+	if args.Has("team link") {
 		teamRegexes := []string{
 			`https://battlefy.com/teams/.+`,
 			`https://gamebattles.majorleaguegaming.com/pc/.+/team/\d+`,
 		}
-		teamURL = regexp.MustCompile(teamRegexes[site]).FindString(args[2])
+		teamURL := regexp.MustCompile(teamRegexes[site]).FindString(args.String("team link"))
 		if len(teamURL) == 0 {
 			return "Incompatible team link; your tournament and team links are from two different websites.", nil
 		}
+		tournament.TeamID = teamURL[strings.LastIndex(teamURL, "/")+1:]
 	}
 
-	var err error
-	teamID := teamURL[strings.LastIndex(teamURL, "/")+1:]
-	switch site {
-	case 0:
-		//_, err = DB.Exec("UPDATE battlefy SET stage_id = $1, tournament_link = $2, team_id = $3 WHERE team = $4", url[strings.LastIndex(url, "/")+1:], url, teamID, team.ID)
-		_, err = s.DB.Exec("INSERT INTO battlefy (team, stage_id, tournament_link, team_id) VALUES ($1, $2, $3, $4) ON CONFLICT (team) DO UPDATE SET stage_id = EXCLUDED.stage_id, tournament_link = EXCLUDED.tournament_link, team_id = EXCLUDED.team_id", team.ID, url[strings.LastIndex(url, "/")+1:], url, teamID)
-	case 1:
-		log.Println("updating gamebattles")
-		//_, err = DB.Exec("UPDATE gamebattles SET tournament_link = $1, team_id = $2 WHERE team = $3", url, teamID, team.ID)
-		_, err = s.DB.Exec("INSERT INTO gamebattles (team, tournament_link, team_id) VALUES ($1, $2, $3) ON CONFLICT (team) DO UPDATE SET tournament_link = EXCLUDED.tournament_link, team_id = EXCLUDED.team_id", team.ID, url, teamID)
-	}
+	// the team's ID on the site is kept when only the tournament changes
+	tournament.Team = t.ID
+	tournament.Link = url
+	err = s.DB.SetTournament(tournament)
 	if err != nil {
 		return "Error updating tournament: " + err.Error(), err
 	}
+	log.Printf("set %s tournament for team %d to %s\n", tournament.Site, t.ID, url)
 	return "Updated tournament. :)", nil
 }
+
+// SetRoles sets the roles that grant a permission level on the team in the channel the command is called from.
+func SetRoles(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	team := s.FindTeam(m.GuildID, m.ChannelID)
+	if team.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+
+	level, err := command.ParsePermission(args.String("level"))
+	if err != nil || level == command.Everyone {
+		return fmt.Sprintf("Invalid level %q; use player, captain or admin.", args.String("level")), nil
+	}
+
+	roles, err := s.DB.TeamRoles(team.ID)
+	if err != nil {
+		return "Error grabbing roles.", err
+	}
+	given := args.Strings("roles")
+	switch level {
+	case command.Player:
+		roles.Players = given
+	case command.Captain:
+		roles.Captains = given
+	case command.Admin:
+		roles.Admins = given
+	}
+	err = s.DB.SetTeamRoles(roles)
+	if err != nil {
+		return "Error updating roles.", err
+	}
+
+	log.Printf("set %s roles for team %d to %v\n", level, team.ID, given)
+	if len(given) == 0 {
+		return fmt.Sprintf("Cleared %s roles.", level), nil
+	}
+	return fmt.Sprintf("Updated %s roles. :)", level), nil
+}
+
+// SetTimezone sets the IANA timezone the blocks on a team's schedule are in.
+func SetTimezone(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	team := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	loc, err := schedule.LoadLocation(args.String("timezone"))
+	if err != nil {
+		return fmt.Sprintf("Unknown timezone %q; use a name like America/Los_Angeles.", args.String("timezone")), nil
+	}
+	err = s.DB.SetTimezone(team.ID, loc.String())
+	if err != nil {
+		return "Error updating timezone.", err
+	}
+	sched.SetLocation(loc)
+
+	log.Printf("set timezone for team %d to %s\n", team.ID, loc)
+	return fmt.Sprintf("Updated timezone to %s. :)", loc), nil
+}
+
+// SetChangesChannel sets the channel changes made on a team's sheet are announced in, or stops announcing them.
+func SetChangesChannel(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	team := s.FindTeam(m.GuildID, m.ChannelID)
+	if team.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+
+	var channel string
+	if !strings.EqualFold(args.String("channel"), "none") {
+		id, err := command.ParseChannel(args.String("channel"))
+		if err != nil {
+			return err.Error(), nil
+		}
+		canSend, err := sendPermission(s, id)
+		if err != nil {
+			return "Error checking permissions for that channel.", err
+		} else if !canSend {
+			return "I don't have permission to send messages in that channel. :(", nil
+		}
+		channel = id
+	}
+
+	err := s.DB.SetChangesChannel(team.ID, channel)
+	if err != nil {
+		return "Error updating the changes channel.", err
+	}
+	if channel == "" {
+		return "Changes made on the sheet won't be announced anymore.", nil
+	}
+	return "Changes made on the sheet will be announced in <#" + channel + ">.", nil
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+)
+
+func TestSaveAndReset(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	h := commandtest.New()
+	h.AddTeam("Ascension", sched)
+
+	if sent := h.Send("!reset"); len(sent) != 1 || !strings.Contains(sent[0].Content, "No default week") {
+		t.Fatalf("expected no default week yet, got %+v", sent)
+	}
+	if sent := h.Send("!save"); len(sent) != 1 || !strings.Contains(sent[0].Content, "Updated default") {
+		t.Fatalf("week wasn't saved: %+v", sent)
+	}
+
+	saved := sched.Week.Container[0][0].Value
+	sched.SetValue(schedule.WeekGrid, sched.Week.Container[0][0], "Changed")
+	if err := sched.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if sent := h.Send("!reset"); len(sent) != 1 || !strings.Contains(sent[0].Content, "Loaded default") {
+		t.Fatalf("week wasn't reset: %+v", sent)
+	}
+	if got := sched.Week.Container[0][0].Value; got != saved {
+		t.Errorf("first block is %q after resetting, expected %q", got, saved)
+	}
+
+	cached := &schedule.Schedule{ID: sched.ID}
+	if err := h.Store.CachedSchedule(cached); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached.Week.Container[0][0].Value; got != saved {
+		t.Errorf("reset week wasn't cached: first block is %q", got)
+	}
+}
+
+func TestSetTournament(t *testing.T) {
+	h := commandtest.New()
+	tm := h.AddTeam("Ascension", nil)
+
+	tournament := "https://gamebattles.majorleaguegaming.com/pc/overwatch/tournament/Breakable-Barriers-EMEA-2"
+	if sent := h.Send("!set_tournament " + tournament); len(sent) != 1 || !strings.Contains(sent[0].Content, "team link") {
+		t.Fatalf("expected to be asked for a team link, got %+v", sent)
+	}
+	h.Send("!set_tournament " + tournament + " https://gamebattles.majorleaguegaming.com/pc/overwatch/team/33834248")
+
+	// changing only the tournament keeps the team
+	next := "https://gamebattles.majorleaguegaming.com/pc/overwatch/tournament/Breakable-Barriers-EMEA-3"
+	if sent := h.Send("!set_tournament " + next); len(sent) != 1 || !strings.Contains(sent[0].Content, "Updated tournament") {
+		t.Fatalf("tournament wasn't updated: %+v", sent)
+	}
+	got, err := h.Store.Tournament(team.Gamebattles, tm.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Link != next || got.TeamID != "33834248" {
+		t.Errorf("wrong tournament: %+v", got)
+	}
+}
+
+func TestAddChannels(t *testing.T) {
+	h := commandtest.New()
+	h.AddTeam("Ascension", nil)
+
+	if sent := h.Send("!add_channels <#500000000000000000>"); len(sent) != 1 || sent[0].Content != "Added Channels." {
+		t.Fatalf("channel wasn't added: %+v", sent)
+	}
+	if name, err := h.Store.GetName("500000000000000000"); err != nil || name != "Ascension" {
+		t.Errorf("wrong team in the added channel: %q (%v)", name, err)
+	}
+	if sent := h.Send("!add_channel <#500000000000000000>"); len(sent) != 1 || !strings.Contains(sent[0].Content, "already added") {
+		t.Errorf("expected the channel to already be added, got %+v", sent)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/bigheadgeorge/thonky2/pkg/db"
 	"github.com/bigheadgeorge/thonky2/pkg/gamebattles"
 	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -20,13 +21,15 @@ func init() {
 		{"!gamebattles Feeders", "Search gamebattles for a team with \"Feeders\" in the name (case-sensitive)."},
 		{"!gb Feeders", "Same as above, just a shortcut :)"},
 	}
-	command.AddCommand("gamebattles", "Get info about other teams in a Gamebattles tournament.", examples, Gamebattles).AddAliases("gb")
+	command.AddCommand("gamebattles", "Get info about other teams in a Gamebattles tournament.", examples, Gamebattles).SetArgs(
+		command.Arg{Name: "team", Type: command.ArgRest},
+	).AddAliases("gb")
 }
 
 // Gamebattles gets team information off of gamebattles.
-func Gamebattles(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
+func Gamebattles(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
 	var teamStats TeamStats
-	msg, err := getTeamStats(s, m, searchGamebattles, matchBattlefy, &teamStats)
+	msg, err := getTeamStats(s, m, args.String("team"), searchGamebattles, matchBattlefy, &teamStats)
 	if len(msg) > 0 || err != nil {
 		return msg, err
 	}
@@ -34,7 +37,7 @@ func Gamebattles(s *state.State, m *discordgo.MessageCreate, args []string) (str
 	embed := formatTeamStats(teamStats.Team, convertPlayers(teamStats.Players))
 	embed.Color = 0x22242C
 	embed.Author.IconURL = gamebattlesLogo
-	s.Session.ChannelMessageSendEmbed(m.ChannelID, &embed)
+	s.Messenger.ChannelMessageSendEmbed(m.ChannelID, &embed)
 	return "", nil
 }
 
@@ -47,9 +50,8 @@ func gamebattlesPlayers(players []gamebattles.Player) []Player {
 	return genericPlayers
 }
 
-func searchGamebattles(db *db.Handler, team_id int, name string, teamStats *TeamStats) (string, error) {
-	var tournamentLink string
-	err := db.QueryRow("SELECT tournament_link FROM gamebattles WHERE team = $1", team_id).Scan(&tournamentLink)
+func searchGamebattles(db db.Store, team_id int, name string, teamStats *TeamStats) (string, error) {
+	tournament, err := db.Tournament(team.Gamebattles, team_id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "No config for Gamebattles; use !set_tournament.", nil
@@ -57,7 +59,7 @@ func searchGamebattles(db *db.Handler, team_id int, name string, teamStats *Team
 		return fmt.Sprintf("Error grabbing Gamebattles config: %s", err), err
 	}
 
-	urlSplit := strings.Split(tournamentLink, "/")
+	urlSplit := strings.Split(tournament.Link, "/")
 	id, err := gamebattles.GetTournamentID(urlSplit[6], urlSplit[4], urlSplit[3])
 	if err != nil {
 		return fmt.Sprintf("Error getting tournament ID: %s", id), err
@@ -67,16 +69,16 @@ func searchGamebattles(db *db.Handler, team_id int, name string, teamStats *Team
 		return fmt.Sprintf("Error getting participant list: %s", id), err
 	}
 	var foundTeams []gamebattles.Team
-	for _, team := range teams {
-		if strings.Contains(team.TeamName, name) {
-			foundTeams = append(foundTeams, team)
+	for _, t := range teams {
+		if strings.Contains(t.TeamName, name) {
+			foundTeams = append(foundTeams, t)
 		}
 	}
 
 	if len(foundTeams) > 1 {
 		var names []string
-		for _, team := range foundTeams {
-			names = append(names, team.TeamName)
+		for _, t := range foundTeams {
+			names = append(names, t.TeamName)
 		}
 		return formatNames(names), nil
 	} else if len(foundTeams) == 0 {
@@ -94,7 +96,7 @@ func searchGamebattles(db *db.Handler, team_id int, name string, teamStats *Team
 	return "", nil
 }
 
-func matchGamebattles(db *db.Handler, team, round int, teamStats *TeamStats) (string, error) {
+func matchGamebattles(db db.Store, team, round int, teamStats *TeamStats) (string, error) {
 	// TODO: figure out how the rounds api stuff works on gamebattles
 	//       https://gamebattles.majorleaguegaming.com/pc/overwatch/tournament/Breakable-Barriers-NA-1/bracket
 	return "i haven't actually done this part yet", nil
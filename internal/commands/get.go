@@ -1,74 +1,123 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"image/png"
 	"log"
 	"strings"
 
 	"github.com/bigheadgeorge/thonky2/pkg/command"
 	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule/render"
 	"github.com/bigheadgeorge/thonky2/pkg/state"
 	"github.com/bwmarrin/discordgo"
 )
 
-var (
-	timeEmotes = []string{":zero:", ":one:", ":two:", ":three:", ":four:", ":five:", ":six:", ":seven:", ":eight:", ":nine:", ":keycap_ten:", "<:eleven:548022654540578827>", "<:twelve:548220054353870849>"}
-)
-
 func init() {
 	examples := [][2]string{
 		{"!get week", "Show the schedule for this week."},
+		{"!get today", "Show player availability for today."},
+		{"!get unscheduled", "Show open scrim blocks."},
+		{"!get week 10/08", "Show the schedule for the week of 10/08 from the schedule's history."},
 	}
-	command.AddCommand("get", "Get information from the configured spreadsheet.", examples, Get)
+	command.AddCommand("get", "Get information from the configured spreadsheet.", examples, Get).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString},
+		command.Arg{Name: "date", Type: command.ArgString, Optional: true},
+	)
 }
 
 // Get formats information from a given spreadsheet into a Discord embed.
-func Get(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
+func Get(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
 	sched := s.FindSchedule(m.GuildID, m.ChannelID)
 	if sched == nil {
 		return "", nil
 	}
-	sheetLink := "https://docs.google.com/spreadsheets/d/" + sched.ID
+	sheetLink := sched.Link()
 
 	var embed *discordgo.MessageEmbed
-	if len(args) == 2 {
-		switch args[1] {
-		case "week":
-			log.Println("getting week")
-			if sched.Week.Container == nil {
-				return "No week schedule, something broke", nil
+	switch args.String("option") {
+	case "week":
+		log.Println("getting week")
+		week := &sched.Week
+		if args.Has("date") {
+			snap, msg, err := findSnapshot(s, sched, args.String("date"))
+			if snap == nil {
+				return msg, err
 			}
-			embed = formatWeek(s, &sched.Week, sheetLink)
-			log.Println("sent week :)")
-		case "today":
-			log.Println("getting today")
-			if sched.Week.Container == nil {
-				return "No week schedule, something broke", nil
-			} else if sched.Players == nil {
-				return "No players, something broke", nil
-			}
-			embed = formatDay(s, &sched.Week, sched.Players, sheetLink, sched.Week.Today())
-		case "unscheduled":
-			log.Println("getting unscheduled")
-			embed = formatUnscheduled(sched, sheetLink)
-		default:
-			return fmt.Sprintf("Invalid option for !get: %q", args[1]), nil
+			snap.Week.Location = sched.Location
+			week = &snap.Week
+		} else if sched.Week.Container == nil {
+			return "No week schedule, something broke", nil
+		}
+		err := sendImage(s, m.ChannelID, baseEmbed("Week of "+week.Date, sheetLink, week.Zone()), "week.png", render.Week(week))
+		if err != nil {
+			return "Error sending the week schedule.", err
+		}
+		log.Println("sent week :)")
+		return "", nil
+	case "today":
+		log.Println("getting today")
+		if sched.Week.Container == nil {
+			return "No week schedule, something broke", nil
+		} else if sched.Players == nil {
+			return "No players, something broke", nil
+		}
+		today := sched.Week.Today()
+		err := sendImage(s, m.ChannelID, baseEmbed("Schedule for "+sched.Week.Days[today], sheetLink, sched.Week.Zone()), "today.png", render.Day(&sched.Week, sched.Players, today))
+		if err != nil {
+			return "Error sending today's schedule.", err
 		}
+		return "", nil
+	case "unscheduled":
+		log.Println("getting unscheduled")
+		scrims, err := s.DB.Scrims(s.FindTeam(m.GuildID, m.ChannelID).ID, sched.Week.Now())
+		if err != nil {
+			return "Error grabbing scrims.", err
+		}
+		embed = formatUnscheduled(sched, scrims, sheetLink)
+	default:
+		return fmt.Sprintf("Invalid option for !get: %q", args.String("option")), nil
 	}
 
 	for _, field := range embed.Fields {
 		log.Println(*field)
 	}
-	_, err := s.Session.ChannelMessageSendEmbed(m.ChannelID, embed)
+	_, err := s.Messenger.ChannelMessageSendEmbed(m.ChannelID, embed)
 	if err != nil {
 		return err.Error(), err
 	}
 	return "", nil
 }
 
+// sendImage sends an embed showing an image drawn of a schedule, attached as a PNG file.
+func sendImage(s *state.State, channelID string, embed *discordgo.MessageEmbed, name string, img image.Image) error {
+	file, err := attachImage(embed, name, img)
+	if err != nil {
+		return err
+	}
+	_, err = s.Messenger.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  []*discordgo.File{file},
+	})
+	return err
+}
+
+// attachImage shows an image in an embed, returning the PNG file it has to be sent with.
+func attachImage(embed *discordgo.MessageEmbed, name string, img image.Image) (*discordgo.File, error) {
+	var b bytes.Buffer
+	err := png.Encode(&b, img)
+	if err != nil {
+		return nil, err
+	}
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + name}
+	return &discordgo.File{Name: name, ContentType: "image/png", Reader: &b}, nil
+}
+
 // baseEmbed returns a template embed with the decorative stuff set up all ez
 func baseEmbed(title, sheetLink, timezone string) *discordgo.MessageEmbed {
-	return &discordgo.MessageEmbed{
+	embed := &discordgo.MessageEmbed{
 		Color: 0x2ecc71,
 		Thumbnail: &discordgo.MessageEmbedThumbnail{
 			URL: "https://cdn.discordapp.com/attachments/437847669839495170/476837854966710282/thonk.png",
@@ -82,141 +131,44 @@ func baseEmbed(title, sheetLink, timezone string) *discordgo.MessageEmbed {
 			Text: "Times shown in " + timezone,
 		},
 	}
-}
-
-// addTimeField adds a field to the given embed with time emotes
-func addTimeField(e *discordgo.MessageEmbed, title string, week *schedule.Week) {
-	var timeString string
-	for i := 0; i < len(week.Container[0])-1; i++ {
-		timeString += timeEmotes[week.StartTime+(i*week.BlockLength)] + ", "
-	}
-	timeString += timeEmotes[week.StartTime+(len(week.Container[0])-1)*week.BlockLength]
-	e.Fields = append(e.Fields, &discordgo.MessageEmbedField{Name: title, Value: timeString})
-}
-
-// formatWeek formats week information into a Discord embed
-func formatWeek(s *state.State, w *schedule.Week, sheetLink string) *discordgo.MessageEmbed {
-	embed := baseEmbed("Week of "+w.Date, sheetLink, w.Timezone)
-	addTimeField(embed, "Times", w)
-	emojiGuild, err := s.Session.Guild("437847669839495168")
-	if err != nil {
-		return embed
+	if sheetLink == "" {
+		// schedules that aren't on Google Sheets don't get the Sheets icon
+		embed.Author.IconURL = ""
 	}
-
-	days := w.Values()
-	today := w.Today()
-	for i := 0; i < 7; i++ {
-		var activityEmojis []string
-		currDay := (i + today) % 7
-		for j := 0; j < len(days[0]); j++ {
-			activity := days[currDay][j]
-			if activity == "" || activity == "TBD" {
-				activityEmojis = append(activityEmojis, ":grey_question:")
-				continue
-			}
-			emojiName := strings.Replace(strings.ToLower(activity), " ", "_", -1)
-			var found bool
-			for _, emoji := range emojiGuild.Emojis {
-				if emoji.Name == emojiName {
-					activityEmojis = append(activityEmojis, emoji.MessageFormat())
-					found = true
-					break
-				}
-			}
-			if !found {
-				activityEmojis = append(activityEmojis, fmt.Sprintf(":regional_indicator_%s:", strings.ToLower(string(activity[0]))))
-			}
-		}
-		var dayName string
-		if currDay == today {
-			dayName = "**" + w.Days[currDay] + "**"
-		} else {
-			dayName = w.Days[currDay]
-		}
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: dayName, Value: strings.Join(activityEmojis, ", "), Inline: false})
-	}
-
 	return embed
 }
 
-func roleEmoji(role string) string {
-	switch role {
-	case "Tanks":
-		return ":shield:"
-	case "DPS":
-		return ":crossed_swords:"
-	case "Supports":
-		return ":ambulance:"
-	case "Coaches":
-		return ":books:"
-	case "Flex":
-		return ":muscle:"
-	default:
-		return ""
-	}
-}
-
-func formatDay(s *state.State, w *schedule.Week, p []schedule.Player, sheetLink string, day int) *discordgo.MessageEmbed {
-	embed := baseEmbed("Schedule for "+w.Days[day], sheetLink, w.Timezone)
-	addTimeField(embed, "Players", w)
-
-	roleAvailability := map[string]*[6]int{}
-	for _, player := range p {
-		if roleAvailability[player.Role] == nil {
-			roleAvailability[player.Role] = &[6]int{}
-		}
+// formatUnscheduled highlights open scrim blocks, and ones with scrims proposed that haven't been confirmed yet, next to
+// each block's hours.
+func formatUnscheduled(sched *schedule.Schedule, scrims []schedule.Scrim, sheetLink string) *discordgo.MessageEmbed {
+	embed := baseEmbed("Open Scrims", sheetLink, sched.Week.Zone())
 
-		var emojis []string
-		for i, response := range player.AvailabilityOn(day) {
-			var emoji string
-			switch response {
-			case "Yes":
-				roleAvailability[player.Role][i]++
-				emoji = ":white_check_mark:"
-			case "Maybe":
-				emoji = ":grey_question:"
-			case "No":
-				emoji = ":x:"
-			}
-			emojis = append(emojis, emoji)
+	proposed := make(map[[2]int]bool)
+	for _, scrim := range scrims {
+		day, start, end, ok := scrim.Blocks(&sched.Week)
+		if !ok || scrim.Status != schedule.ScrimProposed {
+			continue
 		}
-		emojiString := strings.Join(emojis, ", ")
-
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: roleEmoji(player.Role) + " " + player.Name, Value: emojiString, Inline: false})
-	}
-
-	for role, counts := range roleAvailability {
-		var availability []string
-		for _, count := range counts {
-			availability = append(availability, timeEmotes[count])
+		for block := start; block < end; block++ {
+			proposed[[2]int{day, block}] = true
 		}
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: roleEmoji(role) + " " + role, Value: strings.Join(availability, ", "), Inline: false})
 	}
 
-	return embed
-}
-
-// formatUnscheduled highlights open scrim blocks
-func formatUnscheduled(sched *schedule.Schedule, sheetLink string) *discordgo.MessageEmbed {
-	embed := baseEmbed("Open Scrims", sheetLink, sched.Week.Timezone)
-	addTimeField(embed, "Times", &sched.Week)
-
 	today := sched.Week.Today()
 	activities := sched.Week.Values()
 	for i := 0; i < 7; i++ {
 		currDay := (i + today) % 7
-		var open string
+		blocks := make([]string, len(activities[currDay]))
 		for j, activity := range activities[currDay] {
-			if activity == "Scrim" && sched.Week.Container[currDay][j].Note == "" {
-				open += ":regional_indicator_o:"
-			} else {
-				open += ":black_large_square:"
-			}
-			if j < 5 {
-				open += ", "
+			status := ":black_large_square:"
+			if proposed[[2]int{currDay, j}] {
+				status = ":regional_indicator_p:"
+			} else if activity == "Scrim" && sched.Week.Container[currDay][j].Note == "" {
+				status = ":regional_indicator_o:"
 			}
+			blocks[j] = sched.Week.BlockHours(currDay, j) + " " + status
 		}
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: sched.Week.Days[currDay], Value: open, Inline: false})
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: sched.Week.Days[currDay], Value: strings.Join(blocks, ", "), Inline: false})
 	}
 	return embed
 }
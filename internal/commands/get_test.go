@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule/render"
+)
+
+func TestSendImage(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	h := commandtest.New()
+
+	embed := baseEmbed("Week of "+sched.Week.Date, "", sched.Week.Zone())
+	err := sendImage(h.State, h.ChannelID, embed, "week.png", render.Week(&sched.Week))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := h.Messenger.Sent()
+	if len(sent) != 1 || len(sent[0].Files) != 1 || sent[0].Embed == nil {
+		t.Fatalf("expected an embed with an image attached: %+v", sent)
+	}
+	if sent[0].Embed.Image == nil || sent[0].Embed.Image.URL != "attachment://week.png" {
+		t.Errorf("expected the embed to show the attached image: %+v", sent[0].Embed.Image)
+	}
+	if _, err = png.Decode(sent[0].Files[0].Reader); err != nil {
+		t.Errorf("attached image isn't a PNG: %s", err)
+	}
+}
+
+func TestFormatUnscheduled(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+
+	embed := formatUnscheduled(sched, nil, "")
+	if len(embed.Fields) != 7 {
+		t.Fatalf("wrong amount of days: %d != 7", len(embed.Fields))
+	}
+	for _, field := range embed.Fields {
+		if strings.Contains(field.Value, "<:") {
+			t.Errorf("%s uses a custom emoji: %q", field.Name, field.Value)
+		}
+	}
+	day := sched.Week.Today()
+	if want := sched.Week.BlockHours(day, 0) + " "; !strings.HasPrefix(embed.Fields[0].Value, want) {
+		t.Errorf("%q doesn't start with the first block's hours %q", embed.Fields[0].Value, want)
+	}
+}
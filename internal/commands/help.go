@@ -1,37 +1,66 @@
 package commands
 
 import (
+	"sort"
+
 	"github.com/bigheadgeorge/thonky2/pkg/command"
 	"github.com/bigheadgeorge/thonky2/pkg/state"
 	"github.com/bwmarrin/discordgo"
 )
 
+// maxMessage is the most characters Discord allows in a message.
+const maxMessage = 2000
+
 func init() {
-	command.AddCommand("help", "duh", [][2]string{{"!help", "yeah"}}, help)
+	command.AddCommand("help", "duh", [][2]string{{"!help", "yeah"}, {"!help set", "Show how to use !set."}}, help).SetArgs(
+		command.Arg{Name: "command", Type: command.ArgString, Optional: true},
+	)
 }
 
-func help(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
-	if len(args) > 2 {
-		s.Session.ChannelMessageSend(m.ChannelID, "what the heck are you doing")
-	} else if len(args) == 2 {
-		cmd := command.Commands[args[1]]
-		if cmd.Name != "" {
-			longDoc := "Examples:\n\n"
-			for _, example := range cmd.Examples {
-				longDoc += example[0] + "\n\t" + example[1] + "\n"
-			}
-			cmdHelp := "```\n" + "!" + cmd.Name + ": " + cmd.ShortDoc + "\n\n" + longDoc + "```"
-			s.Session.ChannelMessageSend(m.ChannelID, cmdHelp)
-		} else {
-			s.Session.ChannelMessageSend(m.ChannelID, "No command named \""+args[1]+"\"")
+func help(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	if args.Has("command") {
+		name := args.String("command")
+		cmd := command.Find(name)
+		if cmd == nil {
+			return "No command named \"" + name + "\"", nil
+		}
+		longDoc := "Usage:\n\n" + cmd.Usage() + "\n\nExamples:\n\n"
+		for _, example := range cmd.Examples {
+			longDoc += example[0] + "\n\t" + example[1] + "\n"
 		}
-	} else {
-		cmdList := "```\n"
-		for name, cmd := range command.Commands {
-			cmdList += "!" + name + ":\t" + cmd.ShortDoc + "\n"
+		return "```\n" + "!" + cmd.Name + ": " + cmd.ShortDoc + "\n\n" + longDoc + "```", nil
+	}
+
+	names := make([]string, 0, len(command.Commands))
+	for name := range command.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// the list is split into as many messages as it takes to stay under Discord's limit
+	lists := cmdLists(names)
+	for _, list := range lists[:len(lists)-1] {
+		_, err := s.Messenger.ChannelMessageSend(m.ChannelID, list)
+		if err != nil {
+			return "Error sending the command list.", err
+		}
+	}
+	return lists[len(lists)-1], nil
+}
+
+// cmdLists lists each command's name and short description in code blocks of at most maxMessage characters, with
+// details left to `!help <command>`.
+func cmdLists(names []string) []string {
+	const start, end, footer = "```\n", "```", "\nUse !help <command> to see how to use a command.\n"
+	var lists []string
+	list := start
+	for _, name := range names {
+		line := "!" + name + ":\t" + command.Commands[name].ShortDoc + "\n"
+		if len(list)+len(line)+len(footer)+len(end) > maxMessage {
+			lists = append(lists, list+end)
+			list = start
 		}
-		cmdList += "```"
-		s.Session.ChannelMessageSend(m.ChannelID, cmdList)
+		list += line
 	}
-	return "", nil
+	return append(lists, list+footer+end)
 }
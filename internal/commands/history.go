@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxHistory is how many weeks or snapshots !history lists.
+const maxHistory = 10
+
+func init() {
+	examples := [][2]string{
+		{"!history", "List the weeks saved in the schedule's history."},
+		{"!history 10/08", "List every saved version of the week of 10/08."},
+	}
+	command.AddCommand("history", "List past versions of the schedule.", examples, History).SetArgs(
+		command.Arg{Name: "date", Type: command.ArgString, Optional: true},
+	)
+}
+
+// History lists the weeks in a schedule's history, or the versions saved of one week.
+func History(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+	snapshots, err := s.DB.Snapshots(sched.ID)
+	if err != nil {
+		return "Error grabbing the schedule's history.", err
+	} else if len(snapshots) == 0 {
+		return "No history saved for this schedule yet.", nil
+	}
+	return formatHistory(snapshots, args.String("date"), sched.Week.Now().Location()), nil
+}
+
+// formatHistory lists the weeks in a schedule's history with how many versions each has, or the versions of one week
+// if date isn't empty. Snapshots should be newest first, and times are shown in loc.
+func formatHistory(snapshots []schedule.Snapshot, date string, loc *time.Location) string {
+	var lines []string
+	if date == "" {
+		var dates []string
+		versions := make(map[string][]schedule.Snapshot)
+		for _, snap := range snapshots {
+			if versions[snap.Date] == nil {
+				dates = append(dates, snap.Date)
+			}
+			versions[snap.Date] = append(versions[snap.Date], snap)
+		}
+		for i, d := range dates {
+			if i == maxHistory {
+				lines = append(lines, fmt.Sprintf("...and %d older weeks", len(dates)-maxHistory))
+				break
+			}
+			last := versions[d][0]
+			count := fmt.Sprintf("%d versions", len(versions[d]))
+			if len(versions[d]) == 1 {
+				count = "1 version"
+			}
+			lines = append(lines, fmt.Sprintf("Week of %s: %s, last saved %s (#%d)", d, count, formatSaved(last, loc), last.ID))
+		}
+		return "**Schedule history:**\n" + strings.Join(lines, "\n") + "\nSee the versions of a week with `!history <date>`, and restore one with `!reset <date or #number>`."
+	}
+
+	var found int
+	for _, snap := range snapshots {
+		if !schedule.SameDate(snap.Date, date) {
+			continue
+		}
+		if found++; found <= maxHistory {
+			lines = append(lines, fmt.Sprintf("#%d saved %s", snap.ID, formatSaved(snap, loc)))
+		}
+	}
+	if found == 0 {
+		return fmt.Sprintf("No history saved for the week of %s.", date)
+	} else if found > maxHistory {
+		lines = append(lines, fmt.Sprintf("...and %d older versions", found-maxHistory))
+	}
+	return fmt.Sprintf("**Versions of the week of %s:**\n%s", date, strings.Join(lines, "\n"))
+}
+
+// formatSaved formats when a snapshot was saved in a timezone.
+func formatSaved(snap schedule.Snapshot, loc *time.Location) string {
+	return snap.Saved.In(loc).Format("Mon Jan 2 3:04pm MST")
+}
+
+// findSnapshot returns a snapshot from a schedule's history, either by number, ex. "#12", or the latest one of a week, ex. "10/08".
+// If there isn't one, the reply to send back is returned instead.
+func findSnapshot(s *state.State, sched *schedule.Schedule, ref string) (*schedule.Snapshot, string, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		snapshots, err := s.DB.Snapshots(sched.ID)
+		if err != nil {
+			return nil, "Error grabbing the schedule's history.", err
+		}
+		for _, snap := range snapshots {
+			if schedule.SameDate(snap.Date, ref) {
+				id = snap.ID
+				break
+			}
+		}
+		if id == 0 {
+			return nil, fmt.Sprintf("No history saved for the week of %s.", ref), nil
+		}
+	}
+
+	snap, err := s.DB.Snapshot(sched.ID, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Sprintf("No version #%d in this schedule's history.", id), nil
+	} else if err != nil {
+		return nil, "Error grabbing the schedule's history.", err
+	}
+	return &snap, "", nil
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+)
+
+func TestFormatHistory(t *testing.T) {
+	saved := time.Date(2018, time.October, 9, 15, 4, 0, 0, time.UTC)
+	snapshots := []schedule.Snapshot{
+		{ID: 3, Date: "10/15", Saved: saved.AddDate(0, 0, 7)},
+		{ID: 2, Date: "10/08", Saved: saved.Add(time.Hour)},
+		{ID: 1, Date: "10/08", Saved: saved},
+	}
+
+	msg := formatHistory(snapshots, "", time.UTC)
+	for _, want := range []string{
+		"Week of 10/15: 1 version, last saved Tue Oct 16 3:04pm UTC (#3)",
+		"Week of 10/08: 2 versions, last saved Tue Oct 9 4:04pm UTC (#2)",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("%q doesn't contain %q", msg, want)
+		}
+	}
+	if strings.Index(msg, "10/15") > strings.Index(msg, "10/08") {
+		t.Errorf("weeks aren't newest first: %q", msg)
+	}
+
+	want := "**Versions of the week of 10/8:**\n#2 saved Tue Oct 9 4:04pm UTC\n#1 saved Tue Oct 9 3:04pm UTC"
+	if msg = formatHistory(snapshots, "10/8", time.UTC); msg != want {
+		t.Errorf("%q != %q", msg, want)
+	}
+	if msg = formatHistory(snapshots, "10/22", time.UTC); !strings.Contains(msg, "No history") {
+		t.Errorf("expected no history for 10/22, got %q", msg)
+	}
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	examples := [][2]string{
+		{"!layout", "Show where thonky looks for everything on the spreadsheet."},
+	}
+	command.AddCommand("layout", "Show the spreadsheet layout.", examples, ShowLayout)
+
+	examples = [][2]string{
+		{`!set_layout {"roster_size": 8}`, "Only read the first 8 rows of the roster."},
+		{`!set_layout {"week_sheet": "Week", "days": "A2", "times": "B1", "timezone": "H1"}`, "Read the week from a sheet called \"Week\" that starts a column and row earlier."},
+		{`!set_layout {"afternoon": false}`, "Read bare hours on the week schedule, ex. 4-5, as 24-hour time instead of PM."},
+		{"!set_layout {}", "Go back to the default layout."},
+	}
+	command.AddCommand("set_layout", "Change where thonky looks for everything on the spreadsheet.", examples, SetLayout).SetArgs(
+		command.Arg{Name: "layout", Type: command.ArgRest},
+	).SetPermission(command.Admin)
+}
+
+// sheetsSource returns the spreadsheet a schedule is kept in, or nil if it isn't kept in one.
+func sheetsSource(sched *schedule.Schedule) *schedule.Sheets {
+	sheets, _ := sched.Source.(*schedule.Sheets)
+	return sheets
+}
+
+// ShowLayout shows the layout of the spreadsheet for the team in a channel.
+func ShowLayout(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+	sheets := sheetsSource(sched)
+	if sheets == nil {
+		return "This schedule isn't kept in a spreadsheet.", nil
+	}
+
+	b, err := json.MarshalIndent(sheets.Layout, "", "  ")
+	if err != nil {
+		return "Error encoding layout, something stupid happened", err
+	}
+	return "```json\n" + string(b) + "\n```", nil
+}
+
+// SetLayout changes the layout of the spreadsheet for the team in a channel.
+// The spreadsheet is grabbed again with the new layout, and if it doesn't match, the old layout is kept.
+func SetLayout(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	team := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+	sheets := sheetsSource(sched)
+	if sheets == nil {
+		return "This schedule isn't kept in a spreadsheet.", nil
+	}
+
+	layout, err := schedule.ParseLayout([]byte(args.String("layout")))
+	if err != nil {
+		return "Invalid layout: " + err.Error(), nil
+	}
+
+	err = sched.SetSource(sheets.WithLayout(layout))
+	if err != nil {
+		return "The spreadsheet doesn't match that layout, keeping the old one.\n" + err.Error(), nil
+	}
+
+	b, err := json.Marshal(layout)
+	if err != nil {
+		return "Error encoding layout, something stupid happened", err
+	}
+	err = s.DB.SetLayout(team.ID, b)
+	if err != nil {
+		return "Error saving layout.", err
+	}
+
+	err = s.DB.CacheSchedule(sched)
+	if err != nil {
+		log.Println(err)
+	}
+	return "Updated layout. :)", nil
+}
@@ -0,0 +1,351 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxLFSResults is how many teams !lfs shows.
+const maxLFSResults = 10
+
+func init() {
+	examples := [][2]string{
+		{"!lfs", "Find teams on the board who are open at the same times as this team."},
+		{"!lfs join NA 3500-4000", "Put this team on the board for NA at 3500 to 4000 SR, with requests sent to this channel."},
+		{"!lfs leave", "Take this team off the board."},
+		{"!lfs request 12 thursday 7-9", "Ask team 12 for a scrim from 7 to 9 on Thursday, in this team's timezone."},
+		{"!lfs accept 4", "Accept request #4, booking the scrim on both teams' schedules."},
+		{"!lfs decline 4", "Decline request #4."},
+	}
+	command.AddCommand("lfs", "Find scrims against teams in other servers on the looking for scrim board.", examples, LFS).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+		command.Arg{Name: "values", Type: command.ArgRest, Optional: true},
+	).SetPermission(command.Captain)
+}
+
+// LFS manages a team's listing on the looking for scrim board, searches it, and requests scrims from other teams on it.
+// Teams on the board are matched by region and SR, and by the open blocks on their schedules, which are compared in
+// absolute time so teams in different timezones line up.
+func LFS(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	switch option := strings.ToLower(args.String("option")); option {
+	case "", "search":
+		return searchLFS(s, t, sched)
+	case "join":
+		return joinLFS(s, t, m.ChannelID, args.Raw[1:])
+	case "leave":
+		err := s.DB.RemoveLFSListing(t.ID)
+		if err != nil {
+			return "Error leaving the board.", err
+		}
+		return "Took the team off the looking for scrim board.", nil
+	case "request":
+		return requestScrim(s, t, sched, args.Raw[1:])
+	case "accept", "decline":
+		id, err := strconv.Atoi(strings.TrimPrefix(args.String("values"), "#"))
+		if err != nil {
+			return fmt.Sprintf("Give the number of a request, ex. `!lfs %s 4`", option), nil
+		}
+		if option == "decline" {
+			return declineScrim(s, t, id)
+		}
+		return acceptScrim(s, t, sched, id)
+	default:
+		return fmt.Sprintf("Invalid option for !lfs: %q; use join, leave, request, accept, decline or nothing.", option), nil
+	}
+}
+
+// joinLFS lists a team on the board from tokens like `NA 3500-4000`, or updates its listing.
+func joinLFS(s *state.State, t team.Team, channelID string, tokens []string) (string, error) {
+	if len(tokens) != 2 {
+		return "Usage: `!lfs join <region> <SR range>`, ex. `!lfs join NA 3500-4000`", nil
+	}
+	l := team.LFSListing{Team: t.ID, Region: strings.ToUpper(tokens[0]), ChannelID: channelID}
+	var err error
+	sr := strings.SplitN(tokens[1], "-", 2)
+	l.MinSR, err = strconv.Atoi(sr[0])
+	l.MaxSR = l.MinSR
+	if err == nil && len(sr) == 2 {
+		l.MaxSR, err = strconv.Atoi(sr[1])
+	}
+	if err != nil || l.MinSR < 0 || l.MinSR > l.MaxSR {
+		return fmt.Sprintf("Invalid SR range %q, ex. 3500-4000.", tokens[1]), nil
+	}
+
+	err = s.DB.SetLFSListing(l)
+	if err != nil {
+		return "Error joining the board.", err
+	}
+	return fmt.Sprintf("Listed %s on the looking for scrim board for %s. Requests will be sent to this channel.", s.TeamName(t), formatListing(l)), nil
+}
+
+// formatListing formats where and at what SR a team plays, ex. "NA at 3500-4000 SR".
+func formatListing(l team.LFSListing) string {
+	if l.MinSR == l.MaxSR {
+		return fmt.Sprintf("%s at %d SR", l.Region, l.MinSR)
+	}
+	return fmt.Sprintf("%s at %d-%d SR", l.Region, l.MinSR, l.MaxSR)
+}
+
+// listing returns a team's listing, or the reply to send back if it isn't on the board.
+func listing(s *state.State, t team.Team) (team.LFSListing, string, error) {
+	l, err := s.DB.LFSListing(t.ID)
+	if err == sql.ErrNoRows {
+		return l, "Put the team on the board first, ex. `!lfs join NA 3500-4000`", nil
+	} else if err != nil {
+		return l, "Error grabbing the team's listing.", err
+	}
+	return l, "", nil
+}
+
+// searchLFS lists the teams on the board that match a team and are open at the same times, in the team's timezone.
+func searchLFS(s *state.State, t team.Team, sched *schedule.Schedule) (string, error) {
+	ours, msg, err := listing(s, t)
+	if msg != "" {
+		return msg, err
+	}
+	listings, err := s.DB.LFSListings()
+	if err != nil {
+		return "Error grabbing the board.", err
+	}
+
+	open := schedule.OpenWindows(&sched.Week, sched.Week.Now())
+	lines := []string{fmt.Sprintf("**Teams open at the same times** (%s):", sched.Week.Zone())}
+	for _, l := range listings {
+		if l.Team == t.ID || !ours.Matches(l) {
+			continue
+		}
+		theirs := s.TeamSchedule(l.Team)
+		other, err := s.DB.Team(l.Team)
+		if theirs == nil || err != nil {
+			continue
+		}
+		both := schedule.Overlap(open, schedule.OpenWindows(&theirs.Week, theirs.Week.Now()))
+		if len(both) == 0 {
+			continue
+		}
+		times := make([]string, len(both))
+		for i, w := range both {
+			times[i] = formatWindow(&sched.Week, w)
+		}
+		lines = append(lines, fmt.Sprintf("`%d` **%s** (%s): %s", l.Team, s.TeamName(other), formatListing(l), strings.Join(times, ", ")))
+		if len(lines) > maxLFSResults {
+			break
+		}
+	}
+	if len(lines) == 1 {
+		return "No teams on the board are open at the same times.", nil
+	}
+	lines = append(lines, "Ask one for a scrim with `!lfs request <team> <day> <time range>`.")
+	return strings.Join(lines, "\n"), nil
+}
+
+// requestScrim asks another team on the board for a scrim, from tokens like `12 thursday 7-9`. The time is in the
+// requesting team's timezone, and has to be open on both teams' schedules.
+func requestScrim(s *state.State, t team.Team, sched *schedule.Schedule, tokens []string) (string, error) {
+	if len(tokens) != 3 {
+		return "Usage: `!lfs request <team> <day> <time range>`, with a team from `!lfs`", nil
+	}
+	ours, msg, err := listing(s, t)
+	if msg != "" {
+		return msg, err
+	}
+	otherID, err := strconv.Atoi(tokens[0])
+	if err != nil || otherID == t.ID {
+		return fmt.Sprintf("Invalid team %q; use a team from `!lfs`.", tokens[0]), nil
+	}
+	theirs, err := s.DB.LFSListing(otherID)
+	if err == sql.ErrNoRows {
+		return fmt.Sprintf("Team %d isn't on the board.", otherID), nil
+	} else if err != nil {
+		return "Error grabbing the team's listing.", err
+	}
+	other, err := s.DB.Team(otherID)
+	if err != nil {
+		return "Error grabbing the team.", err
+	}
+	otherSched := s.TeamSchedule(otherID)
+	if otherSched == nil {
+		return fmt.Sprintf("%s doesn't have a schedule.", s.TeamName(other)), nil
+	}
+
+	weekday, err := command.ParseDay(tokens[1])
+	if err != nil {
+		return fmt.Sprintf("Invalid day %q.", tokens[1]), nil
+	}
+	hours, err := command.ParseTimeRange(tokens[2])
+	if err != nil {
+		return fmt.Sprintf("Invalid time range %q.", tokens[2]), nil
+	}
+	day := sched.Week.Weekday(int(weekday))
+	start, end, err := sched.Week.BlockRange(day, hours.Start, hours.End)
+	if err != nil {
+		return "Error parsing input: " + err.Error(), nil
+	}
+	r := schedule.ScrimRequest{
+		From:   t.ID,
+		To:     otherID,
+		Start:  sched.Week.BlockStart(day, start),
+		End:    sched.Week.BlockEnd(day, end-1),
+		Status: schedule.ScrimProposed,
+	}
+	if r.Start.Before(sched.Week.Now()) {
+		return "That block has already started.", nil
+	} else if !sched.Week.OpenBetween(r.Start, r.End) {
+		return "This team isn't open then.", nil
+	} else if !otherSched.Week.OpenBetween(r.Start, r.End) {
+		return fmt.Sprintf("%s isn't open then; see when they are with `!lfs`.", s.TeamName(other)), nil
+	}
+
+	r.ID, err = s.DB.AddScrimRequest(r)
+	if err != nil {
+		return "Error sending the request.", err
+	}
+	window := schedule.Window{Start: r.Start, End: r.End}
+	err = notifyTeam(s, theirs.ChannelID, fmt.Sprintf("**%s** (%s) asked for a scrim on %s %s. Use `!lfs accept %d` or `!lfs decline %d`.",
+		s.TeamName(t), formatListing(ours), formatWindow(&otherSched.Week, window), otherSched.Week.Zone(), r.ID, r.ID))
+	if err != nil {
+		return "Error sending the request.", err
+	}
+	log.Printf("team %d requested a scrim from team %d\n", t.ID, otherID)
+	return fmt.Sprintf("Sent request #%d to %s for %s.", r.ID, s.TeamName(other), formatWindow(&sched.Week, window)), nil
+}
+
+// pendingRequest returns a request sent to a team that hasn't been answered, or the reply to send back if there isn't
+// one.
+func pendingRequest(s *state.State, t team.Team, id int) (schedule.ScrimRequest, string, error) {
+	r, err := s.DB.ScrimRequest(id)
+	if err == sql.ErrNoRows || (err == nil && r.To != t.ID) {
+		return r, fmt.Sprintf("No request #%d for this team.", id), nil
+	} else if err != nil {
+		return r, "Error grabbing the request.", err
+	}
+	switch r.Status {
+	case schedule.ScrimConfirmed:
+		return r, fmt.Sprintf("Request #%d was already accepted.", id), nil
+	case schedule.ScrimCancelled:
+		return r, fmt.Sprintf("Request #%d was already declined.", id), nil
+	}
+	return r, "", nil
+}
+
+// acceptScrim accepts a request sent to a team, booking the scrim on both teams' schedules and telling the team that
+// asked for it. The request is only marked accepted once both scrims are booked, and they're cancelled if it can't be.
+func acceptScrim(s *state.State, t team.Team, sched *schedule.Schedule, id int) (string, error) {
+	r, msg, err := pendingRequest(s, t, id)
+	if msg != "" {
+		return msg, err
+	}
+	from, err := s.DB.Team(r.From)
+	if err != nil {
+		return "Error grabbing the team that sent the request.", err
+	}
+	fromSched := s.TeamSchedule(r.From)
+	if r.Start.Before(sched.Week.Now()) {
+		return "That scrim would have already started.", nil
+	} else if fromSched == nil || !fromSched.Week.OpenBetween(r.Start, r.End) {
+		return fmt.Sprintf("%s isn't open then anymore.", s.TeamName(from)), nil
+	} else if !sched.Week.OpenBetween(r.Start, r.End) {
+		return "This team isn't open then anymore.", nil
+	}
+
+	ours, err := bookRequested(s, t, sched, from, r)
+	if err != nil {
+		unbook(s, sched, ours)
+		return "Error booking the scrim.", err
+	}
+	theirs, err := bookRequested(s, from, fromSched, t, r)
+	if err != nil {
+		unbook(s, sched, ours)
+		unbook(s, fromSched, theirs)
+		return "Error booking the scrim for the other team.", err
+	}
+	err = s.DB.SetScrimRequestStatus(id, schedule.ScrimConfirmed)
+	if err != nil {
+		unbook(s, sched, ours)
+		unbook(s, fromSched, theirs)
+		return "Error accepting the request.", err
+	}
+
+	log.Printf("team %d accepted scrim request #%d from team %d\n", t.ID, id, r.From)
+	if l, err := s.DB.LFSListing(r.From); err == nil {
+		notifyTeam(s, l.ChannelID, fmt.Sprintf("%s accepted request #%d! Booked scrim %s", s.TeamName(t), id, formatScrim(&fromSched.Week, theirs)))
+	}
+	return "Booked scrim " + formatScrim(&sched.Week, ours), nil
+}
+
+// bookRequested books an accepted request as a confirmed scrim for one of its teams, and writes it on their sheet.
+func bookRequested(s *state.State, t team.Team, sched *schedule.Schedule, opponent team.Team, r schedule.ScrimRequest) (schedule.Scrim, error) {
+	scrim := schedule.Scrim{
+		Team:     t.ID,
+		Start:    r.Start,
+		End:      r.End,
+		Opponent: s.TeamName(opponent),
+		Status:   schedule.ScrimConfirmed,
+	}
+	if l, err := s.DB.LFSListing(opponent.ID); err == nil {
+		scrim.Region = l.Region
+	}
+	var err error
+	scrim.ID, err = s.DB.AddScrim(scrim)
+	if err != nil {
+		return scrim, err
+	}
+	return scrim, mirrorScrim(sched, scrim, "", scrimActivity(sched))
+}
+
+// unbook cancels a scrim booked for a request that couldn't be accepted. Scrims that weren't saved are skipped.
+func unbook(s *state.State, sched *schedule.Schedule, scrim schedule.Scrim) {
+	if scrim.ID == 0 {
+		return
+	}
+	err := s.DB.SetScrimStatus(scrim.Team, scrim.ID, schedule.ScrimCancelled)
+	if err == nil {
+		previous := scrim.Note()
+		scrim.Status = schedule.ScrimCancelled
+		err = mirrorScrim(sched, scrim, previous, "")
+	}
+	if err != nil {
+		log.Printf("error cancelling scrim #%d for team %d: %s\n", scrim.ID, scrim.Team, err)
+	}
+}
+
+// declineScrim declines a request sent to a team, and tells the team that asked for it.
+func declineScrim(s *state.State, t team.Team, id int) (string, error) {
+	r, msg, err := pendingRequest(s, t, id)
+	if msg != "" {
+		return msg, err
+	}
+	err = s.DB.SetScrimRequestStatus(id, schedule.ScrimCancelled)
+	if err != nil {
+		return "Error declining the request.", err
+	}
+	if l, err := s.DB.LFSListing(r.From); err == nil {
+		notifyTeam(s, l.ChannelID, fmt.Sprintf("%s declined request #%d.", s.TeamName(t), id))
+	}
+	return fmt.Sprintf("Declined request #%d.", id), nil
+}
+
+// notifyTeam sends a message to another team's channel. Team names come from other guilds, so nobody is pinged by
+// anything in it.
+func notifyTeam(s *state.State, channelID, content string) error {
+	_, err := s.Messenger.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	return err
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+)
+
+func TestLFS(t *testing.T) {
+	ours, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	theirs, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	nextWeek(ours)
+	nextWeek(theirs)
+	// their blocks are an hour ahead, so their 7 PM block is our 6 PM block
+	theirs.SetLocation(time.FixedZone("UTC+1", 60*60))
+
+	h := commandtest.New()
+	h.AddTeam("Ascension", ours)
+	h.GuildID, h.ChannelID = "110000000000000000", "210000000000000000"
+	inked := h.AddTeam("Inked", theirs)
+	h.Send("!lfs join na 3000-3800")
+	h.GuildID, h.ChannelID = "100000000000000000", "200000000000000000"
+
+	if sent := h.Send("!lfs"); len(sent) != 1 || !strings.Contains(sent[0].Content, "join") {
+		t.Fatalf("expected to be told to join the board first, got %+v", sent)
+	}
+	h.Send("!lfs join NA 3500-4000")
+	sent := h.Send("!lfs")
+	if len(sent) != 1 || !strings.Contains(sent[0].Content, "**Inked** (NA at 3000-3800 SR)") {
+		t.Fatalf("expected Inked to be found: %+v", sent)
+	}
+
+	// Thursday at 8 PM is their VOD
+	if sent = h.Send("!lfs request 2 thursday 7-9"); len(sent) != 1 || !strings.Contains(sent[0].Content, "isn't open") {
+		t.Errorf("expected a time they aren't open to be rejected, got %+v", sent)
+	}
+	sent = h.Send("!lfs request 2 thursday 6-8")
+	if len(sent) != 2 || sent[0].ChannelID != "210000000000000000" || !strings.Contains(sent[0].Content, "!lfs accept 1") {
+		t.Fatalf("request wasn't sent to Inked: %+v", sent)
+	}
+	if !strings.Contains(sent[0].Content, "7pm-9pm UTC+1") {
+		t.Errorf("request isn't in Inked's timezone: %q", sent[0].Content)
+	}
+	if m := sent[0].AllowedMentions; m == nil || len(m.Parse)+len(m.Users)+len(m.Roles) != 0 {
+		t.Errorf("request to another guild can ping: %+v", m)
+	}
+
+	h.GuildID, h.ChannelID = "110000000000000000", "210000000000000000"
+	sent = h.Send("!lfs accept 1")
+	if len(sent) != 2 || sent[0].ChannelID != "200000000000000000" || !strings.Contains(sent[1].Content, "Booked scrim") {
+		t.Fatalf("request wasn't accepted: %+v", sent)
+	}
+	if sent[0].AllowedMentions == nil {
+		t.Error("acceptance sent to another guild can ping")
+	}
+	for _, block := range []int{2, 3} {
+		if got := ours.Week.Container[3][block].Note; got != "vs Inked (confirmed) | NA" {
+			t.Errorf("wrong note on our block %d: %q", block, got)
+		}
+	}
+	for _, block := range []int{3, 4} {
+		if got := theirs.Week.Container[3][block].Note; got != "vs Ascension (confirmed) | NA" {
+			t.Errorf("wrong note on their block %d: %q", block, got)
+		}
+	}
+	scrims, err := h.Store.Scrims(inked.ID, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scrims) != 1 || scrims[0].Status != schedule.ScrimConfirmed {
+		t.Errorf("scrim wasn't booked for Inked: %+v", scrims)
+	}
+	if sent = h.Send("!lfs decline 1"); len(sent) != 1 || !strings.Contains(sent[0].Content, "already accepted") {
+		t.Errorf("expected the request to already be accepted, got %+v", sent)
+	}
+}
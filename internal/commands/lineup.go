@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	examples := [][2]string{
+		{"!lineup thursday 7pm", "Pick a lineup for the block at 7pm on Thursday from who's available."},
+	}
+	command.AddCommand("lineup", "Pick a lineup for a block from player availability.", examples, PickLineup).SetArgs(
+		command.Arg{Name: "day", Type: command.ArgDay},
+		command.Arg{Name: "time", Type: command.ArgTimeRange},
+	)
+
+	examples = [][2]string{
+		{"!set_starters Taub Tydra", "Pick Taub, then Tydra, over other players available for the same role."},
+		{"!set_starters", "Stop preferring any players."},
+	}
+	command.AddCommand("set_starters", "Set the players preferred when picking lineups.", examples, SetStarters).SetArgs(
+		command.Arg{Name: "players", Type: command.ArgPlayer, Optional: true, Multiple: true},
+	).SetPermission(command.Captain)
+}
+
+// PickLineup picks a lineup for a block with the team's lineup and preferred starters. Between players who are
+// otherwise tied, the ones who showed up more reliably over the last attendanceDays days are picked.
+func PickLineup(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	day := sched.Week.Weekday(int(args.Day("day")))
+	start := args.TimeRange("time").Start
+	block, ok := sched.Week.BlockIndex(day, start)
+	if !ok {
+		return "No block at that time on " + sched.Week.Days[day] + ".", nil
+	}
+	lineup, err := s.DB.Lineup(t.ID)
+	if err != nil {
+		return "Error grabbing the team's lineup.", err
+	}
+	starters, err := s.DB.Starters(t.ID)
+	if err != nil {
+		return "Error grabbing the team's starters.", err
+	}
+
+	records, err := s.DB.Attendance(t.ID, sched.Week.Now().AddDate(0, 0, -attendanceDays))
+	if err != nil {
+		return "Error grabbing attendance.", err
+	}
+	reliability := make(map[string]float64)
+	for _, r := range schedule.Reliabilities(sched.Week.Finished(records)) {
+		reliability[r.Player] = r.Rate()
+	}
+
+	pick := schedule.PickLineup(sched.Players, day, block, lineup, starters, reliability)
+	return formatLineup(&sched.Week, day, block, lineup, pick), nil
+}
+
+// formatLineup lists the players picked for each role, flagging roles that couldn't be filled, and the substitutes.
+func formatLineup(week *schedule.Week, day, block int, lineup schedule.Lineup, pick schedule.LineupPick) string {
+	describe := func(p schedule.Pick) string {
+		name := p.Player
+		var notes []string
+		if p.Flex {
+			notes = append(notes, "flex")
+		}
+		if p.Maybe {
+			notes = append(notes, "maybe")
+		}
+		if len(notes) > 0 {
+			name += " (" + strings.Join(notes, ", ") + ")"
+		}
+		return name
+	}
+
+	lines := []string{fmt.Sprintf("**Lineup for %s %s (%s):**", week.WeekdayOf(day), week.BlockHours(day, block), week.Container[day][block].Value)}
+	for _, role := range lineup.Roles() {
+		var names []string
+		for _, p := range pick.Starters {
+			if p.Role == role {
+				names = append(names, describe(p))
+			}
+		}
+		for i := 0; i < pick.Missing[role]; i++ {
+			names = append(names, "**unfilled**")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", role, strings.Join(names, ", ")))
+	}
+	if len(pick.Missing) > 0 {
+		lines = append(lines, ":warning: Missing "+pick.Missing.String())
+	}
+
+	if len(pick.Subs) > 0 {
+		var subs []string
+		for _, p := range pick.Subs {
+			sub := p.Player + " (" + p.Role
+			if p.Maybe {
+				sub += ", maybe"
+			}
+			subs = append(subs, sub+")")
+		}
+		lines = append(lines, "Subs: "+strings.Join(subs, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetStarters sets the players preferred when picking lineups, or clears them if none are given.
+func SetStarters(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+	starters := args.Strings("players")
+	err := s.DB.SetStarters(t.ID, starters)
+	if err != nil {
+		return "Error saving starters.", err
+	}
+	if len(starters) == 0 {
+		return "No players are preferred when picking lineups now.", nil
+	}
+	return "Preferred starters, in order: " + strings.Join(starters, ", "), nil
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	examples := [][2]string{
+		{"!link Tydra", "Let thonky know you're Tydra on the schedule."},
+	}
+	command.AddCommand("link", "Link yourself to your name on the schedule.", examples, Link).SetArgs(
+		command.Arg{Name: "player", Type: command.ArgPlayer},
+	)
+
+	examples = [][2]string{
+		{"!unlink", "Unlink yourself from your name on the schedule."},
+	}
+	command.AddCommand("unlink", "Unlink yourself from the schedule.", examples, Unlink)
+
+	examples = [][2]string{
+		{"!dm_reminders on", "Get a DM before activities you said you're available for."},
+		{"!dm_reminders off", "Stop getting DMs before activities."},
+	}
+	command.AddCommand("dm_reminders", "Turn DM reminders on or off.", examples, DMReminders).SetArgs(
+		command.Arg{Name: "setting", Type: command.ArgString},
+	)
+}
+
+// Link links the author of a message to a player on the schedule for the team in a channel.
+func Link(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+	player := args.Player("player")
+
+	links, err := s.DB.PlayerLinks(t.ID)
+	if err != nil {
+		return "Error grabbing linked players.", err
+	}
+	link := team.PlayerLink{Team: t.ID, UserID: m.Author.ID, Player: player}
+	for _, l := range links {
+		if l.Player == player && l.UserID != m.Author.ID {
+			return fmt.Sprintf("<@%s> is already linked to %s.", l.UserID, player), nil
+		} else if l.UserID == m.Author.ID {
+			link.DMReminders = l.DMReminders
+		}
+	}
+
+	err = s.DB.SetPlayerLink(link)
+	if err != nil {
+		return "Error linking you.", err
+	}
+	log.Printf("linked %s (%s) to %q on team %d\n", m.Author.Username, m.Author.ID, player, t.ID)
+	return fmt.Sprintf("Linked you to %s. :)", player), nil
+}
+
+// Unlink unlinks the author of a message from their player.
+func Unlink(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+	err := s.DB.RemovePlayerLink(t.ID, m.Author.ID)
+	if err != nil {
+		return "Error unlinking you.", err
+	}
+	return "Unlinked you.", nil
+}
+
+// DMReminders turns DM reminders on or off for the author of a message.
+func DMReminders(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+
+	var on bool
+	switch strings.ToLower(args.String("setting")) {
+	case "on", "yes":
+		on = true
+	case "off", "no":
+	default:
+		return fmt.Sprintf("Invalid setting %q; use on or off.", args.String("setting")), nil
+	}
+
+	link, err := s.DB.PlayerLink(t.ID, m.Author.ID)
+	if err == sql.ErrNoRows {
+		return "Link yourself to your name on the schedule first, ex. `!link Tydra`", nil
+	} else if err != nil {
+		return "Error grabbing your link.", err
+	}
+	link.DMReminders = on
+	err = s.DB.SetPlayerLink(link)
+	if err != nil {
+		return "Error updating your settings.", err
+	}
+
+	if on {
+		return fmt.Sprintf("You'll get a DM before activities %s said yes or maybe to.", link.Player), nil
+	}
+	return "You won't get DMs before activities anymore.", nil
+}
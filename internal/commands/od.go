@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/bigheadgeorge/goverbuff"
@@ -14,10 +13,10 @@ import (
 )
 
 // searchOD searches the participants in a tournament for the given name.
-type searchOD func(*db.Handler, int, string, *TeamStats) (string, error)
+type searchOD func(db.Store, int, string, *TeamStats) (string, error)
 
 // matchOD gets stats for the opposing team in a given round in a tournament.
-type matchOD func(*db.Handler, int, int, *TeamStats) (string, error)
+type matchOD func(db.Store, int, int, *TeamStats) (string, error)
 
 // Player has methods for getting information about a player.
 type Player interface {
@@ -39,17 +38,14 @@ type TeamStats struct {
 }
 
 // getTeamStats gets the SR of every player on a team found with the given search and match methods.
-func getTeamStats(s *state.State, m *discordgo.MessageCreate, search searchOD, match matchOD, teamStats *TeamStats) (string, error) {
+// teamName is either part of a team's name or a round number in the tournament.
+func getTeamStats(s *state.State, m *discordgo.MessageCreate, teamName string, search searchOD, match matchOD, teamStats *TeamStats) (string, error) {
 	team := s.FindTeam(m.GuildID, m.ChannelID)
 	if team.ID == 0 {
 		return "No config for this guild.", nil
-	} else if strings.Count(m.Content, " ") == 0 { // hacky argument check
-		return "No args.", nil
 	}
 
 	var msg string
-
-	teamName := m.Content[strings.Index(m.Content, " ")+1:]
 	num, err := strconv.Atoi(teamName)
 	if err != nil {
 		msg, err = search(s.DB, team.ID, teamName, teamStats)
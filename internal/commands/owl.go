@@ -12,11 +12,18 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+// scoreEmotes are the number emotes for scores up to 10.
+var scoreEmotes = []string{":zero:", ":one:", ":two:", ":three:", ":four:", ":five:", ":six:", ":seven:", ":eight:", ":nine:", ":keycap_ten:"}
+
 func init() {
 	examples := [][2]string{
 		{"!owl today", "Get a list of games happening today"},
+		{"!owl next", "Get the next game"},
+		{"!owl now", "Get the game being played right now"},
 	}
-	command.AddCommand("owl", "Get info on Overwatch League games", examples, OWL)
+	command.AddCommand("owl", "Get info on Overwatch League games", examples, OWL).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString},
+	)
 }
 
 // date returns a Time in the format month/day
@@ -34,6 +41,14 @@ func addTime(t int, s, post string) string {
 	return ""
 }
 
+// scoreEmote returns the number emote for a score, or the plain number if there isn't one.
+func scoreEmote(score int) string {
+	if score < 0 || score >= len(scoreEmotes) {
+		return fmt.Sprint(score)
+	}
+	return scoreEmotes[score]
+}
+
 // owlEmbed returns a template for an OWL web embed
 func owlEmbed() *discordgo.MessageEmbed {
 	return &discordgo.MessageEmbed{
@@ -78,12 +93,8 @@ func nextMatchEmbed(m *owl.Match) *discordgo.MessageEmbed {
 }
 
 // OWL posts information about Overwatch League games
-func OWL(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
-	if len(args) == 1 || len(args) > 2 {
-		return "Weird number of args", nil
-	}
-
-	switch strings.ToLower(args[1]) {
+func OWL(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	switch strings.ToLower(args.String("option")) {
 	case "today":
 		sched, err := owl.Matches()
 		if err != nil {
@@ -123,7 +134,7 @@ func OWL(s *state.State, m *discordgo.MessageCreate, args []string) (string, err
 						})
 					}
 
-					s.Session.ChannelMessageSendEmbed(m.ChannelID, embed)
+					s.Messenger.ChannelMessageSendEmbed(m.ChannelID, embed)
 					return "", nil
 				}
 			}
@@ -138,7 +149,7 @@ func OWL(s *state.State, m *discordgo.MessageCreate, args []string) (string, err
 		for _, stage := range sched.Data.Stages {
 			for _, match := range stage.Matches {
 				if match.Status == "PENDING" {
-					s.Session.ChannelMessageSendEmbed(m.ChannelID, nextMatchEmbed(&match))
+					s.Messenger.ChannelMessageSendEmbed(m.ChannelID, nextMatchEmbed(&match))
 					return "", nil
 				}
 			}
@@ -155,9 +166,9 @@ func OWL(s *state.State, m *discordgo.MessageCreate, args []string) (string, err
 		if match.Status == "PENDING" || match.Status == "" {
 			embed = nextMatchEmbed(&match)
 		} else {
-			s.Session.ChannelMessageSendEmbed(m.ChannelID, &discordgo.MessageEmbed{
+			s.Messenger.ChannelMessageSendEmbed(m.ChannelID, &discordgo.MessageEmbed{
 				Color: 0x633FA3,
-				Title: fmt.Sprintf("%s %s - %s %s", timeEmotes[match.Scores[0].Value], match.Teams[0].Name, match.Teams[1].Name, timeEmotes[match.Scores[1].Value]),
+				Title: fmt.Sprintf("%s %s - %s %s", scoreEmote(match.Scores[0].Value), match.Teams[0].Name, match.Teams[1].Name, scoreEmote(match.Scores[1].Value)),
 				URL:   "https://www.twitch.tv/overwatchleague",
 				Video: &discordgo.MessageEmbedVideo{
 					URL:    "https://player.twitch.tv/?channel=overwatchleague&autoplay=true",
@@ -167,9 +178,9 @@ func OWL(s *state.State, m *discordgo.MessageCreate, args []string) (string, err
 			})
 		}
 
-		s.Session.ChannelMessageSendEmbed(m.ChannelID, embed)
+		s.Messenger.ChannelMessageSendEmbed(m.ChannelID, embed)
 	default:
-		return fmt.Sprintf("Invalid option %q.", args[1]), nil
+		return fmt.Sprintf("Invalid option %q.", args.String("option")), nil
 	}
 	return "", nil
 }
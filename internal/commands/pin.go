@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule/render"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	examples := [][2]string{
+		{"!pin_schedule", "Pin the week schedule in this channel and keep it up to date."},
+		{"!pin_schedule today", "Pin player availability for today in this channel and keep it up to date."},
+	}
+	command.AddCommand("pin_schedule", "Pin a schedule in this channel that's edited whenever the schedule changes.", examples, PinSchedule).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+	).SetPermission(command.Captain)
+
+	examples = [][2]string{
+		{"!unpin_schedule", "Stop updating the schedule pinned in this channel."},
+	}
+	command.AddCommand("unpin_schedule", "Stop updating the schedule pinned in this channel.", examples, UnpinSchedule).SetPermission(command.Captain)
+}
+
+// PinSchedule posts the week schedule or today's availability in a channel, pins it, and saves it to be kept up to date.
+// Each channel has one pinned schedule; pinning another replaces the old one.
+func PinSchedule(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	kind := strings.ToLower(args.String("option"))
+	if kind == "" {
+		kind = "week"
+	} else if kind != "week" && kind != "today" {
+		return fmt.Sprintf("Invalid option for !pin_schedule: %q; use week or today.", kind), nil
+	}
+
+	post := team.SchedulePost{Team: t.ID, ChannelID: m.ChannelID, Kind: kind}
+	var err error
+	post.MessageID, err = postSchedule(s, sched, post)
+	if err != nil {
+		return "Error posting the schedule.", err
+	}
+	err = s.DB.SetSchedulePost(post)
+	if err != nil {
+		return "Error saving the pinned schedule.", err
+	}
+	return "", nil
+}
+
+// UnpinSchedule stops keeping the schedule pinned in a channel up to date.
+func UnpinSchedule(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	err := s.DB.RemoveSchedulePost(m.ChannelID)
+	if err != nil {
+		return "Error removing the pinned schedule.", err
+	}
+	return "The schedule in this channel won't be updated anymore.", nil
+}
+
+// scheduleEmbed returns the embed a pinned schedule shows, which is an image of the schedule like !get draws, and the
+// file the image has to be sent with.
+func scheduleEmbed(sched *schedule.Schedule, kind string) (*discordgo.MessageEmbed, *discordgo.File, error) {
+	week := &sched.Week
+	if kind == "today" {
+		today := week.Today()
+		embed := baseEmbed("Schedule for "+week.Days[today], sched.Link(), week.Zone())
+		file, err := attachImage(embed, "today.png", render.Day(week, sched.Players, today))
+		return embed, file, err
+	}
+	embed := baseEmbed("Week of "+week.Date, sched.Link(), week.Zone())
+	file, err := attachImage(embed, "week.png", render.Week(week))
+	return embed, file, err
+}
+
+// postSchedule sends and pins a new message for a pinned schedule, returning its ID.
+func postSchedule(s *state.State, sched *schedule.Schedule, post team.SchedulePost) (string, error) {
+	embed, file, err := scheduleEmbed(sched, post.Kind)
+	if err != nil {
+		return "", err
+	}
+	msg, err := s.Messenger.ChannelMessageSendComplex(post.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  []*discordgo.File{file},
+	})
+	if err != nil {
+		return "", err
+	}
+	err = s.Messenger.ChannelMessagePin(post.ChannelID, msg.ID)
+	if err != nil {
+		// the schedule's still kept up to date without a pin, which needs the manage messages permission
+		log.Printf("error pinning schedule in [%s]: %s\n", post.ChannelID, err)
+	}
+	return msg.ID, nil
+}
+
+// refreshPost edits a pinned schedule to match the schedule, posting it again if the message was deleted.
+// The ID of the message showing the schedule afterwards is returned.
+func refreshPost(s *state.State, sched *schedule.Schedule, post team.SchedulePost) (string, error) {
+	embed, file, err := scheduleEmbed(sched, post.Kind)
+	if err != nil {
+		return post.MessageID, err
+	}
+	// the old image is replaced rather than kept alongside the new one
+	_, err = s.Messenger.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:          post.MessageID,
+		Channel:     post.ChannelID,
+		Embeds:      []*discordgo.MessageEmbed{embed},
+		Files:       []*discordgo.File{file},
+		Attachments: &[]*discordgo.MessageAttachment{},
+	})
+	if err == nil {
+		return post.MessageID, nil
+	}
+	if restErr, ok := err.(*discordgo.RESTError); !ok || restErr.Message == nil || restErr.Message.Code != discordgo.ErrCodeUnknownMessage {
+		return post.MessageID, err
+	}
+	return postSchedule(s, sched, post)
+}
+
+// RefreshSchedulePosts edits every pinned schedule for the teams using a schedule.
+// It's meant to be called whenever the schedule changes.
+func RefreshSchedulePosts(s *state.State, sched *schedule.Schedule) {
+	posts, err := s.DB.SchedulePosts(sched.ID)
+	if err != nil {
+		log.Printf("error grabbing pinned schedules for [%s]: %s\n", sched.ID, err)
+		return
+	}
+	for _, post := range posts {
+		id, err := refreshPost(s, sched, post)
+		if err != nil {
+			log.Printf("error refreshing pinned schedule in [%s]: %s\n", post.ChannelID, err)
+			continue
+		}
+		if id != post.MessageID {
+			post.MessageID = id
+			if err = s.DB.SetSchedulePost(post); err != nil {
+				log.Printf("error saving pinned schedule in [%s]: %s\n", post.ChannelID, err)
+			}
+		}
+	}
+}
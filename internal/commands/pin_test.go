@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+)
+
+func TestRefreshPost(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	h := commandtest.New()
+
+	post := team.SchedulePost{ChannelID: h.ChannelID, Kind: "today"}
+	id, err := postSchedule(h.State, sched, post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := h.Messenger.Sent()
+	if len(sent) != 1 || sent[0].ID != id || !sent[0].Pinned || sent[0].Embed == nil || len(sent[0].Files) != 1 {
+		t.Fatalf("schedule wasn't posted and pinned: %+v", sent)
+	}
+
+	post.MessageID = id
+	if id, err = refreshPost(h.State, sched, post); err != nil || id != post.MessageID {
+		t.Fatalf("expected the post to be edited: %s (%v)", id, err)
+	}
+	if sent = h.Messenger.Sent(); len(sent) != 1 || sent[0].Edits != 1 {
+		t.Errorf("post wasn't edited: %+v", sent)
+	}
+
+	// forgetting every message is like the post being deleted
+	h.Messenger.Reset()
+	if id, err = refreshPost(h.State, sched, post); err != nil || id == post.MessageID {
+		t.Fatalf("expected the post to be recreated: %s (%v)", id, err)
+	}
+	if sent = h.Messenger.Sent(); len(sent) != 1 || sent[0].ID != id || !sent[0].Pinned {
+		t.Errorf("post wasn't recreated: %+v", sent)
+	}
+}
@@ -0,0 +1,299 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/reminders"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	examples := [][2]string{
+		{"!reminders", "Show when reminders are sent and what for."},
+		{"!reminders add interval 45", "Send reminders 45 minutes into the hour before a tracked activity."},
+		{"!reminders add activity Scrim, Tournament", "Send reminders before scrims and tournament matches."},
+		{"!reminders remove interval 45", "Stop sending reminders 45 minutes into the hour."},
+		{"!reminders remove activity Scrim", "Stop sending reminders before scrims."},
+		{"!reminders channel #announcements", "Send reminders in #announcements."},
+		{"!reminders role @Team", "Mention @Team in reminders."},
+		{"!reminders role none", "Don't mention anyone in reminders."},
+	}
+	command.AddCommand("reminders", "Show or change the reminders sent before activities.", examples, Reminders).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+		command.Arg{Name: "values", Type: command.ArgRest, Optional: true},
+	).SetPermission(command.Captain)
+
+	examples = [][2]string{
+		{"!nag", "Show when players who haven't filled in their availability are nagged."},
+		{"!nag hours 12 18", "Nag players at noon and 6pm."},
+		{"!nag days 2", "Only nag players about today and tomorrow."},
+		{"!nag channel #availability", "Ping players in #availability instead of sending a DM."},
+		{"!nag channel none", "Send linked players a DM instead of pinging them."},
+		{"!nag digest #managers", "Tell #managers who's still missing."},
+		{"!nag off", "Stop nagging players."},
+	}
+	command.AddCommand("nag", "Show or change when players are nagged to fill in their availability.", examples, NagPlayers).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+		command.Arg{Name: "values", Type: command.ArgRest, Optional: true},
+	).SetPermission(command.Captain)
+}
+
+// Reminders shows or changes the reminder config for the team in a channel, and reschedules its reminders.
+func Reminders(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	team := s.FindTeam(m.GuildID, m.ChannelID)
+	if team.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+	config, err := s.DB.ReminderConfig(team.ID)
+	if err != nil {
+		return "Error grabbing reminders.", err
+	}
+
+	option := strings.ToLower(args.String("option"))
+	values := args.String("values")
+	var reply string
+	switch option {
+	case "", "list":
+		return formatReminders(config), nil
+	case "add", "remove":
+		fields := strings.SplitN(values, " ", 2)
+		if len(fields) != 2 {
+			return fmt.Sprintf("Give an interval or activity to %s, ex. `!reminders %s interval 45`", option, option), nil
+		}
+		switch strings.ToLower(fields[0]) {
+		case "interval", "intervals":
+			reply, err = changeIntervals(config, option == "add", fields[1])
+		case "activity", "activities":
+			reply, err = changeActivities(s, m, config, option == "add", fields[1])
+		default:
+			return fmt.Sprintf("Can't %s %q; use interval or activity.", option, fields[0]), nil
+		}
+		if err != nil {
+			return reply, nil
+		}
+	case "channel":
+		id, err := command.ParseChannel(values)
+		if err != nil {
+			return err.Error(), nil
+		}
+		canSend, err := sendPermission(s, id)
+		if err != nil {
+			return "Error checking permissions for that channel.", err
+		} else if !canSend {
+			return "I don't have permission to send messages in that channel. :(", nil
+		}
+		config.AnnounceChannel = id
+		reply = "Reminders will be sent in <#" + id + ">."
+	case "role":
+		if strings.EqualFold(values, "none") {
+			config.RoleMention = sql.NullString{}
+			reply = "Reminders won't mention anyone."
+			break
+		}
+		id, err := command.ParseRole(values)
+		if err != nil {
+			return err.Error(), nil
+		}
+		config.RoleMention = sql.NullString{String: "<@&" + id + ">", Valid: true}
+		reply = "Reminders will mention " + config.RoleMention.String + "."
+	default:
+		return fmt.Sprintf("Invalid option for !reminders: %q", option), nil
+	}
+
+	if config.AnnounceChannel == "" {
+		config.AnnounceChannel = m.ChannelID
+	}
+	err = s.DB.SetReminderConfig(config)
+	if err != nil {
+		return "Error saving reminders.", err
+	}
+	err = reminders.AddReminder(reminders.Reminder{State: s, Team: &team, Config: config})
+	if err != nil {
+		return "Saved reminders, but couldn't schedule them. :(", err
+	}
+	log.Printf("updated reminders for team %d: %+v\n", team.ID, *config)
+	return reply, nil
+}
+
+// changeIntervals adds or removes the minutes into the hour reminders are sent at.
+func changeIntervals(config *team.ReminderConfig, add bool, values string) (string, error) {
+	for _, v := range strings.Fields(strings.Replace(values, ",", " ", -1)) {
+		interval, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || interval < 0 || interval > 59 {
+			return fmt.Sprintf("Invalid interval %q; intervals are minutes into the hour, from 0 to 59.", v), fmt.Errorf("invalid interval")
+		}
+		i := indexInt(config.Intervals, interval)
+		if add && i == -1 {
+			config.Intervals = append(config.Intervals, interval)
+		} else if !add && i != -1 {
+			config.Intervals = append(config.Intervals[:i], config.Intervals[i+1:]...)
+		}
+	}
+	if add {
+		return "Added intervals.", nil
+	}
+	return "Removed intervals.", nil
+}
+
+// changeActivities adds or removes the activities reminders are sent for.
+func changeActivities(s *state.State, m *discordgo.MessageCreate, config *team.ReminderConfig, add bool, values string) (string, error) {
+	activities, err := parseArgs(values, command.ScheduleActivities(s, m.GuildID, m.ChannelID))
+	if err != nil {
+		return err.Error(), err
+	}
+	for _, activity := range activities {
+		i := indexString(config.Activities, activity)
+		if add && i == -1 {
+			config.Activities = append(config.Activities, activity)
+		} else if !add && i != -1 {
+			config.Activities = append(config.Activities[:i], config.Activities[i+1:]...)
+		}
+	}
+	if add {
+		return "Added activities.", nil
+	}
+	return "Removed activities.", nil
+}
+
+func indexInt(s []int64, v int64) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexString(s []string, v string) int {
+	for i := range s {
+		if strings.EqualFold(s[i], v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatReminders describes a reminder config.
+func formatReminders(config *team.ReminderConfig) string {
+	if len(config.Intervals) == 0 || len(config.Activities) == 0 {
+		return "No reminders set up; add an interval and an activity with `!reminders add`."
+	}
+	var intervals []string
+	for _, interval := range config.Intervals {
+		intervals = append(intervals, strconv.FormatInt(interval, 10))
+	}
+	msg := fmt.Sprintf("Reminders for %s are sent %s minutes into the hour before each block", strings.Join(config.Activities, ", "), strings.Join(intervals, ", "))
+	if config.AnnounceChannel != "" {
+		msg += " in <#" + config.AnnounceChannel + ">"
+	}
+	if config.RoleMention.Valid {
+		msg += ", mentioning " + config.RoleMention.String
+	}
+	return msg + "."
+}
+
+// NagPlayers shows or changes when players who haven't filled in their availability are nagged, and reschedules the nag.
+func NagPlayers(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	team := s.FindTeam(m.GuildID, m.ChannelID)
+	if team.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+	config, err := s.DB.NagConfig(team.ID)
+	if err != nil {
+		return "Error grabbing nag settings.", err
+	}
+
+	values := args.String("values")
+	var reply string
+	switch option := strings.ToLower(args.String("option")); option {
+	case "", "list":
+		return formatNag(config), nil
+	case "hours":
+		var hours []int64
+		for _, v := range strings.Fields(strings.Replace(values, ",", " ", -1)) {
+			start, _, _, err := schedule.ParseHours(v)
+			if err != nil || start > 23 {
+				return fmt.Sprintf("Invalid hour %q; use hours like 18 or 6pm.", v), nil
+			}
+			if indexInt(hours, int64(start)) == -1 {
+				hours = append(hours, int64(start))
+			}
+		}
+		if len(hours) == 0 {
+			return "Give at least one hour to nag players at, ex. `!nag hours 18`", nil
+		}
+		config.Hours = hours
+		reply = "Updated nag hours."
+	case "days":
+		days, err := strconv.Atoi(values)
+		if err != nil || days < 1 || days > 7 {
+			return "Days has to be a number from 1 to 7.", nil
+		}
+		config.Days = days
+		reply = "Updated nag days."
+	case "channel", "digest":
+		var id string
+		if !strings.EqualFold(values, "none") {
+			id, err = command.ParseChannel(values)
+			if err != nil {
+				return err.Error(), nil
+			}
+			canSend, err := sendPermission(s, id)
+			if err != nil {
+				return "Error checking permissions for that channel.", err
+			} else if !canSend {
+				return "I don't have permission to send messages in that channel. :(", nil
+			}
+		}
+		if option == "channel" {
+			config.Channel = id
+		} else {
+			config.DigestChannel = id
+		}
+		reply = "Updated nag " + option + "."
+	case "off":
+		config.Hours = nil
+		reply = "Players won't be nagged anymore."
+	default:
+		return fmt.Sprintf("Invalid option for !nag: %q", option), nil
+	}
+
+	err = s.DB.SetNagConfig(config)
+	if err != nil {
+		return "Error saving nag settings.", err
+	}
+	err = reminders.AddNag(reminders.Nag{State: s, Team: &team, Config: config})
+	if err != nil {
+		return "Saved nag settings, but couldn't schedule them. :(", err
+	}
+	return reply, nil
+}
+
+// formatNag describes a nag config.
+func formatNag(config *team.NagConfig) string {
+	if len(config.Hours) == 0 {
+		return "Players aren't nagged to fill in their availability; set when with `!nag hours`."
+	}
+	var hours []string
+	for _, hour := range config.Hours {
+		hours = append(hours, strconv.FormatInt(hour, 10)+":00")
+	}
+	msg := fmt.Sprintf("Players who haven't filled in the next %d days are nagged at %s", config.Days, strings.Join(hours, ", "))
+	if config.Channel != "" {
+		msg += " in <#" + config.Channel + ">"
+	} else {
+		msg += " by DM"
+	}
+	if config.DigestChannel != "" {
+		msg += ", with a digest in <#" + config.DigestChannel + ">"
+	}
+	return msg + "."
+}
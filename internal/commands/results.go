@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxStatsLines is how many maps, modes, opponents or weeks !stats shows.
+const maxStatsLines = 8
+
+func init() {
+	examples := [][2]string{
+		{"!result thursday 7 Lijiang Tower 2-1, King's Row 3-2, Hanamura 1-2", "Record the maps played in Thursday's 7 PM block against the team booked for it."},
+		{"!result thursday 7 vs Inked, lijiang 2-1", "Record a map against Inked, for a block without a scrim booked."},
+	}
+	command.AddCommand("result", "Record the map scores of a scrim.", examples, Result).SetArgs(
+		command.Arg{Name: "day", Type: command.ArgDay},
+		command.Arg{Name: "time", Type: command.ArgTimeRange},
+		command.Arg{Name: "maps", Type: command.ArgRest},
+	).SetPermission(command.Captain)
+
+	examples = [][2]string{
+		{"!stats", "Show win rates per map, mode, opponent and week from every recorded scrim."},
+		{"!stats 30", "Only count scrims from the last 30 days."},
+	}
+	command.AddCommand("stats", "Show the team's win rates in scrims.", examples, Stats).SetArgs(
+		command.Arg{Name: "days", Type: command.ArgInt, Optional: true},
+	)
+}
+
+// Result records the scores of the maps played in a block, replacing any recorded for it before. The opponent is the
+// team the block's scrim was booked against, unless another is given first, ex. `vs Inked, Lijiang Tower 2-1`.
+func Result(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	day := sched.Week.Weekday(int(args.Day("day")))
+	block, ok := sched.Week.BlockIndex(day, args.TimeRange("time").Start)
+	if !ok {
+		return "No block at that time on " + sched.Week.Days[day] + ".", nil
+	}
+	played := sched.Week.BlockStart(day, block)
+
+	var opponent string
+	parts := strings.Split(args.String("maps"), ",")
+	if first := strings.TrimSpace(parts[0]); strings.HasPrefix(strings.ToLower(first), "vs ") {
+		opponent = strings.TrimSpace(first[3:])
+		parts = parts[1:]
+	} else {
+		scrims, err := s.DB.Scrims(t.ID, played)
+		if err != nil {
+			return "Error grabbing scrims.", err
+		}
+		for _, scrim := range scrims {
+			if scrim.Active() && !scrim.Start.After(played) {
+				opponent = scrim.Opponent
+				break
+			}
+		}
+		if opponent == "" {
+			return "No scrim booked then; give the opponent first, ex. `!result thursday 7 vs Inked, Lijiang Tower 2-1`", nil
+		}
+	}
+
+	var results []schedule.MapResult
+	for _, part := range parts {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			return fmt.Sprintf("Invalid map %q; give a map and a score, ex. Lijiang Tower 2-1", strings.TrimSpace(part)), nil
+		}
+		score, opponentScore, err := schedule.ParseScore(fields[len(fields)-1])
+		if err != nil {
+			return fmt.Sprintf("Invalid score for %s: %s", strings.Join(fields[:len(fields)-1], " "), err), nil
+		}
+		name, mode := schedule.FindMap(strings.Join(fields[:len(fields)-1], " "))
+		results = append(results, schedule.MapResult{Opponent: opponent, Map: name, Mode: mode, Score: score, OpponentScore: opponentScore})
+	}
+	if len(results) == 0 {
+		return "Give the maps played and their scores, ex. `!result thursday 7 Lijiang Tower 2-1, King's Row 3-2`", nil
+	}
+
+	err := s.DB.SetResults(t.ID, played, results)
+	if err != nil {
+		return "Error saving results.", err
+	}
+	log.Printf("team %d recorded %d maps against %q\n", t.ID, len(results), opponent)
+
+	maps := make([]string, len(results))
+	for i, r := range results {
+		maps[i] = fmt.Sprintf("%s %d-%d", r.Map, r.Score, r.OpponentScore)
+	}
+	record := schedule.NewStats(results, time.UTC).Overall
+	return fmt.Sprintf("Recorded %s vs %s on %s %s: %s", record, opponent, sched.Week.WeekdayOf(day), sched.Week.BlockHours(day, block), strings.Join(maps, ", ")), nil
+}
+
+// Stats shows a team's records in scrims on each map and mode, against each opponent and over the last few weeks.
+func Stats(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	var since time.Time
+	title := "Scrim Stats"
+	if args.Has("days") {
+		if args.Int("days") < 1 {
+			return "Give a number of days of at least 1.", nil
+		}
+		since = sched.Week.Now().AddDate(0, 0, -args.Int("days"))
+		title = fmt.Sprintf("Scrim Stats for the Last %d Days", args.Int("days"))
+	}
+	results, err := s.DB.Results(t.ID, since)
+	if err != nil {
+		return "Error grabbing results.", err
+	}
+	if len(results) == 0 {
+		return "No results recorded; record a scrim's maps with `!result`.", nil
+	}
+
+	stats := schedule.NewStats(results, sched.Week.Now().Location())
+	embed := baseEmbed(title, sched.Link(), sched.Week.Zone())
+	embed.Description = fmt.Sprintf("**Overall:** %s over %d maps", stats.Overall, stats.Overall.Played())
+	for _, field := range []struct {
+		name    string
+		records map[string]schedule.Record
+	}{{"Maps", stats.Maps}, {"Modes", stats.Modes}, {"Opponents", stats.Opponents}} {
+		if len(field.records) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: field.name, Value: formatRecords(field.records)})
+		}
+	}
+
+	weeks := stats.Weeks
+	if len(weeks) > maxStatsLines {
+		weeks = weeks[len(weeks)-maxStatsLines:]
+	}
+	lines := make([]string, len(weeks))
+	for i, w := range weeks {
+		lines[i] = fmt.Sprintf("Week of %s: %s", w.Start.Format("1/2"), w.Record)
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Over Time", Value: strings.Join(lines, "\n")})
+
+	_, err = s.Messenger.ChannelMessageSendEmbed(m.ChannelID, embed)
+	if err != nil {
+		return "Error sending stats.", err
+	}
+	return "", nil
+}
+
+// formatRecords lists the most played records, one per line.
+func formatRecords(records map[string]schedule.Record) string {
+	keys := schedule.Ranked(records)
+	if len(keys) > maxStatsLines {
+		keys = keys[:maxStatsLines]
+	}
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("%s: %s", k, records[k])
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+)
+
+func TestResults(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	nextWeek(sched)
+	h := commandtest.New()
+	tm := h.AddTeam("Ascension", sched)
+
+	if sent := h.Send("!result thursday 7 lijiang 2-1"); len(sent) != 1 || !strings.Contains(sent[0].Content, "No scrim booked") {
+		t.Fatalf("expected to be asked for the opponent, got %+v", sent)
+	}
+	h.Send("!scrim book thursday 7-9 Inked")
+	sent := h.Send("!result thursday 8 Lijiang 2-1, kings row 3-2, Hanamura 1-2")
+	if len(sent) != 1 || sent[0].Content != "Recorded 2-1 (67%) vs Inked on Thursday 8-9pm: Lijiang Tower 2-1, King's Row 3-2, Hanamura 1-2" {
+		t.Fatalf("results weren't recorded: %+v", sent)
+	}
+	if sent = h.Send("!result thursday 8 lijiang 2"); len(sent) != 1 || !strings.Contains(sent[0].Content, "Invalid score") {
+		t.Errorf("expected an invalid score to be rejected, got %+v", sent)
+	}
+	// recording a block again replaces it
+	h.Send("!result thursday 8 Lijiang 2-1, kings row 3-2")
+	h.Send("!result friday 5 vs Kungarna, Castillo 0-2")
+	results, err := h.Store.Results(tm.ID, sched.Week.BlockStart(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[2].Opponent != "Kungarna" || results[2].Mode != "" {
+		t.Fatalf("wrong results: %+v", results)
+	}
+
+	sent = h.Send("!stats")
+	if len(sent) != 1 || sent[0].Embed == nil {
+		t.Fatalf("expected stats in an embed, got %+v", sent)
+	}
+	if !strings.Contains(sent[0].Embed.Description, "2-1 (67%) over 3 maps") {
+		t.Errorf("wrong overall record: %q", sent[0].Embed.Description)
+	}
+	fields := make(map[string]string)
+	for _, f := range sent[0].Embed.Fields {
+		fields[f.Name] = f.Value
+	}
+	if !strings.Contains(fields["Opponents"], "Inked: 2-0 (100%)") || !strings.Contains(fields["Modes"], "Hybrid: 1-0 (100%)") {
+		t.Errorf("wrong records: %+v", fields)
+	}
+	if !strings.HasPrefix(fields["Over Time"], "Week of ") {
+		t.Errorf("expected a record for the week: %q", fields["Over Time"])
+	}
+}
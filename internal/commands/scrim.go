@@ -0,0 +1,220 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+const bookUsage = "Usage: `!scrim book <day> <time range> <opponent> [contact=...] [region=...] [maps=...]`"
+
+func init() {
+	examples := [][2]string{
+		{"!scrim", "List the scrims booked from now on."},
+		{`!scrim book thursday 7-9 "Team Inked" contact=Taub#1234 region=NA maps="Control, Hybrid"`, "Propose a scrim against Team Inked from 7 to 9 on Thursday."},
+		{"!scrim confirm 3", "Confirm scrim #3 once the other team agrees to it."},
+		{"!scrim cancel 3", "Cancel scrim #3, taking it off the sheet."},
+	}
+	command.AddCommand("scrim", "Book, confirm and cancel scrims.", examples, Scrim).SetArgs(
+		command.Arg{Name: "option", Type: command.ArgString, Optional: true},
+		command.Arg{Name: "values", Type: command.ArgRest, Optional: true},
+	).SetPermission(command.Captain)
+}
+
+// Scrim lists, books, confirms or cancels the scrims of the team in a channel. Scrims are written in the notes of their
+// blocks on the sheet, so the sheet shows who each block is booked against.
+func Scrim(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+
+	switch option := strings.ToLower(args.String("option")); option {
+	case "", "list":
+		return listScrims(s, t, sched)
+	case "book":
+		// the raw tokens keep quoted opponents and details together
+		return bookScrim(s, t, sched, args.Raw[1:])
+	case "confirm", "cancel":
+		id, err := strconv.Atoi(strings.TrimPrefix(args.String("values"), "#"))
+		if err != nil {
+			return fmt.Sprintf("Give the number of a scrim from `!scrim`, ex. `!scrim %s 3`", option), nil
+		}
+		status := schedule.ScrimConfirmed
+		if option == "cancel" {
+			status = schedule.ScrimCancelled
+		}
+		return setScrimStatus(s, t, sched, id, status)
+	default:
+		return fmt.Sprintf("Invalid option for !scrim: %q; use book, confirm, cancel or nothing.", option), nil
+	}
+}
+
+// listScrims lists a team's scrims that haven't ended or been cancelled.
+func listScrims(s *state.State, t team.Team, sched *schedule.Schedule) (string, error) {
+	scrims, err := s.DB.Scrims(t.ID, sched.Week.Now())
+	if err != nil {
+		return "Error grabbing scrims.", err
+	}
+	lines := []string{"**Scrims:**"}
+	for _, scrim := range scrims {
+		if scrim.Active() {
+			lines = append(lines, formatScrim(&sched.Week, scrim))
+		}
+	}
+	if len(lines) == 1 {
+		return "No scrims booked; use `!scrim book` to book one.", nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// formatScrim describes a scrim on one line, with times in the week's timezone.
+func formatScrim(week *schedule.Week, scrim schedule.Scrim) string {
+	return fmt.Sprintf("#%d %s: %s", scrim.ID, formatWindow(week, schedule.Window{Start: scrim.Start, End: scrim.End}), scrim.Note())
+}
+
+// formatWindow formats a stretch of time in a week's timezone, ex. "Thursday 10/11 7pm-9pm".
+func formatWindow(week *schedule.Week, w schedule.Window) string {
+	loc := week.Now().Location()
+	start, end := w.Start.In(loc), w.End.In(loc)
+	return fmt.Sprintf("%s %s-%s", start.Format("Monday 1/2"), start.Format("3pm"), end.Format("3pm"))
+}
+
+// bookScrim proposes a scrim from tokens like `thursday 7-9 Inked contact=Taub#1234`, and writes it on the sheet.
+func bookScrim(s *state.State, t team.Team, sched *schedule.Schedule, tokens []string) (string, error) {
+	if len(tokens) < 3 {
+		return bookUsage, nil
+	}
+	weekday, err := command.ParseDay(tokens[0])
+	if err != nil {
+		return fmt.Sprintf("Invalid day %q.", tokens[0]), nil
+	}
+	hours, err := command.ParseTimeRange(tokens[1])
+	if err != nil {
+		return fmt.Sprintf("Invalid time range %q.", tokens[1]), nil
+	}
+	day := sched.Week.Weekday(int(weekday))
+	start, end, err := sched.Week.BlockRange(day, hours.Start, hours.End)
+	if err != nil {
+		return "Error parsing input: " + err.Error(), nil
+	}
+
+	scrim := schedule.Scrim{
+		Team:     t.ID,
+		Start:    sched.Week.BlockStart(day, start),
+		End:      sched.Week.BlockEnd(day, end-1),
+		Opponent: tokens[2],
+		Status:   schedule.ScrimProposed,
+	}
+	for _, detail := range tokens[3:] {
+		kv := strings.SplitN(detail, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Sprintf("Invalid detail %q; use contact=, region= or maps=.\n%s", detail, bookUsage), nil
+		}
+		switch strings.ToLower(kv[0]) {
+		case "contact":
+			scrim.Contact = kv[1]
+		case "region":
+			scrim.Region = kv[1]
+		case "map", "maps", "mode", "modes":
+			scrim.Maps = kv[1]
+		default:
+			return fmt.Sprintf("Invalid detail %q; use contact=, region= or maps=.\n%s", detail, bookUsage), nil
+		}
+	}
+	if scrim.Start.Before(sched.Week.Now()) {
+		return "That block has already started.", nil
+	}
+
+	booked, err := s.DB.Scrims(t.ID, scrim.Start)
+	if err != nil {
+		return "Error grabbing scrims.", err
+	}
+	for _, other := range booked {
+		if other.Active() && other.Overlaps(scrim) {
+			return fmt.Sprintf("Already booked then: %s", formatScrim(&sched.Week, other)), nil
+		}
+	}
+	for block := start; block < end; block++ {
+		if note := sched.Week.Container[day][block].Note; note != "" {
+			return fmt.Sprintf("The %s block already has a note: %q", sched.Week.BlockHours(day, block), note), nil
+		}
+	}
+
+	scrim.ID, err = s.DB.AddScrim(scrim)
+	if err != nil {
+		return "Error booking scrim.", err
+	}
+	err = mirrorScrim(sched, scrim, "", scrimActivity(sched))
+	if err != nil {
+		return "Booked the scrim, but couldn't write it on the sheet.", err
+	}
+	log.Printf("team %d booked scrim #%d vs %q\n", t.ID, scrim.ID, scrim.Opponent)
+	return fmt.Sprintf("Proposed %s\nUse `!scrim confirm %d` once they agree to it.", formatScrim(&sched.Week, scrim), scrim.ID), nil
+}
+
+// setScrimStatus confirms or cancels one of a team's scrims, and updates it on the sheet.
+func setScrimStatus(s *state.State, t team.Team, sched *schedule.Schedule, id int, status schedule.ScrimStatus) (string, error) {
+	scrim, err := s.DB.Scrim(t.ID, id)
+	if err == sql.ErrNoRows {
+		return fmt.Sprintf("No scrim #%d.", id), nil
+	} else if err != nil {
+		return "Error grabbing scrim.", err
+	}
+	if !scrim.Active() {
+		return fmt.Sprintf("Scrim #%d was already cancelled.", id), nil
+	} else if scrim.Status == status {
+		return fmt.Sprintf("Scrim #%d is already %s.", id, status), nil
+	}
+
+	err = s.DB.SetScrimStatus(t.ID, id, status)
+	if err != nil {
+		return "Error updating scrim.", err
+	}
+	previous := scrim.Note()
+	scrim.Status = status
+	err = mirrorScrim(sched, scrim, previous, "")
+	if err != nil {
+		return fmt.Sprintf("Scrim #%d is %s, but couldn't update it on the sheet.", id, status), err
+	}
+	log.Printf("team %d %s scrim #%d\n", t.ID, status, id)
+	return fmt.Sprintf("Scrim #%d vs %s is %s.", id, scrim.Opponent, status), nil
+}
+
+// mirrorScrim writes a scrim in the notes of its blocks in place of the note it had before, and syncs the sheet.
+// Notes someone changed since are left alone, and scrims on other weeks aren't on the sheet. If activity isn't empty,
+// the blocks are set to it too.
+func mirrorScrim(sched *schedule.Schedule, scrim schedule.Scrim, previous, activity string) error {
+	day, start, end, ok := scrim.Blocks(&sched.Week)
+	if !ok {
+		return nil
+	}
+	for _, cell := range sched.Week.Container[day][start:end] {
+		if activity != "" {
+			updateCell(sched, schedule.WeekGrid, cell, activity)
+		}
+		if cell.Note == "" || cell.Note == previous {
+			updateNote(sched, schedule.WeekGrid, cell, scrim.Note())
+		}
+	}
+	return sched.Sync()
+}
+
+// scrimActivity returns how the schedule writes scrims, or an empty string if it doesn't have them.
+func scrimActivity(sched *schedule.Schedule) string {
+	for _, activity := range sched.ValidActivities {
+		if strings.EqualFold(activity, "scrim") {
+			return activity
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+)
+
+func TestScrimBooking(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+	nextWeek(sched)
+	h := commandtest.New()
+	tm := h.AddTeam("Ascension", sched)
+
+	sent := h.Send(`!scrim book thursday 7-9 "Team Inked" contact=Taub#1234 maps="Control, Hybrid"`)
+	if len(sent) != 1 || !strings.Contains(sent[0].Content, "Proposed #1") {
+		t.Fatalf("scrim wasn't booked: %+v", sent)
+	}
+	note := "vs Team Inked (proposed) | Control, Hybrid | contact Taub#1234"
+	for _, block := range []int{3, 4} {
+		if got := sched.Week.Container[3][block].Note; got != note {
+			t.Errorf("wrong note on block %d: %q != %q", block, got, note)
+		}
+	}
+	if sent = h.Send("!scrim book thursday 8-10 Inked"); len(sent) != 1 || !strings.Contains(sent[0].Content, "Already booked") {
+		t.Errorf("expected an overlapping scrim to be rejected, got %+v", sent)
+	}
+
+	if sent = h.Send("!scrim confirm #1"); len(sent) != 1 || !strings.Contains(sent[0].Content, "is confirmed") {
+		t.Fatalf("scrim wasn't confirmed: %+v", sent)
+	}
+	if got := sched.Week.Container[3][3].Note; !strings.HasPrefix(got, "vs Team Inked (confirmed)") {
+		t.Errorf("note wasn't updated after confirming: %q", got)
+	}
+
+	if sent = h.Send("!scrim cancel 1"); len(sent) != 1 || !strings.Contains(sent[0].Content, "is cancelled") {
+		t.Fatalf("scrim wasn't cancelled: %+v", sent)
+	}
+	if got := sched.Week.Container[3][3].Note; got != "" {
+		t.Errorf("note wasn't cleared after cancelling: %q", got)
+	}
+	scrim, err := h.Store.Scrim(tm.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scrim.Status != schedule.ScrimCancelled {
+		t.Errorf("wrong status after cancelling: %q", scrim.Status)
+	}
+	if !sched.Week.Open(3, 3) {
+		t.Error("block isn't open again after cancelling")
+	}
+	if sent = h.Send("!scrim cancel 1"); len(sent) != 1 || !strings.Contains(sent[0].Content, "already cancelled") {
+		t.Errorf("expected the scrim to already be cancelled, got %+v", sent)
+	}
+	if sent = h.Send("!scrim confirm 2"); len(sent) != 1 || sent[0].Content != "No scrim #2." {
+		t.Errorf("expected no scrim #2, got %+v", sent)
+	}
+}
+
+// nextWeek moves a schedule's week to next week, so none of its blocks have started.
+func nextWeek(sched *schedule.Schedule) {
+	monday := time.Now().UTC().AddDate(0, 0, 7)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, -1)
+	}
+	for i := range sched.Week.Days {
+		sched.Week.Days[i] = monday.AddDate(0, 0, i).Format("Monday, 1/2")
+	}
+}
@@ -3,8 +3,6 @@ package commands
 import (
 	"database/sql"
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/bigheadgeorge/spreadsheet"
@@ -12,110 +10,152 @@ import (
 	"github.com/bigheadgeorge/thonky2/pkg/schedule"
 	"github.com/bigheadgeorge/thonky2/pkg/state"
 	"github.com/bwmarrin/discordgo"
-	"github.com/jmoiron/sqlx/types"
 )
 
 func init() {
+	setArgs := []command.Arg{
+		{Name: "player", Type: command.ArgPlayer, Optional: true},
+		{Name: "day", Type: command.ArgDay},
+		{Name: "time range", Type: command.ArgTimeRange, Optional: true},
+		{Name: "values", Type: command.ArgRest, Complete: completeValues},
+	}
+
+	noteArgs := append([]command.Arg{}, setArgs...)
+	noteArgs[3] = command.Arg{Name: "note", Type: command.ArgRest}
+
 	examples := [][2]string{
 		{"!set <player name> <day name> <time range> <availability>", "Update player availability."},
 		{"!set <day name> <time range> <activity / activities>", "Update schedule."},
+		{"!set monday 4-6 scrim", "Set the 4-6 block on Monday to Scrim"},
 		{"To give multiple responses / activities, use commas:", "!set tydra monday 4-6 no, yes"},
 		{"Give one response over a range to set it all to that one response:", "!set monday 4-10 free"},
 	}
-	command.AddCommand("set", "Update information on the configured spreadsheet.", examples, Set)
+	command.AddCommand("set", "Update information on the configured spreadsheet.", examples, Set).SetArgs(setArgs...).SetPermission(command.Player)
 
 	examples = [][2]string{
 		{"!reset", "Load a given default week schedule (use !save to do that)"},
+		{"!reset 10/08", "Restore the week schedule and availability to the last saved version of the week of 10/08"},
+		{"!reset #12", "Restore version #12 from !history; availability is only restored from the same week"},
 	}
-	command.AddCommand("reset", "Reset the week schedule on a sheet to default", examples, Reset)
+	command.AddCommand("reset", "Reset the week schedule on a sheet to default, or to a version from its history", examples, Reset).SetArgs(
+		command.Arg{Name: "version", Type: command.ArgString, Optional: true},
+	).SetPermission(command.Captain)
 
 	examples = [][2]string{
-		{"!set monday 4-6 scrim", "Set the 4-6 block on Monday to Scrim"},
+		{"!set_note monday 4-6 Inked", "Block out scrims 4-6 for Inked"},
 	}
-	command.AddCommand("set", "Update cells on the spreadsheet.", examples, Set)
+	command.AddCommand("set_note", "Add notes on the week schedule", examples, SetNote).SetArgs(noteArgs...).SetPermission(command.Player)
+}
 
-	examples = [][2]string{
-		{"!set_note monday 4-6 Inked", "Block out scrims 4-6 for Inked"},
+// completeValues suggests availability responses when a player is given to !set, and activities otherwise.
+func completeValues(s *state.State, guildID, channelID string, typed map[string]string) []string {
+	if typed["player"] != "" {
+		return []string{"Yes", "Maybe", "No"}
 	}
-	command.AddCommand("set_note", "Add notes on the week schedule", examples, SetNote)
+	return command.ScheduleActivities(s, guildID, channelID)
 }
 
-type updater func(*spreadsheet.Sheet, *spreadsheet.Cell, string)
+type updater func(sched *schedule.Schedule, grid string, cell *spreadsheet.Cell, val string)
+
+// Edit is a change to blocks on a schedule, like the ones !set and !set_note make.
+type Edit struct {
+	// Player is whose availability is changed, or empty to change the week schedule.
+	Player string
+	// Day is the day on the sheet the blocks are on.
+	Day int
+	// Hours are the hours the blocks cover, or nil for every block on the day.
+	Hours *command.TimeRange
+	// Values are comma separated values for each block, or one value for all of them.
+	Values string
+	// Note is whether Values are notes instead of activities or availability.
+	Note bool
+}
 
-// updateSheet updates cells, notes, whatever on the spreadsheet by parsing a whatever spaghetti people shove in as arguments
-func updateSheet(s *state.State, m *discordgo.MessageCreate, args []string, validWeekArgs, validPlayerArgs []string, updater updater) (string, error) {
-	sched := s.FindSchedule(m.GuildID, m.ChannelID)
-	if sched == nil {
-		return "", nil
-	}
+// InvalidEditError is returned when an edit doesn't fit a schedule, ex. it has an invalid activity.
+type InvalidEditError struct {
+	Reason string
+}
 
-	if len(args) >= 3 {
-		day := sched.Week.DayInt(args[1])
-		if day != -1 {
-			// update w/ day
-			return updateRange("Weekly Schedule", sched, sched.Week.Container[day], 1, args[1:], validWeekArgs, &sched.Week, updater)
-		}
+func (e *InvalidEditError) Error() string {
+	return e.Reason
+}
 
-		var player *schedule.Player
-		playerName := strings.ToLower(args[1])
+// EditSchedule makes an edit to a schedule and syncs it to the schedule's source.
+// Activities and availability are checked against the schedule's valid values; notes can be anything.
+func EditSchedule(sched *schedule.Schedule, e Edit) error {
+	if e.Day < 0 || e.Day >= len(sched.Week.Container) {
+		return &InvalidEditError{fmt.Sprintf("Invalid day %d", e.Day)}
+	}
+	grid, cells, validArgs := schedule.WeekGrid, sched.Week.Container[e.Day], sched.ValidActivities
+	if e.Player != "" {
+		var found bool
 		for _, p := range sched.Players {
-			if playerName == strings.ToLower(p.Name) {
-				player = &p
+			if p.Name == e.Player {
+				grid, cells, validArgs, found = p.Name, p.Container[e.Day], []string{"Yes", "Maybe", "No"}, true
 				break
 			}
 		}
-
-		if player != nil {
-			day = sched.Week.DayInt(args[2])
-			if day != -1 {
-				// update w/ player
-				return updateRange(player.Name, sched, player.Container[day], 2, args[2:], validPlayerArgs, &sched.Week, updater)
-			}
-
-			return fmt.Sprintf("Invalid day %q", args[2]), nil
+		if !found {
+			return &InvalidEditError{fmt.Sprintf("Invalid player %q", e.Player)}
 		}
-
-		return fmt.Sprintf("Invalid day / player %q", args[1]), nil
+	}
+	updater := updateCell
+	if e.Note {
+		updater, validArgs = updateNote, nil
 	}
 
-	return "weird amount of args", nil
-}
-
-func updateRange(title string, sched *schedule.Schedule, cells []*spreadsheet.Cell, argStartIndex int, args, validArgs []string, week *schedule.Week, updater updater) (string, error) {
-	sheet, err := sched.SheetByTitle(title)
-	if err != nil {
-		return err.Error(), err
+	if e.Hours != nil {
+		start, end, err := sched.Week.BlockRange(e.Day, e.Hours.Start, e.Hours.End)
+		if err != nil {
+			return &InvalidEditError{fmt.Sprintf("Error parsing input: %s", err.Error())}
+		}
+		cells = cells[start:end]
 	}
-	updateCells, err := cellsToUpdate(sheet, cells[:], argStartIndex, week.StartTime, week.BlockLength, args)
+	parsed, err := parseArgs(e.Values, validArgs)
 	if err != nil {
-		return fmt.Sprintf("Error parsing input: %s", err.Error()), err
+		return &InvalidEditError{fmt.Sprintf("Error parsing input: %s", err.Error())}
+	} else if len(parsed) != 1 && len(cells) != len(parsed) {
+		return &InvalidEditError{fmt.Sprintf("Input mismatch; cell count != parsed count (%d cells != %d parsed arguments)", len(cells), len(parsed))}
 	}
+	update(sched, grid, cells, parsed, updater)
+	return sched.Sync()
+}
 
-	parsed, err := parseArgs(args, validArgs)
-	if err != nil {
-		return fmt.Sprintf("Error parsing input: %s", err.Error()), err
-	} else if len(cells) != len(parsed) {
-		return fmt.Sprintf("Input mismatch; cell count != parsed count (%d cells != %d parsed arguments)", len(cells), len(parsed)), nil
+// updateSheet updates cells or notes on the week schedule or a player's availability using the parsed arguments of !set and !set_note.
+func updateSheet(s *state.State, m *discordgo.MessageCreate, args command.Args, note bool) (string, error) {
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
 	}
-	update(sheet, updateCells, parsed, updater)
 
-	err = sched.SyncSheet(sheet)
-	if err != nil {
+	e := Edit{Player: args.Player("player"), Day: sched.Week.Weekday(int(args.Day("day"))), Values: args.String("values"), Note: note}
+	if note {
+		e.Values = args.String("note")
+	}
+	if args.Has("time range") {
+		r := args.TimeRange("time range")
+		e.Hours = &r
+	}
+	err := EditSchedule(sched, e)
+	if invalid, ok := err.(*InvalidEditError); ok {
+		return invalid.Reason, nil
+	} else if err != nil {
 		return err.Error(), err
 	}
-
 	return "Updated schedule.", nil
 }
 
-// Reset loads the default week schedule for a sheet
-func Reset(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
+// Reset loads the default week schedule for a sheet, or restores a version of the schedule from its history.
+func Reset(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
 	sched := s.FindSchedule(m.GuildID, m.ChannelID)
 	if sched == nil {
 		return "", nil
 	}
+	if args.Has("version") {
+		return restoreSnapshot(s, sched, args.String("version"))
+	}
 
-	var j types.JSONText
-	err := s.DB.Get(&j, "SELECT default_week FROM sheet_info WHERE id = $1")
+	w, err := s.DB.DefaultWeek(sched.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "No default week schedule for this sheet", nil
@@ -124,26 +164,15 @@ func Reset(s *state.State, m *discordgo.MessageCreate, args []string) (string, e
 		}
 	}
 
-	sheet, err := sched.SheetByTitle("Weekly Schedule")
-	if err != nil {
-		return "Error grabbing week schedule", err
-	}
-
-	var w schedule.Week
-	err = j.Unmarshal(&w)
-	if err != nil {
-		return "Error parsing default week schedule, something stupid happened", err
-	}
-
 	activities := w.Values()
 	for i, c := range sched.Week.Container {
-		update(sheet, c[:], activities[i][:], updateCell)
+		update(sched, schedule.WeekGrid, c[:], activities[i][:], updateCell)
 	}
-	err = sched.SyncSheet(sheet)
+	err = sched.Sync()
 	if err != nil {
 		return "Error synchronizing sheets", err
 	}
-	err = s.DB.ExecJSON(fmt.Sprintf("UPDATE cache SET week = $1 WHERE id = '%s'", sched.ID), sched.Week)
+	err = s.DB.CacheSchedule(sched)
 	if err != nil {
 		return "Error caching new default week", err
 	}
@@ -151,106 +180,70 @@ func Reset(s *state.State, m *discordgo.MessageCreate, args []string) (string, e
 	return "Loaded default week schedule. :)", nil
 }
 
-// Set updates a cell on a sheet.
-func Set(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
-	if sched := s.FindSchedule(m.GuildID, m.ChannelID); sched != nil {
-		return updateSheet(s, m, args, sched.ValidActivities, []string{"Yes", "Maybe", "No"}, updateCell)
+// restoreSnapshot restores a schedule to a version from its history.
+func restoreSnapshot(s *state.State, sched *schedule.Schedule, version string) (string, error) {
+	snap, msg, err := findSnapshot(s, sched, version)
+	if snap == nil {
+		return msg, err
+	}
+	// availability from another week doesn't mean anything for this one, so only its activities are used
+	sameWeek := schedule.SameDate(snap.Date, sched.Week.Date)
+	n := sched.Restore(snap, sameWeek)
+	err = sched.Sync()
+	if err != nil {
+		return "Error synchronizing sheets", err
+	}
+	err = s.DB.CacheSchedule(sched)
+	if err != nil {
+		return "Error caching the restored schedule", err
 	}
-	return "", nil
+	if !sameWeek {
+		return fmt.Sprintf("Restored the week schedule from version #%d (week of %s), changing %d cells. :)", snap.ID, snap.Date, n), nil
+	}
+	return fmt.Sprintf("Restored version #%d, changing %d cells. :)", snap.ID, n), nil
+}
+
+// Set updates a cell on a sheet.
+func Set(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	return updateSheet(s, m, args, false)
 }
 
 // SetNote updates a note on a sheet.
-func SetNote(s *state.State, m *discordgo.MessageCreate, args []string) (string, error) {
-	return updateSheet(s, m, args, []string{}, []string{}, updateNote)
+func SetNote(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	return updateSheet(s, m, args, true)
 }
 
-func update(sheet *spreadsheet.Sheet, cells []*spreadsheet.Cell, newValues []string, updater func(*spreadsheet.Sheet, *spreadsheet.Cell, string)) {
+func update(sched *schedule.Schedule, grid string, cells []*spreadsheet.Cell, newValues []string, updater updater) {
 	if len(newValues) > 1 {
 		for i, cell := range cells {
-			updater(sheet, cell, newValues[i])
+			updater(sched, grid, cell, newValues[i])
 		}
 	} else {
 		for _, cell := range cells {
-			updater(sheet, cell, newValues[0])
+			updater(sched, grid, cell, newValues[0])
 		}
 	}
 }
 
-func updateCell(sheet *spreadsheet.Sheet, cell *spreadsheet.Cell, val string) {
+func updateCell(sched *schedule.Schedule, grid string, cell *spreadsheet.Cell, val string) {
 	if cell.Value != val {
-		sheet.Update(int(cell.Row), int(cell.Column), val)
-		cell.Value = val
+		sched.SetValue(grid, cell, val)
 	}
 }
 
-func updateNote(sheet *spreadsheet.Sheet, cell *spreadsheet.Cell, val string) {
-	if cell.Note != val {
-		lowerVal := strings.ToLower(val)
-		if lowerVal == "empty" || lowerVal == "none" || lowerVal == "blank" {
-			val = ""
-		}
-		sheet.UpdateNote(int(cell.Row), int(cell.Column), val)
-		cell.Note = val
+func updateNote(sched *schedule.Schedule, grid string, cell *spreadsheet.Cell, val string) {
+	lowerVal := strings.ToLower(val)
+	if lowerVal == "empty" || lowerVal == "none" || lowerVal == "blank" {
+		val = ""
 	}
-}
-
-func cellsToUpdate(sheet *spreadsheet.Sheet, cells []*spreadsheet.Cell, argStartIndex, startTime, blockLength int, args []string) ([]*spreadsheet.Cell, error) {
-	if match, _ := regexp.MatchString(`\d{1,2}-\d{1,2}`, args[0]); match {
-		rangeStart, rangeEnd, err := parseTimeRange(args[0], startTime, blockLength)
-		if err != nil {
-			return cells, err
-		}
-		if rangeStart == rangeEnd {
-			cells = cells[rangeStart : rangeStart+1]
-		} else {
-			cells = cells[rangeStart:rangeEnd]
-		}
-
-		args = args[1:]
-		argStartIndex++
-	} else if i, err := strconv.Atoi(args[0]); err == nil {
-		if i < startTime {
-			return cells, fmt.Errorf("Invalid time: %d < %d", i, startTime)
-		}
-		cells = cells[i-startTime : i-startTime+1]
-	}
-	return cells[argStartIndex:], nil
-}
-
-func parseTimeRange(timeStr string, startTime, blockLength int) (int, int, error) {
-	timeStrings := strings.Split(timeStr, "-")
-	var timeRange [2]int
-	for i, timeStr := range timeStrings {
-		time, err := strconv.Atoi(timeStr)
-		if err != nil {
-			return -1, -1, err
-		}
-		timeRange[i] = time
-	}
-	if timeRange[0] < startTime {
-		return -1, -1, fmt.Errorf("Invalid start time")
-	}
-	if timeRange[0] > timeRange[1] {
-		return -1, -1, fmt.Errorf("Invalid time range: first time > second time")
-	}
-	if timeRange[0]-startTime != 0 && (timeRange[0]-startTime)/blockLength != 0 {
-		return -1, -1, fmt.Errorf("range does not conform to block length")
+	if cell.Note != val {
+		sched.SetNote(grid, cell, val)
 	}
-	rangeStart := (timeRange[0] - startTime) / blockLength
-	rangeEnd := rangeStart + ((timeRange[1] - timeRange[0]) / blockLength)
-	return rangeStart, rangeEnd, nil
 }
 
-// parseArgs takes a list of unformatted arguments and tries to match them with a given list of valid arguments.
-func parseArgs(args []string, validArgs []string) ([]string, error) {
-	var argString string
-	if len(args) > 1 {
-		argString = strings.Join(args, " ")
-	} else {
-		argString = args[0]
-	}
+// parseArgs takes a comma separated list of values and tries to match them with a given list of valid arguments.
+func parseArgs(argString string, validArgs []string) ([]string, error) {
 	csv := strings.Split(argString, ", ")
-
 	if len(validArgs) == 0 {
 		return csv, nil
 	}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+)
+
+func TestEditSchedule(t *testing.T) {
+	sched, _, cleanup := fileSchedule(t)
+	defer cleanup()
+
+	err := EditSchedule(sched, Edit{Day: 0, Hours: &command.TimeRange{Start: 16, End: 18}, Values: "scrim, free"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sched.Week.ActivitiesOn(0)[:2]; got[0] != "Scrim" || got[1] != "Free" {
+		t.Errorf("activities weren't set: %v", got)
+	}
+
+	err = EditSchedule(sched, Edit{Player: "Taub", Day: 0, Values: "No"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range sched.Players[0].AvailabilityOn(0) {
+		if v != "No" {
+			t.Errorf("block %d wasn't set to No: %q", i, v)
+		}
+	}
+
+	err = EditSchedule(sched, Edit{Day: 0, Hours: &command.TimeRange{Start: 16, End: 16}, Values: "vs Inked", Note: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note := sched.Week.Container[0][0].Note; note != "vs Inked" {
+		t.Errorf("note wasn't set: %q", note)
+	}
+
+	for _, e := range []Edit{
+		{Player: "Nobody", Day: 0, Values: "Yes"},
+		{Day: 0, Values: "Bowling"},
+		{Day: 0, Hours: &command.TimeRange{Start: 16, End: 18}, Values: "Free, Free, Free"},
+	} {
+		if _, ok := EditSchedule(sched, e).(*InvalidEditError); !ok {
+			t.Errorf("expected %+v to be invalid", e)
+		}
+	}
+}
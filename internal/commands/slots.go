@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxSlots is how many blocks !find_slots shows.
+const maxSlots = 5
+
+func init() {
+	examples := [][2]string{
+		{"!find_slots", "Show the open blocks this week where the most of the team's lineup can play."},
+		{"!find_slots tanks=2 dps=2 supports=2", "Find blocks for 2 tanks, 2 DPS and 2 supports instead of the team's lineup."},
+	}
+	command.AddCommand("find_slots", "Find the best open blocks to book scrims in.", examples, FindSlots).SetArgs(
+		command.Arg{Name: "lineup", Type: command.ArgRest, Optional: true},
+	)
+
+	examples = [][2]string{
+		{"!set_lineup", "Show how many players of each role the team plays with."},
+		{"!set_lineup tanks=2 dps=2 supports=2", "Play with 2 tanks, 2 DPS and 2 supports."},
+	}
+	command.AddCommand("set_lineup", "Set how many players of each role the team plays with.", examples, SetLineup).SetArgs(
+		command.Arg{Name: "lineup", Type: command.ArgRest, Optional: true},
+	).SetPermission(command.Captain)
+}
+
+// teamLineup returns the lineup given in a command's arguments, or the team's lineup if there isn't one.
+// If the lineup given is invalid, the reply to send back is returned instead.
+func teamLineup(s *state.State, teamID int, args command.Args) (schedule.Lineup, string, error) {
+	if args.Has("lineup") {
+		l, err := schedule.ParseLineup(args.String("lineup"))
+		if err != nil {
+			return nil, "Error parsing lineup: " + err.Error(), nil
+		}
+		return l, "", nil
+	}
+	l, err := s.DB.Lineup(teamID)
+	if err != nil {
+		return nil, "Error grabbing the team's lineup.", err
+	}
+	return l, "", nil
+}
+
+// FindSlots shows the open blocks left this week where the most of a lineup can play.
+func FindSlots(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	sched := s.FindSchedule(m.GuildID, m.ChannelID)
+	if sched == nil {
+		return "", nil
+	}
+	lineup, msg, err := teamLineup(s, t.ID, args)
+	if lineup == nil {
+		return msg, err
+	}
+	slots := schedule.FindSlots(&sched.Week, sched.Players, lineup, sched.Week.Now())
+	return formatSlots(&sched.Week, lineup, slots), nil
+}
+
+// formatSlots lists the best slots, with who can play and which roles are missing.
+func formatSlots(week *schedule.Week, lineup schedule.Lineup, slots []schedule.Slot) string {
+	if len(slots) == 0 {
+		return "No open blocks left this week."
+	}
+	lines := []string{fmt.Sprintf("**Best open blocks for %s:**", lineup)}
+	for i, slot := range slots {
+		if i == maxSlots {
+			break
+		}
+		status := fmt.Sprintf("%.0f%% of the lineup", slot.Score*100)
+		if slot.Full {
+			status = "full lineup"
+		}
+		line := fmt.Sprintf("%d. %s %s (%s), %s", i+1, week.WeekdayOf(slot.Day), week.BlockHours(slot.Day, slot.Block), week.Container[slot.Day][slot.Block].Value, status)
+		if len(slot.Yes) > 0 {
+			line += "\n   Yes: " + strings.Join(slot.Yes, ", ")
+		}
+		if len(slot.Maybe) > 0 {
+			line += "\n   Maybe: " + strings.Join(slot.Maybe, ", ")
+		}
+		if len(slot.Missing) > 0 {
+			line += "\n   Missing: " + slot.Missing.String()
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetLineup shows or sets how many players of each role a team plays with.
+func SetLineup(s *state.State, m *discordgo.MessageCreate, args command.Args) (string, error) {
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return "No team in this channel or server.", nil
+	}
+	lineup, msg, err := teamLineup(s, t.ID, args)
+	if lineup == nil {
+		return msg, err
+	} else if !args.Has("lineup") {
+		return fmt.Sprintf("The team plays with %s.", lineup), nil
+	}
+
+	err = s.DB.SetLineup(t.ID, lineup)
+	if err != nil {
+		return "Error saving the lineup.", err
+	}
+	return fmt.Sprintf("The team plays with %s now. :)", lineup), nil
+}
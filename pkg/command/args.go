@@ -0,0 +1,320 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+)
+
+// ArgType is the kind of value an argument is parsed into.
+type ArgType int
+
+// Argument types understood by the parser.
+const (
+	// ArgString is a single word, or a quoted phrase.
+	ArgString ArgType = iota
+	// ArgInt is a whole number.
+	ArgInt
+	// ArgDay is the name of a day, ex. monday or mon.
+	ArgDay
+	// ArgTimeRange is an hour or a range of hours, ex. 4, 4-6, 4pm-6pm or 16-18.
+	ArgTimeRange
+	// ArgChannel is a channel mention, ex. #general.
+	ArgChannel
+	// ArgRole is a role mention, ex. @Tanks.
+	ArgRole
+	// ArgPlayer is the name of a player on the team's schedule.
+	ArgPlayer
+	// ArgRest is everything left in the message.
+	ArgRest
+)
+
+// Arg describes one argument a command takes.
+type Arg struct {
+	Name string
+	Type ArgType
+	// Optional arguments are skipped when the next token doesn't parse as their type.
+	Optional bool
+	// Multiple arguments consume every following token that parses as their type.
+	Multiple bool
+	// Complete suggests values for the argument when it's used as a slash command option.
+	// Days and players are suggested without it.
+	Complete Completer
+}
+
+// Completer returns suggestions for an argument in a channel.
+// typed holds what's been given for the command's other arguments so far.
+type Completer func(s *state.State, guildID, channelID string, typed map[string]string) []string
+
+// TimeRange is a range of hours given to a command in 24-hour time, ex. 4pm-6pm is 16-18.
+// If only one hour is given, Start and End are equal. Hours before noon given without am or pm,
+// ex. 4-6, are left as they are; Week.BlockRange tries them in the afternoon too.
+type TimeRange struct {
+	Start int
+	End   int
+}
+
+var (
+	channelRe = regexp.MustCompile(`^<#(\d+)>$`)
+	roleRe    = regexp.MustCompile(`^<@&(\d+)>$`)
+)
+
+// Args holds the parsed arguments for a call to a command.
+type Args struct {
+	// Raw is the message split into tokens, not including the command name.
+	Raw    []string
+	values map[string][]interface{}
+}
+
+// Has returns whether an argument was given.
+func (a Args) Has(name string) bool {
+	return len(a.values[name]) > 0
+}
+
+func (a Args) get(name string) interface{} {
+	if v := a.values[name]; len(v) > 0 {
+		return v[0]
+	}
+	return nil
+}
+
+// String returns a string, player name or rest-of-line argument.
+func (a Args) String(name string) string {
+	s, _ := a.get(name).(string)
+	return s
+}
+
+// Strings returns every value given to a Multiple argument.
+func (a Args) Strings(name string) []string {
+	var values []string
+	for _, v := range a.values[name] {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// Int returns an integer argument.
+func (a Args) Int(name string) int {
+	i, _ := a.get(name).(int)
+	return i
+}
+
+// Day returns a day argument.
+func (a Args) Day(name string) time.Weekday {
+	d, _ := a.get(name).(time.Weekday)
+	return d
+}
+
+// TimeRange returns a time range argument.
+func (a Args) TimeRange(name string) TimeRange {
+	r, _ := a.get(name).(TimeRange)
+	return r
+}
+
+// Channel returns the ID of a mentioned channel.
+func (a Args) Channel(name string) string {
+	return a.String(name)
+}
+
+// Role returns the ID of a mentioned role.
+func (a Args) Role(name string) string {
+	return a.String(name)
+}
+
+// Player returns the name of a player, as written on the schedule.
+func (a Args) Player(name string) string {
+	return a.String(name)
+}
+
+// UsageError is returned when a command's arguments don't match its spec.
+type UsageError struct {
+	Command *Command
+	Reason  string
+}
+
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("%s\nUsage: `%s`", e.Reason, e.Command.Usage())
+}
+
+// usageError turns an error parsing a command's arguments into a UsageError, written as a sentence.
+func usageError(c *Command, err error) *UsageError {
+	return &UsageError{c, strings.ToUpper(err.Error()[:1]) + err.Error()[1:] + "."}
+}
+
+// Split splits a message into tokens on whitespace, keeping "quoted phrases" together.
+// If a quote isn't closed, the tokens up to and including the unclosed one are returned with an error.
+func Split(s string) ([]string, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+		inToken bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inToken = true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	if quoted {
+		return tokens, fmt.Errorf("unclosed quote in %q", tokens[len(tokens)-1])
+	}
+	return tokens, nil
+}
+
+// ParseDay parses the name of a day, or the first three letters of it.
+func ParseDay(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	if len(s) >= 3 {
+		for i := time.Sunday; i <= time.Saturday; i++ {
+			name := strings.ToLower(i.String())
+			if strings.HasPrefix(name, s) {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q", s)
+}
+
+// ParseTimeRange parses an hour or a range of hours, ex. 4, 4-6, 4pm-6pm or 16-18.
+func ParseTimeRange(s string) (TimeRange, error) {
+	start, end, _, err := schedule.ParseHours(s)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	return TimeRange{start, end}, nil
+}
+
+// ParseChannel returns the ID of the channel in a channel mention, ex. <#1234>.
+func ParseChannel(s string) (string, error) {
+	match := channelRe.FindStringSubmatch(s)
+	if match == nil {
+		return "", fmt.Errorf("invalid channel %q", s)
+	}
+	return match[1], nil
+}
+
+// ParseRole returns the ID of the role in a role mention, ex. <@&1234>.
+func ParseRole(s string) (string, error) {
+	match := roleRe.FindStringSubmatch(s)
+	if match == nil {
+		return "", fmt.Errorf("invalid role %q", s)
+	}
+	return match[1], nil
+}
+
+// parseToken parses a single token as the given type.
+func parseToken(t ArgType, token string, players []string) (interface{}, error) {
+	switch t {
+	case ArgInt:
+		i, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return i, nil
+	case ArgDay:
+		return ParseDay(token)
+	case ArgTimeRange:
+		return ParseTimeRange(token)
+	case ArgChannel:
+		return ParseChannel(token)
+	case ArgRole:
+		return ParseRole(token)
+	case ArgPlayer:
+		for _, p := range players {
+			if strings.EqualFold(p, token) {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid player %q", token)
+	default:
+		return token, nil
+	}
+}
+
+// Parse matches tokens against a command's argument spec.
+// tokens shouldn't include the command name. players is used to match ArgPlayer arguments.
+func (c *Command) Parse(tokens []string, players []string) (Args, error) {
+	args := Args{Raw: tokens, values: make(map[string][]interface{})}
+	i := 0
+	for _, arg := range c.Args {
+		if arg.Type == ArgRest {
+			if i < len(tokens) {
+				args.values[arg.Name] = []interface{}{strings.Join(tokens[i:], " ")}
+				i = len(tokens)
+			} else if !arg.Optional {
+				return args, &UsageError{c, fmt.Sprintf("Missing %s.", arg.Name)}
+			}
+			continue
+		}
+
+		for i < len(tokens) {
+			v, err := parseToken(arg.Type, tokens[i], players)
+			if err != nil {
+				if arg.Optional || (arg.Multiple && args.Has(arg.Name)) {
+					break
+				}
+				return args, usageError(c, err)
+			}
+			args.values[arg.Name] = append(args.values[arg.Name], v)
+			i++
+			if !arg.Multiple {
+				break
+			}
+		}
+		if !arg.Optional && !args.Has(arg.Name) {
+			return args, &UsageError{c, fmt.Sprintf("Missing %s.", arg.Name)}
+		}
+	}
+	if i < len(tokens) {
+		return args, &UsageError{c, fmt.Sprintf("Unexpected argument %q.", tokens[i])}
+	}
+	return args, nil
+}
+
+// Usage returns a usage string generated from the command's argument spec, ex. !set [player] <day> [time range] <values...>
+func (c *Command) Usage() string {
+	usage := "!" + c.Name
+	for _, arg := range c.Args {
+		name := arg.Name
+		if arg.Multiple || arg.Type == ArgRest {
+			name += "..."
+		}
+		if arg.Optional {
+			usage += " [" + name + "]"
+		} else {
+			usage += " <" + name + ">"
+		}
+	}
+	return usage
+}
+
+// needsPlayers returns whether any of a command's arguments are player names.
+func (c *Command) needsPlayers() bool {
+	for _, arg := range c.Args {
+		if arg.Type == ArgPlayer {
+			return true
+		}
+	}
+	return false
+}
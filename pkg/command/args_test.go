@@ -0,0 +1,126 @@
+package command
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in  string
+		out []string
+	}{
+		{"set monday 4-6 scrim", []string{"set", "monday", "4-6", "scrim"}},
+		{`add_team "Team Rocket" <#477928874450354176>`, []string{"add_team", "Team Rocket", "<#477928874450354176>"}},
+		{"get   week ", []string{"get", "week"}},
+		{`set_note monday 4 ""`, []string{"set_note", "monday", "4", ""}},
+	}
+	for _, test := range tests {
+		tokens, err := Split(test.in)
+		if err != nil {
+			t.Fatalf("Split(%q): %s", test.in, err)
+		}
+		if len(tokens) != len(test.out) {
+			t.Fatalf("Split(%q): %q != %q", test.in, tokens, test.out)
+		}
+		for i := range tokens {
+			if tokens[i] != test.out[i] {
+				t.Fatalf("Split(%q): %q != %q", test.in, tokens, test.out)
+			}
+		}
+	}
+
+	tokens, err := Split(`add_team "Team Rocket <#477928874450354176>`)
+	if err == nil || len(tokens) != 2 || tokens[1] != "Team Rocket <#477928874450354176>" {
+		t.Errorf("expected an unclosed quote to be reported: %q (%v)", tokens, err)
+	}
+}
+
+// mustSplit splits a message that's known to have its quotes closed.
+func mustSplit(t *testing.T, s string) []string {
+	t.Helper()
+	tokens, err := Split(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+func TestParse(t *testing.T) {
+	c := &Command{Name: "set", Args: []Arg{
+		{Name: "player", Type: ArgPlayer, Optional: true},
+		{Name: "day", Type: ArgDay},
+		{Name: "time range", Type: ArgTimeRange, Optional: true},
+		{Name: "values", Type: ArgRest},
+	}}
+	players := []string{"Tydra", "Taub"}
+
+	args, err := c.Parse(mustSplit(t, "tydra mon 4-6 no, yes"), players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Player("player") != "Tydra" {
+		t.Errorf("wrong player: %q != %q", args.Player("player"), "Tydra")
+	}
+	if args.Day("day") != time.Monday {
+		t.Errorf("wrong day: %s != %s", args.Day("day"), time.Monday)
+	}
+	if r := args.TimeRange("time range"); r != (TimeRange{4, 6}) {
+		t.Errorf("wrong time range: %+v", r)
+	}
+	if args.String("values") != "no, yes" {
+		t.Errorf("wrong values: %q", args.String("values"))
+	}
+
+	args, err = c.Parse(mustSplit(t, "mon 4pm-6pm scrim"), players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := args.TimeRange("time range"); r != (TimeRange{16, 18}) {
+		t.Errorf("wrong time range: %+v", r)
+	}
+
+	args, err = c.Parse(mustSplit(t, "friday scrim"), players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Has("player") || args.Has("time range") {
+		t.Errorf("optional arguments shouldn't be set: %+v", args)
+	}
+
+	if _, err = c.Parse(mustSplit(t, "tydra 4-6 yes"), players); err == nil {
+		t.Error("expected an error for a missing day")
+	} else if _, ok := err.(*UsageError); !ok {
+		t.Errorf("expected a usage error, got %T", err)
+	}
+}
+
+func TestParseMultiple(t *testing.T) {
+	c := &Command{Name: "add_channel", Args: []Arg{{Name: "channels", Type: ArgChannel, Multiple: true}}}
+	args, err := c.Parse([]string{"<#1>", "<#2>"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channels := args.Strings("channels"); len(channels) != 2 || channels[0] != "1" || channels[1] != "2" {
+		t.Errorf("wrong channels: %q", channels)
+	}
+
+	if _, err = c.Parse([]string{"<#1>", "general"}, nil); err == nil {
+		t.Error("expected an error for an invalid channel")
+	}
+	if _, err = c.Parse(nil, nil); err == nil {
+		t.Error("expected an error for no channels")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	c := &Command{Name: "add_team", Args: []Arg{
+		{Name: "name", Type: ArgString},
+		{Name: "channel", Type: ArgChannel},
+		{Name: "note", Type: ArgRest, Optional: true},
+	}}
+	const usage = "!add_team <name> <channel> [note...]"
+	if c.Usage() != usage {
+		t.Errorf("%q != %q", c.Usage(), usage)
+	}
+}
@@ -10,17 +10,19 @@ import (
 var Commands = make(map[string]*Command)
 
 // cmd is the template for any command.
-// Any command should take a session, the message, and a slice of arguments.
+// Any command should take a session, the message, and the arguments parsed from the command's spec.
 // The string returned will be sent in Discord.
-type cmd func(*state.State, *discordgo.MessageCreate, []string) (string, error)
+type cmd func(*state.State, *discordgo.MessageCreate, Args) (string, error)
 
 // Command is a struct holding info about a command and the command itself
 type Command struct {
-	Name     string
-	ShortDoc string
-	Examples [][2]string
-	Aliases  []string
-	Call     cmd
+	Name       string
+	ShortDoc   string
+	Examples   [][2]string
+	Aliases    []string
+	Args       []Arg
+	Permission Permission
+	Call       cmd
 }
 
 // AddAliases adds an alias for a command
@@ -30,6 +32,12 @@ func (c *Command) AddAliases(aliases ...string) {
 	}
 }
 
+// SetArgs sets the arguments a command takes.
+func (c *Command) SetArgs(args ...Arg) *Command {
+	c.Args = args
+	return c
+}
+
 // Match checks if the given strings matches the command's name or any of its aliases.
 func (c *Command) Match(s string) bool {
 	for _, alias := range c.Aliases {
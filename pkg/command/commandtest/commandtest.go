@@ -0,0 +1,277 @@
+// Package commandtest runs commands through the real dispatcher without connecting to Discord.
+package commandtest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/db"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Sent is a message sent through a fake Messenger.
+type Sent struct {
+	ID        string
+	ChannelID string
+	Content   string
+	Embed     *discordgo.MessageEmbed
+	Files     []*discordgo.File
+	// Components are the buttons and menus sent with the message.
+	Components []discordgo.MessageComponent
+	// AllowedMentions are who the message could ping, or nil if it didn't restrict it.
+	AllowedMentions *discordgo.MessageAllowedMentions
+	// Edits counts how many times the message was edited after it was sent.
+	Edits int
+	// Pinned is whether the message was pinned.
+	Pinned bool
+}
+
+// Messenger is a fake state.Messenger that records every message sent through it.
+type Messenger struct {
+	mu     sync.Mutex
+	sent   []*Sent
+	nextID int
+
+	// Permissions is what every user has in every channel.
+	Permissions int64
+	// Roles are the roles every member has.
+	Roles []string
+	// Guilds are returned by Guild, by ID.
+	Guilds map[string]*discordgo.Guild
+	// Channels are returned by Channel, by ID.
+	Channels map[string]*discordgo.Channel
+}
+
+var _ state.Messenger = (*Messenger)(nil)
+
+// NewMessenger returns a fake Messenger where every user is a server admin.
+func NewMessenger() *Messenger {
+	return &Messenger{
+		Permissions: discordgo.PermissionAll,
+		Guilds:      make(map[string]*discordgo.Guild),
+		Channels:    make(map[string]*discordgo.Channel),
+	}
+}
+
+func (f *Messenger) send(channelID string, s *Sent) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	s.ID = strconv.Itoa(f.nextID)
+	s.ChannelID = channelID
+	f.sent = append(f.sent, s)
+
+	m := &discordgo.Message{ID: s.ID, ChannelID: channelID, Content: s.Content, Timestamp: time.Now()}
+	if s.Embed != nil {
+		m.Embeds = []*discordgo.MessageEmbed{s.Embed}
+	}
+	return m, nil
+}
+
+// Sent returns every message sent so far, with any edits applied.
+func (f *Messenger) Sent() []Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sent := make([]Sent, len(f.sent))
+	for i, s := range f.sent {
+		sent[i] = *s
+	}
+	return sent
+}
+
+// Reset forgets every message sent so far.
+func (f *Messenger) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
+
+// ChannelMessageSend records a message.
+func (f *Messenger) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.send(channelID, &Sent{Content: content})
+}
+
+// ChannelMessageSendEmbed records an embed.
+func (f *Messenger) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.send(channelID, &Sent{Embed: embed})
+}
+
+// ChannelMessageSendComplex records a message with its first embed and any files.
+func (f *Messenger) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s := &Sent{Content: data.Content, Files: data.Files, Components: data.Components, AllowedMentions: data.AllowedMentions}
+	if len(data.Embeds) > 0 {
+		s.Embed = data.Embeds[0]
+	}
+	return f.send(channelID, s)
+}
+
+// ChannelMessageEdit updates the content of a message that was sent before.
+func (f *Messenger) ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.edit(channelID, messageID, func(s *Sent) { s.Content = content })
+}
+
+// ChannelMessageEditEmbed replaces the embed of a message that was sent before.
+func (f *Messenger) ChannelMessageEditEmbed(channelID, messageID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.edit(channelID, messageID, func(s *Sent) { s.Embed = embed })
+}
+
+// ChannelMessageEditComplex replaces the embed, files and components of a message that was sent before, and its
+// content if it's given.
+func (f *Messenger) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.edit(m.Channel, m.ID, func(s *Sent) {
+		if m.Content != nil {
+			s.Content = *m.Content
+		}
+		s.Embed = nil
+		if len(m.Embeds) > 0 {
+			s.Embed = m.Embeds[0]
+		}
+		s.Files, s.Components = m.Files, m.Components
+	})
+}
+
+// ChannelMessagePin pins a message that was sent before.
+func (f *Messenger) ChannelMessagePin(channelID, messageID string, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s := f.find(channelID, messageID); s != nil {
+		s.Pinned = true
+		return nil
+	}
+	return unknownMessage()
+}
+
+// edit applies a change to a message that was sent before, failing like Discord does if there's no such message.
+func (f *Messenger) edit(channelID, messageID string, change func(*Sent)) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.find(channelID, messageID)
+	if s == nil {
+		return nil, unknownMessage()
+	}
+	change(s)
+	s.Edits++
+	return &discordgo.Message{ID: s.ID, ChannelID: channelID, Content: s.Content}, nil
+}
+
+// find returns a message that was sent before, or nil if there isn't one. f.mu must be held.
+func (f *Messenger) find(channelID, messageID string) *Sent {
+	for _, s := range f.sent {
+		if s.ID == messageID && s.ChannelID == channelID {
+			return s
+		}
+	}
+	return nil
+}
+
+// unknownMessage returns the error Discord gives for a message that doesn't exist, ex. one that was deleted.
+func unknownMessage() error {
+	return &discordgo.RESTError{
+		Response: &http.Response{Status: "404 Not Found", StatusCode: http.StatusNotFound},
+		Message:  &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownMessage, Message: "Unknown Message"},
+	}
+}
+
+// Channel returns one of the fake's channels.
+func (f *Messenger) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	if c, ok := f.Channels[channelID]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("no channel %s", channelID)
+}
+
+// Guild returns one of the fake's guilds.
+func (f *Messenger) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	if g, ok := f.Guilds[guildID]; ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("no guild %s", guildID)
+}
+
+// GuildMember returns a member with the fake's roles.
+func (f *Messenger) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	return &discordgo.Member{GuildID: guildID, User: &discordgo.User{ID: userID}, Roles: f.Roles}, nil
+}
+
+// UserChannelPermissions returns the fake's permissions.
+func (f *Messenger) UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+	return f.Permissions, nil
+}
+
+// UserChannelCreate returns a DM channel with a user, whose ID is the user's ID prefixed with "dm-".
+func (f *Messenger) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{ID: DMChannel(recipientID), Type: discordgo.ChannelTypeDM}, nil
+}
+
+// DMChannel returns the ID of the fake DM channel with a user.
+func DMChannel(userID string) string {
+	return "dm-" + userID
+}
+
+// Harness feeds synthetic messages through the real command dispatcher.
+type Harness struct {
+	State     *state.State
+	Messenger *Messenger
+	// Store is the in-memory store State.DB keeps everything in.
+	Store     *db.Memory
+	GuildID   string
+	ChannelID string
+	Author    *discordgo.User
+}
+
+// New returns a harness with a fake Messenger and an empty in-memory store, so there are no teams until AddTeam adds
+// them.
+func New() *Harness {
+	m := NewMessenger()
+	store := db.NewMemory()
+	return &Harness{
+		State: &state.State{
+			Messenger: m,
+			DB:        store,
+			Schedules: make(map[string]*schedule.Schedule),
+			Voice:     state.NewVoice(),
+		},
+		Messenger: m,
+		Store:     store,
+		GuildID:   "100000000000000000",
+		ChannelID: "200000000000000000",
+		Author:    &discordgo.User{ID: "300000000000000000", Username: "tester"},
+	}
+}
+
+// Send dispatches a message as if the harness's author typed it in the harness's channel,
+// and returns everything sent while handling it.
+func (h *Harness) Send(content string) []Sent {
+	before := len(h.Messenger.Sent())
+	command.Dispatch(h.State, &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        "400000000000000000",
+		GuildID:   h.GuildID,
+		ChannelID: h.ChannelID,
+		Content:   content,
+		Author:    h.Author,
+		Timestamp: time.Now(),
+	}})
+	return h.Messenger.Sent()[before:]
+}
+
+// AddTeam adds a team in the harness's channel, or the guild's team if name is empty, using sched as its schedule if
+// it isn't nil.
+func (h *Harness) AddTeam(name string, sched *schedule.Schedule) team.Team {
+	t := team.Team{GuildID: h.GuildID, Name: name}
+	if name != "" {
+		t.Channels = []string{h.ChannelID}
+	}
+	t = h.Store.NewTeam(t)
+	if sched != nil {
+		h.Store.SetScheduleConfig(team.ScheduleConfig{Team: t.ID, SpreadsheetID: sched.ID, Source: "file"})
+		h.State.Schedules[sched.ID] = sched
+	}
+	return t
+}
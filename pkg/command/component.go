@@ -0,0 +1,78 @@
+package command
+
+import (
+	"log"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+// componentHandler is the template for anything that handles clicks on a button or choices from a menu on a message thonky sent.
+// args are the parts of the component's custom ID after its name. The response returned is sent back to Discord.
+type componentHandler func(*state.State, *discordgo.InteractionCreate, []string) (*discordgo.InteractionResponse, error)
+
+// Component is a kind of button or menu that thonky puts on messages.
+// Components on DMs have no guild to check roles in, so they're only handled if everyone can use them.
+type Component struct {
+	Name       string
+	Permission Permission
+	Handle     componentHandler
+}
+
+// Components is a package-level map of components, where the key is the name at the start of their custom IDs.
+// Add components using AddComponent.
+var Components = make(map[string]*Component)
+
+// AddComponent adds a kind of component that members with a permission level can use.
+func AddComponent(name string, p Permission, h componentHandler) *Component {
+	component := &Component{Name: name, Permission: p, Handle: h}
+	Components[name] = component
+	return component
+}
+
+// ComponentID builds the custom ID of a component, which is the component's name followed by args, separated by colons.
+func ComponentID(name string, args ...string) string {
+	return strings.Join(append([]string{name}, args...), ":")
+}
+
+// Ephemeral returns a response to an interaction that only the member who used it can see.
+func Ephemeral(content string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: content, Flags: discordgo.MessageFlagsEphemeral},
+	}
+}
+
+// handleComponent passes a click on a component to its handler and sends back the response.
+func handleComponent(s *state.State, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+	fields := strings.Split(data.CustomID, ":")
+	c := Components[fields[0]]
+	if c == nil || (i.Member == nil && c.Permission != Everyone) {
+		return
+	}
+
+	m := interactionMessage(i, "")
+	allowed, err := c.Permission.allowed(s, m)
+	var response *discordgo.InteractionResponse
+	if err != nil {
+		log.Printf("error checking permissions for %s in [%s]: %s\n", m.Author.ID, m.GuildID, err)
+		response = Ephemeral("Error checking your permissions. :(")
+	} else if !allowed {
+		response = Ephemeral("You need to be " + c.Permission.describe() + " to use that.")
+	} else {
+		response, err = c.Handle(s, i, fields[1:])
+		if err != nil {
+			log.Printf("error handling %s: %s\n", data.CustomID, err)
+		}
+	}
+	if response == nil {
+		return
+	}
+
+	err = s.Session.InteractionRespond(i.Interaction, response)
+	if err != nil {
+		log.Printf("error responding to %s: %s\n", data.CustomID, err)
+	}
+}
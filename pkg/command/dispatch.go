@@ -0,0 +1,110 @@
+package command
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Prefix is what messages have to start with to be treated as commands.
+const Prefix = "!"
+
+// Find returns the command with the given name or alias, or nil if there isn't one.
+func Find(name string) *Command {
+	for _, c := range Commands {
+		if c.Match(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Dispatch parses a message and calls the command it names, sending any reply back to the channel.
+func Dispatch(s *state.State, m *discordgo.MessageCreate) {
+	if !strings.HasPrefix(m.Content, Prefix) {
+		return
+	}
+	tokens, err := Split(m.Content[len(Prefix):])
+	if len(tokens) == 0 {
+		return
+	}
+
+	c := Find(tokens[0])
+	if c == nil {
+		return
+	}
+	if err != nil {
+		s.Messenger.ChannelMessageSend(m.ChannelID, usageError(c, err).Error())
+		return
+	}
+	if msg := c.Run(s, m, tokens[1:]); msg != "" {
+		s.Messenger.ChannelMessageSend(m.ChannelID, msg)
+	}
+}
+
+// Run checks that the author of a message can use the command, parses tokens against the
+// command's spec and calls it. The reply to send back is returned.
+func (c *Command) Run(s *state.State, m *discordgo.MessageCreate, tokens []string) string {
+	allowed, err := c.Allowed(s, m)
+	if err != nil {
+		log.Printf("error checking permissions for %s in [%s]: %s\n", m.Author.ID, m.GuildID, err)
+		return "Error checking your permissions. :("
+	} else if !allowed {
+		log.Printf("denied !%s for %s (%s) in [%s]: needs %s\n", c.Name, m.Author.Username, m.Author.ID, m.GuildID, c.Permission)
+		return fmt.Sprintf("You need to be %s to use !%s.", c.Permission.describe(), c.Name)
+	}
+
+	var players []string
+	if c.needsPlayers() {
+		players = SchedulePlayers(s, m.GuildID, m.ChannelID)
+	}
+	args, err := c.Parse(tokens, players)
+	if err != nil {
+		return err.Error()
+	}
+
+	msg, err := c.Call(s, m, args)
+	if err != nil {
+		log.Println(err)
+	}
+	return msg
+}
+
+// SchedulePlayers returns the names of the players on the schedule for the team in a channel.
+func SchedulePlayers(s *state.State, guildID, channelID string) []string {
+	sched := teamSchedule(s, guildID, channelID)
+	if sched == nil {
+		return nil
+	}
+	var names []string
+	for _, p := range sched.Players {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// ScheduleActivities returns the valid activities on the schedule for the team in a channel.
+func ScheduleActivities(s *state.State, guildID, channelID string) []string {
+	sched := teamSchedule(s, guildID, channelID)
+	if sched == nil {
+		return nil
+	}
+	return sched.ValidActivities
+}
+
+// teamSchedule finds the schedule for the team in a channel without sending any messages.
+func teamSchedule(s *state.State, guildID, channelID string) *schedule.Schedule {
+	team := s.FindTeam(guildID, channelID)
+	if team.ID == 0 {
+		return nil
+	}
+	spreadsheetID, err := s.DB.SpreadsheetID(team.ID)
+	if err != nil {
+		return nil
+	}
+	return s.Schedules[spreadsheetID]
+}
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Permission is the level a member needs to be at to use a command.
+type Permission int
+
+// Permission levels, from least to most trusted.
+const (
+	Everyone Permission = iota
+	Player
+	Captain
+	Admin
+)
+
+var permissionNames = []string{"everyone", "player", "captain", "admin"}
+
+func (p Permission) String() string {
+	if p < Everyone || p > Admin {
+		return fmt.Sprintf("Permission(%d)", int(p))
+	}
+	return permissionNames[p]
+}
+
+// describe returns the permission level with an article, ex. "a captain".
+func (p Permission) describe() string {
+	if p == Admin {
+		return "an admin"
+	}
+	return "a " + p.String()
+}
+
+// ParsePermission parses the name of a permission level.
+// "manager" is accepted as another name for captain.
+func ParsePermission(s string) (Permission, error) {
+	s = strings.ToLower(s)
+	if s == "manager" {
+		return Captain, nil
+	}
+	for i, name := range permissionNames {
+		if s == name {
+			return Permission(i), nil
+		}
+	}
+	return Everyone, fmt.Errorf("invalid permission level %q", s)
+}
+
+// SetPermission sets the permission level needed to use a command.
+func (c *Command) SetPermission(p Permission) *Command {
+	c.Permission = p
+	return c
+}
+
+// rolesFor returns the roles that grant a permission level.
+func rolesFor(r *team.Roles, p Permission) []string {
+	switch p {
+	case Player:
+		return r.Players
+	case Captain:
+		return r.Captains
+	case Admin:
+		return r.Admins
+	}
+	return nil
+}
+
+// memberRoles returns the IDs of the roles the author of a message has.
+func memberRoles(s *state.State, m *discordgo.MessageCreate) ([]string, error) {
+	if m.Member != nil {
+		return m.Member.Roles, nil
+	}
+	member, err := s.Messenger.GuildMember(m.GuildID, m.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	return member.Roles, nil
+}
+
+// guildAdmin returns whether a user can manage the guild a channel is in.
+func guildAdmin(s *state.State, userID, channelID string) (bool, error) {
+	perms, err := s.Messenger.UserChannelPermissions(userID, channelID)
+	if err != nil {
+		return false, err
+	}
+	return perms&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) != 0, nil
+}
+
+// Allowed checks whether the author of a message has the permission level a command needs.
+// Members who can manage the guild have every permission. Player commands are open to everyone until the team has
+// player roles, but captain and admin commands need a role set for that level or above.
+func (c *Command) Allowed(s *state.State, m *discordgo.MessageCreate) (bool, error) {
+	return c.Permission.allowed(s, m)
+}
+
+// allowed checks whether the author of a message has a permission level.
+func (p Permission) allowed(s *state.State, m *discordgo.MessageCreate) (bool, error) {
+	if p == Everyone {
+		return true, nil
+	}
+
+	admin, err := guildAdmin(s, m.Author.ID, m.ChannelID)
+	if err != nil {
+		return false, err
+	} else if admin {
+		return true, nil
+	}
+
+	t := s.FindTeam(m.GuildID, m.ChannelID)
+	if t.ID == 0 {
+		return p == Player, nil
+	}
+	roles, err := s.DB.TeamRoles(t.ID)
+	if err != nil {
+		return false, err
+	}
+	if p == Player && len(roles.Players) == 0 {
+		return true, nil
+	}
+	have, err := memberRoles(s, m)
+	if err != nil {
+		return false, err
+	}
+
+	// roles for a level also grant every level below it
+	for level := Admin; level >= p; level-- {
+		for _, role := range rolesFor(&roles, level) {
+			for _, memberRole := range have {
+				if role == memberRole {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
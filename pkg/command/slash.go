@@ -0,0 +1,333 @@
+package command
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxChoices is the most autocomplete suggestions Discord will show.
+const maxChoices = 25
+
+var optionNameRe = regexp.MustCompile(`[^\w-]`)
+
+// optionName converts an argument's name into a valid slash command option name, ex. "time range" becomes "time_range".
+func optionName(name string) string {
+	return optionNameRe.ReplaceAllString(strings.ToLower(name), "_")
+}
+
+// optionType returns the slash command option type an argument is given as.
+func optionType(arg Arg) discordgo.ApplicationCommandOptionType {
+	if arg.Multiple {
+		// there's no way to give a list as an option, so lists are typed out and parsed like messages
+		return discordgo.ApplicationCommandOptionString
+	}
+	switch arg.Type {
+	case ArgInt:
+		return discordgo.ApplicationCommandOptionInteger
+	case ArgChannel:
+		return discordgo.ApplicationCommandOptionChannel
+	case ArgRole:
+		return discordgo.ApplicationCommandOptionRole
+	default:
+		return discordgo.ApplicationCommandOptionString
+	}
+}
+
+// optionDescription describes an argument, using a value from the command's examples if there is one.
+func optionDescription(arg Arg, example string) string {
+	var desc string
+	switch arg.Type {
+	case ArgInt:
+		desc = "A number"
+	case ArgDay:
+		desc = "A day of the week"
+	case ArgTimeRange:
+		desc = "An hour or range of hours"
+	case ArgChannel:
+		desc = "A channel"
+	case ArgRole:
+		desc = "A role"
+	case ArgPlayer:
+		desc = "A player on the schedule"
+	default:
+		desc = strings.ToUpper(arg.Name[:1]) + arg.Name[1:]
+	}
+	if arg.Multiple {
+		desc += ", or more than one separated by spaces"
+	}
+	if example != "" {
+		desc += fmt.Sprintf(", ex. %s", example)
+	}
+	if len(desc) > 100 {
+		desc = desc[:97] + "..."
+	}
+	return desc
+}
+
+// exampleValues returns a value for each argument taken from the command's examples.
+func (c *Command) exampleValues() map[string]string {
+	values := make(map[string]string)
+	for _, example := range c.Examples {
+		tokens, err := Split(example[0])
+		if err != nil || len(tokens) == 0 || tokens[0] != Prefix+c.Name {
+			continue
+		}
+		args, err := c.Parse(tokens[1:], nil)
+		if err != nil {
+			continue
+		}
+		for _, arg := range c.Args {
+			if _, ok := values[arg.Name]; ok || !args.Has(arg.Name) {
+				continue
+			}
+			switch arg.Type {
+			case ArgChannel:
+				values[arg.Name] = "#channel"
+			case ArgRole:
+				values[arg.Name] = "@role"
+			case ArgTimeRange:
+				r := args.TimeRange(arg.Name)
+				values[arg.Name] = fmt.Sprintf("%d-%d", r.Start, r.End)
+			case ArgDay:
+				values[arg.Name] = strings.ToLower(args.Day(arg.Name).String())
+			case ArgInt:
+				values[arg.Name] = strconv.Itoa(args.Int(arg.Name))
+			default:
+				values[arg.Name] = strings.Join(args.Strings(arg.Name), " ")
+			}
+		}
+	}
+	return values
+}
+
+// ApplicationCommand returns the slash command version of a command.
+func (c *Command) ApplicationCommand() *discordgo.ApplicationCommand {
+	desc := c.ShortDoc
+	if len(desc) > 100 {
+		desc = desc[:97] + "..."
+	}
+	dm := false
+	ac := &discordgo.ApplicationCommand{
+		Name:         c.Name,
+		Description:  desc,
+		DMPermission: &dm,
+	}
+
+	examples := c.exampleValues()
+	var required, optional []*discordgo.ApplicationCommandOption
+	for _, arg := range c.Args {
+		option := &discordgo.ApplicationCommandOption{
+			Type:         optionType(arg),
+			Name:         optionName(arg.Name),
+			Description:  optionDescription(arg, examples[arg.Name]),
+			Required:     !arg.Optional,
+			Autocomplete: arg.Complete != nil || (!arg.Multiple && (arg.Type == ArgDay || arg.Type == ArgPlayer)),
+		}
+		if option.Required {
+			required = append(required, option)
+		} else {
+			optional = append(optional, option)
+		}
+	}
+	// Discord wants every required option before the optional ones
+	ac.Options = append(required, optional...)
+	return ac
+}
+
+// Register publishes every command as a slash command, replacing any that were published before.
+func Register(s *state.State) error {
+	var names []string
+	for name := range Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var commands []*discordgo.ApplicationCommand
+	for _, name := range names {
+		commands = append(commands, Commands[name].ApplicationCommand())
+	}
+	_, err := s.Session.ApplicationCommandBulkOverwrite(s.Session.State.User.ID, "", commands)
+	return err
+}
+
+// interactionMessage wraps an interaction in a message so it can be handled like one.
+func interactionMessage(i *discordgo.InteractionCreate, content string) *discordgo.MessageCreate {
+	author := i.User
+	if i.Member != nil {
+		author = i.Member.User
+	}
+	return &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        i.ID,
+		ChannelID: i.ChannelID,
+		GuildID:   i.GuildID,
+		Content:   content,
+		Author:    author,
+		Member:    i.Member,
+		Timestamp: time.Now(),
+	}}
+}
+
+// optionValues returns the value given for each of a command's arguments in an interaction, as they'd be typed in a message.
+func (c *Command) optionValues(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]string {
+	values := make(map[string]string)
+	for _, arg := range c.Args {
+		for _, option := range options {
+			if option.Name != optionName(arg.Name) {
+				continue
+			}
+			switch option.Type {
+			case discordgo.ApplicationCommandOptionChannel:
+				values[arg.Name] = "<#" + fmt.Sprint(option.Value) + ">"
+			case discordgo.ApplicationCommandOptionRole:
+				values[arg.Name] = "<@&" + fmt.Sprint(option.Value) + ">"
+			case discordgo.ApplicationCommandOptionInteger:
+				values[arg.Name] = strconv.FormatInt(option.IntValue(), 10)
+			default:
+				values[arg.Name] = fmt.Sprint(option.Value)
+			}
+		}
+	}
+	return values
+}
+
+// tokens converts the options given in an interaction into tokens, in the order the command's spec expects.
+func (c *Command) tokens(values map[string]string) ([]string, error) {
+	var tokens []string
+	for _, arg := range c.Args {
+		value, ok := values[arg.Name]
+		if !ok {
+			continue
+		}
+		if arg.Multiple || arg.Type == ArgRest {
+			split, err := Split(value)
+			if err != nil {
+				return nil, usageError(c, err)
+			}
+			tokens = append(tokens, split...)
+		} else {
+			tokens = append(tokens, value)
+		}
+	}
+	return tokens, nil
+}
+
+// HandleInteraction runs slash commands through the same handlers as messages, answers autocomplete requests
+// and passes clicks on components to their handlers.
+func HandleInteraction(s *state.State, i *discordgo.InteractionCreate) {
+	if i.Type == discordgo.InteractionMessageComponent {
+		handleComponent(s, i)
+		return
+	}
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return
+	}
+	data := i.ApplicationCommandData()
+	c := Commands[data.Name]
+	if c == nil || i.Member == nil {
+		return
+	}
+	values := c.optionValues(data.Options)
+
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		err := s.Session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{Choices: c.complete(s, i, data.Options, values)},
+		})
+		if err != nil {
+			log.Printf("error sending suggestions for /%s: %s\n", c.Name, err)
+		}
+		return
+	}
+
+	// handlers can take longer than Discord waits for a response, so respond now and edit it later
+	err := s.Session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		log.Printf("error responding to /%s: %s\n", c.Name, err)
+		return
+	}
+
+	var msg string
+	tokens, err := c.tokens(values)
+	if err != nil {
+		msg = err.Error()
+	} else {
+		content := Prefix + c.Name
+		if len(tokens) > 0 {
+			content += " " + strings.Join(tokens, " ")
+		}
+		msg = c.Run(s, interactionMessage(i, content), tokens)
+	}
+	if msg == "" {
+		// the handler already sent whatever it had to say
+		err = s.Session.InteractionResponseDelete(i.Interaction)
+	} else {
+		_, err = s.Session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+	}
+	if err != nil {
+		log.Printf("error finishing /%s: %s\n", c.Name, err)
+	}
+}
+
+// complete suggests values for the option being typed in an autocomplete interaction.
+func (c *Command) complete(s *state.State, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, values map[string]string) []*discordgo.ApplicationCommandOptionChoice {
+	var arg *Arg
+	var typed string
+	for _, option := range options {
+		if !option.Focused {
+			continue
+		}
+		for j := range c.Args {
+			if optionName(c.Args[j].Name) == option.Name {
+				arg = &c.Args[j]
+				typed = fmt.Sprint(option.Value)
+			}
+		}
+	}
+	if arg == nil {
+		return nil
+	}
+
+	var suggestions []string
+	switch {
+	case arg.Complete != nil:
+		suggestions = arg.Complete(s, i.GuildID, i.ChannelID, values)
+	case arg.Type == ArgDay:
+		for d := time.Monday; d <= time.Saturday; d++ {
+			suggestions = append(suggestions, strings.ToLower(d.String()))
+		}
+		suggestions = append(suggestions, "sunday")
+	case arg.Type == ArgPlayer:
+		suggestions = SchedulePlayers(s, i.GuildID, i.ChannelID)
+	}
+	return choices(suggestions, typed)
+}
+
+// choices filters suggestions down to the ones that start with what's been typed.
+// Lists of values separated by commas, ex. "no, yes", are completed one value at a time.
+func choices(suggestions []string, typed string) []*discordgo.ApplicationCommandOptionChoice {
+	var before string
+	if i := strings.LastIndex(typed, ", "); i != -1 {
+		before, typed = typed[:i+2], typed[i+2:]
+	}
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, suggestion := range suggestions {
+		if len(choices) == maxChoices {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(suggestion), strings.ToLower(typed)) {
+			value := before + suggestion
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+		}
+	}
+	return choices
+}
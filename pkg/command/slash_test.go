@@ -0,0 +1,66 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestApplicationCommand(t *testing.T) {
+	c := &Command{
+		Name:     "set",
+		ShortDoc: "Update information on the configured spreadsheet.",
+		Examples: [][2]string{{"!set monday 4-6 scrim", "Set the 4-6 block on Monday to Scrim"}},
+		Args: []Arg{
+			{Name: "player", Type: ArgPlayer, Optional: true},
+			{Name: "day", Type: ArgDay},
+			{Name: "time range", Type: ArgTimeRange, Optional: true},
+			{Name: "values", Type: ArgRest},
+		},
+	}
+	ac := c.ApplicationCommand()
+	names := []string{"day", "values", "player", "time_range"}
+	if len(ac.Options) != len(names) {
+		t.Fatalf("wrong amount of options: %d != %d", len(ac.Options), len(names))
+	}
+	for i, option := range ac.Options {
+		if option.Name != names[i] {
+			t.Errorf("option %d: %q != %q", i, option.Name, names[i])
+		}
+	}
+	if !ac.Options[0].Autocomplete || !ac.Options[2].Autocomplete {
+		t.Error("days and players should autocomplete")
+	}
+	const desc = "An hour or range of hours, ex. 4-6"
+	if ac.Options[3].Description != desc {
+		t.Errorf("%q != %q", ac.Options[3].Description, desc)
+	}
+
+	tokens, err := c.tokens(c.optionValues([]*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "values", Type: discordgo.ApplicationCommandOptionString, Value: "no, yes"},
+		{Name: "day", Type: discordgo.ApplicationCommandOptionString, Value: "monday"},
+		{Name: "time_range", Type: discordgo.ApplicationCommandOptionString, Value: "4-6"},
+	}))
+	if err != nil {
+		t.Fatalf("options didn't split: %s", err)
+	}
+	if _, err := c.Parse(tokens, nil); err != nil {
+		t.Errorf("options didn't parse: %s", err)
+	}
+	_, err = c.tokens(c.optionValues([]*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "values", Type: discordgo.ApplicationCommandOptionString, Value: "\"no"},
+	}))
+	if err == nil {
+		t.Error("unclosed quote should be an error")
+	}
+}
+
+func TestChoices(t *testing.T) {
+	c := choices([]string{"Yes", "Maybe", "No"}, "no, m")
+	if len(c) != 1 || c[0].Value != "no, Maybe" {
+		t.Errorf("wrong choices: %+v", c)
+	}
+	if c = choices([]string{"Yes", "Maybe", "No"}, ""); len(c) != 3 {
+		t.Errorf("wrong amount of choices: %d != 3", len(c))
+	}
+}
@@ -1,8 +1,12 @@
 package db
 
 import (
+	"bytes"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bigheadgeorge/thonky2/pkg/schedule"
 	"github.com/bigheadgeorge/thonky2/pkg/team"
@@ -10,22 +14,42 @@ import (
 	"github.com/lib/pq"
 )
 
-// Handler makes grabbing and updating config easier
+// Handler keeps thonky's Store in Postgres.
 type Handler struct {
 	*sqlx.DB
 }
 
-// AddTeam adds a team to the database
-func (d *Handler) AddTeam(guildID, name, channel string) error {
-	var t team.Team
-	err := d.Get(&t, "SELECT * FROM teams WHERE server_id=$1", "0")
+// Open connects to a Postgres database.
+func Open(user, password, host, database string) (*Handler, error) {
+	d, err := sqlx.Open("postgres", fmt.Sprintf("user=%s password=%s host=%s dbname=%s", user, password, host, database))
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	t.GuildID = guildID
-	t.Name = name
-	t.Channels = pq.StringArray([]string{channel})
-	_, err = d.Query("INSERT INTO teams (server_id, team_name, channels) VALUES ($1, $2, $3)", t.GuildID, t.Name, t.Channels)
+	return &Handler{DB: d}, nil
+}
+
+// teamRow is a team as it's kept in Postgres, where its channels are an array.
+type teamRow struct {
+	ID       int            `db:"id"`
+	GuildID  string         `db:"server_id"`
+	Name     string         `db:"team_name"`
+	Channels pq.StringArray `db:"channels"`
+}
+
+func (r teamRow) team() team.Team {
+	return team.Team{ID: r.ID, GuildID: r.GuildID, Name: r.Name, Channels: r.Channels}
+}
+
+// getTeam returns the team a query finds.
+func (d *Handler) getTeam(query string, args ...interface{}) (team.Team, error) {
+	var r teamRow
+	err := d.Get(&r, query, args...)
+	return r.team(), err
+}
+
+// AddTeam adds a team to the database
+func (d *Handler) AddTeam(guildID, name, channel string) error {
+	_, err := d.Exec("INSERT INTO teams (server_id, team_name, channels) VALUES ($1, $2, $3)", guildID, name, pq.StringArray{channel})
 	return err
 }
 
@@ -36,13 +60,34 @@ func (d *Handler) GetName(channelID string) (string, error) {
 	return teamName, err
 }
 
-// ExecJSON runs a query with a JSON representation of v.
-func (d *Handler) ExecJSON(query string, v interface{}) error {
-	b, err := json.Marshal(v)
-	if err != nil {
-		return err
+// Team returns a team by its ID.
+func (d *Handler) Team(teamID int) (team.Team, error) {
+	return d.getTeam("SELECT * FROM teams WHERE id = $1", teamID)
+}
+
+// GuildTeams returns every team in a guild.
+func (d *Handler) GuildTeams(guildID string) (teams []team.Team, err error) {
+	var rows []teamRow
+	err = d.Select(&rows, "SELECT * FROM teams WHERE server_id = $1", guildID)
+	for _, r := range rows {
+		teams = append(teams, r.team())
 	}
-	_, err = d.Exec(query, b)
+	return
+}
+
+// ChannelTeam returns the team in a channel in a guild.
+func (d *Handler) ChannelTeam(guildID, channelID string) (team.Team, error) {
+	return d.getTeam("SELECT * FROM teams WHERE server_id = $1 AND $2 = ANY(channels)", guildID, channelID)
+}
+
+// GuildTeam returns the team for a whole guild.
+func (d *Handler) GuildTeam(guildID string) (team.Team, error) {
+	return d.getTeam("SELECT * FROM teams WHERE server_id = $1 AND 0 = LENGTH(team_name)", guildID)
+}
+
+// SetTeamChannels sets the channels a team is in.
+func (d *Handler) SetTeamChannels(teamID int, channels []string) error {
+	_, err := d.Exec("UPDATE teams SET channels = $1 WHERE id = $2", pq.StringArray(channels), teamID)
 	return err
 }
 
@@ -76,9 +121,80 @@ func (d *Handler) CacheSchedule(s *schedule.Schedule) (err error) {
 	} else {
 		_, err = d.Exec(query, s.ID, s.LastModified, b[0], b[1], activities)
 	}
+	if err != nil {
+		return
+	}
+	return d.saveSnapshot(s.ID, s.Week.Date, b[1], b[0])
+}
+
+// saveSnapshot appends a week and players to a schedule's history, unless they're the same as the last snapshot of that week.
+func (d *Handler) saveSnapshot(spreadsheetID, date string, week, players []byte) error {
+	var last [2][]byte
+	err := d.QueryRow("SELECT week, players FROM history WHERE spreadsheet_id = $1 AND date = $2 ORDER BY id DESC LIMIT 1", spreadsheetID, date).Scan(&last[0], &last[1])
+	if err == nil && bytes.Equal(last[0], week) && bytes.Equal(last[1], players) {
+		return nil
+	} else if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	_, err = d.Exec("INSERT INTO history (spreadsheet_id, date, week, players) VALUES ($1, $2, $3, $4)", spreadsheetID, date, week, players)
+	return err
+}
+
+// Snapshots returns when each snapshot in a schedule's history was saved, newest first, without their weeks or players.
+func (d *Handler) Snapshots(spreadsheetID string) (snapshots []schedule.Snapshot, err error) {
+	err = d.Select(&snapshots, "SELECT id, spreadsheet_id, date, saved FROM history WHERE spreadsheet_id = $1 ORDER BY id DESC", spreadsheetID)
+	return
+}
+
+// Snapshot returns a snapshot in a schedule's history, or sql.ErrNoRows if the schedule doesn't have it.
+func (d *Handler) Snapshot(spreadsheetID string, id int) (snap schedule.Snapshot, err error) {
+	var data [2][]byte
+	err = d.QueryRow("SELECT id, spreadsheet_id, date, saved, week, players FROM history WHERE spreadsheet_id = $1 AND id = $2", spreadsheetID, id).Scan(&snap.ID, &snap.SpreadsheetID, &snap.Date, &snap.Saved, &data[0], &data[1])
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data[0], &snap.Week)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data[1], &snap.Players)
+	return
+}
+
+// CacheModified returns when the cached copy of a schedule was last modified at its source.
+func (d *Handler) CacheModified(spreadsheetID string) (modified time.Time, err error) {
+	err = d.Get(&modified, "SELECT modified FROM cache WHERE id = $1", spreadsheetID)
+	return
+}
+
+// DefaultWeek returns the week a schedule is reset to.
+func (d *Handler) DefaultWeek(spreadsheetID string) (w schedule.Week, err error) {
+	var b []byte
+	err = d.QueryRow("SELECT default_week FROM sheet_info WHERE id = $1", spreadsheetID).Scan(&b)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &w)
 	return
 }
 
+// SetDefaultWeek sets the week a schedule is reset to.
+func (d *Handler) SetDefaultWeek(spreadsheetID string, w schedule.Week) error {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	r, err := d.Exec("UPDATE sheet_info SET default_week = $1 WHERE id = $2", b, spreadsheetID)
+	if err != nil {
+		return err
+	}
+	if n, err := r.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	_, err = d.Exec("INSERT INTO sheet_info (id, default_week) VALUES ($1, $2)", spreadsheetID, b)
+	return err
+}
+
 // CachedSchedule returns a cached schedule
 func (d *Handler) CachedSchedule(s *schedule.Schedule) (err error) {
 	var data [3][]byte
@@ -107,3 +223,367 @@ func (d *Handler) SpreadsheetID(teamID int) (id string, err error) {
 	err = d.QueryRow("SELECT spreadsheet_id FROM schedules WHERE team = $1", teamID).Scan(&id)
 	return
 }
+
+// ScheduleConfig returns where a team's schedule comes from and how it's read.
+func (d *Handler) ScheduleConfig(teamID int) (c team.ScheduleConfig, err error) {
+	err = d.Get(&c, "SELECT team, spreadsheet_id, source, layout, COALESCE(timezone, '') AS timezone, update_interval FROM schedules WHERE team = $1", teamID)
+	return
+}
+
+// SetLayout sets where everything is on a team's spreadsheet.
+func (d *Handler) SetLayout(teamID int, layout []byte) error {
+	_, err := d.Exec("UPDATE schedules SET layout = $1 WHERE team = $2", layout, teamID)
+	return err
+}
+
+// SetTimezone sets the IANA timezone a team's schedule is in.
+func (d *Handler) SetTimezone(teamID int, timezone string) error {
+	_, err := d.Exec("UPDATE schedules SET timezone = $1 WHERE team = $2", timezone, teamID)
+	return err
+}
+
+// SetChangesChannel sets the channel changes to a team's schedule are announced in, or stops announcing them if
+// channelID is empty.
+func (d *Handler) SetChangesChannel(teamID int, channelID string) error {
+	channel := sql.NullString{String: channelID, Valid: channelID != ""}
+	_, err := d.Exec("UPDATE schedules SET changes_channel = $1 WHERE team = $2", channel, teamID)
+	return err
+}
+
+// TeamRoles returns the roles that grant permission levels on a team.
+// Teams without any roles configured get an empty Roles.
+func (d *Handler) TeamRoles(teamID int) (team.Roles, error) {
+	r := team.Roles{Team: teamID}
+	err := d.QueryRow("SELECT player_roles, captain_roles, admin_roles FROM team_roles WHERE team = $1", teamID).Scan(pq.Array(&r.Players), pq.Array(&r.Captains), pq.Array(&r.Admins))
+	if err == sql.ErrNoRows {
+		return r, nil
+	}
+	return r, err
+}
+
+// SetTeamRoles updates the roles that grant permission levels on a team.
+func (d *Handler) SetTeamRoles(r team.Roles) error {
+	_, err := d.Exec("INSERT INTO team_roles (team, player_roles, captain_roles, admin_roles) VALUES ($1, $2, $3, $4) ON CONFLICT (team) DO UPDATE SET player_roles = EXCLUDED.player_roles, captain_roles = EXCLUDED.captain_roles, admin_roles = EXCLUDED.admin_roles", r.Team, pq.Array(r.Players), pq.Array(r.Captains), pq.Array(r.Admins))
+	return err
+}
+
+// PlayerLinks returns every Discord user linked to a player on a team.
+func (d *Handler) PlayerLinks(teamID int) (links []team.PlayerLink, err error) {
+	err = d.Select(&links, "SELECT * FROM player_links WHERE team = $1", teamID)
+	return
+}
+
+// PlayerLink returns the player a Discord user is linked to on a team, or sql.ErrNoRows if they aren't linked.
+func (d *Handler) PlayerLink(teamID int, userID string) (l team.PlayerLink, err error) {
+	err = d.Get(&l, "SELECT * FROM player_links WHERE team = $1 AND user_id = $2", teamID, userID)
+	return
+}
+
+// SetPlayerLink links a Discord user to a player on a team, replacing any link they had before.
+func (d *Handler) SetPlayerLink(l team.PlayerLink) error {
+	_, err := d.Exec("INSERT INTO player_links (team, user_id, player, dm_reminders) VALUES ($1, $2, $3, $4) ON CONFLICT (team, user_id) DO UPDATE SET player = EXCLUDED.player, dm_reminders = EXCLUDED.dm_reminders", l.Team, l.UserID, l.Player, l.DMReminders)
+	return err
+}
+
+// RemovePlayerLink unlinks a Discord user from their player on a team.
+func (d *Handler) RemovePlayerLink(teamID int, userID string) error {
+	_, err := d.Exec("DELETE FROM player_links WHERE team = $1 AND user_id = $2", teamID, userID)
+	return err
+}
+
+// SchedulePosts returns every message kept up to date with a schedule, for all of the teams using it.
+func (d *Handler) SchedulePosts(spreadsheetID string) (posts []team.SchedulePost, err error) {
+	err = d.Select(&posts, "SELECT p.* FROM schedule_posts p JOIN schedules s ON s.team = p.team WHERE s.spreadsheet_id = $1", spreadsheetID)
+	return
+}
+
+// SetSchedulePost keeps a message up to date with a team's schedule, replacing any message kept in its channel before.
+func (d *Handler) SetSchedulePost(p team.SchedulePost) error {
+	_, err := d.Exec("INSERT INTO schedule_posts (team, channel_id, message_id, kind) VALUES ($1, $2, $3, $4) ON CONFLICT (channel_id) DO UPDATE SET team = EXCLUDED.team, message_id = EXCLUDED.message_id, kind = EXCLUDED.kind", p.Team, p.ChannelID, p.MessageID, p.Kind)
+	return err
+}
+
+// RemoveSchedulePost stops keeping the message in a channel up to date.
+func (d *Handler) RemoveSchedulePost(channelID string) error {
+	_, err := d.Exec("DELETE FROM schedule_posts WHERE channel_id = $1", channelID)
+	return err
+}
+
+// ChangesChannels returns the channels changes to a schedule are announced in, for all of the teams using it.
+func (d *Handler) ChangesChannels(spreadsheetID string) (channels []string, err error) {
+	err = d.Select(&channels, "SELECT changes_channel FROM schedules WHERE spreadsheet_id = $1 AND changes_channel IS NOT NULL", spreadsheetID)
+	return
+}
+
+// Lineup returns how many players of each role a team plays with, or the default lineup if the team hasn't set one.
+func (d *Handler) Lineup(teamID int) (schedule.Lineup, error) {
+	var b []byte
+	err := d.QueryRow("SELECT lineup FROM schedules WHERE team = $1", teamID).Scan(&b)
+	if err != nil || b == nil {
+		return schedule.DefaultLineup, err
+	}
+	var l schedule.Lineup
+	err = json.Unmarshal(b, &l)
+	return l, err
+}
+
+// SetLineup sets how many players of each role a team plays with.
+func (d *Handler) SetLineup(teamID int, l schedule.Lineup) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	_, err = d.Exec("UPDATE schedules SET lineup = $1 WHERE team = $2", b, teamID)
+	return err
+}
+
+// Starters returns the players a team prefers to start when picking lineups, in order.
+func (d *Handler) Starters(teamID int) ([]string, error) {
+	var starters pq.StringArray
+	err := d.QueryRow("SELECT starters FROM schedules WHERE team = $1", teamID).Scan(&starters)
+	return starters, err
+}
+
+// SetStarters sets the players a team prefers to start when picking lineups.
+func (d *Handler) SetStarters(teamID int, starters []string) error {
+	_, err := d.Exec("UPDATE schedules SET starters = $1 WHERE team = $2", pq.StringArray(starters), teamID)
+	return err
+}
+
+// CalendarToken returns the secret in the URL of a team's calendar feed, or an empty string if it doesn't have one.
+func (d *Handler) CalendarToken(teamID int) (string, error) {
+	var token sql.NullString
+	err := d.QueryRow("SELECT calendar_token FROM schedules WHERE team = $1", teamID).Scan(&token)
+	return token.String, err
+}
+
+// SetCalendarToken sets the secret in the URL of a team's calendar feed.
+func (d *Handler) SetCalendarToken(teamID int, token string) error {
+	_, err := d.Exec("UPDATE schedules SET calendar_token = $1 WHERE team = $2", token, teamID)
+	return err
+}
+
+// CalendarTeam returns the team and spreadsheet ID of the schedule whose calendar feed has a token,
+// or sql.ErrNoRows if no feed does.
+func (d *Handler) CalendarTeam(token string) (t team.Team, spreadsheetID string, err error) {
+	err = d.QueryRow("SELECT t.id, t.server_id, t.team_name, s.spreadsheet_id FROM schedules s JOIN teams t ON t.id = s.team WHERE s.calendar_token = $1", token).Scan(&t.ID, &t.GuildID, &t.Name, &spreadsheetID)
+	return
+}
+
+// AddAPIToken lets a token use the HTTP API for a team. Only the token's hash is saved.
+func (d *Handler) AddAPIToken(teamID int, hash string) error {
+	_, err := d.Exec("INSERT INTO api_tokens (token_hash, team) VALUES ($1, $2)", hash, teamID)
+	return err
+}
+
+// RemoveAPITokens stops every token for a team from using the HTTP API.
+func (d *Handler) RemoveAPITokens(teamID int) error {
+	_, err := d.Exec("DELETE FROM api_tokens WHERE team = $1", teamID)
+	return err
+}
+
+// APITokenTeam returns the team a token's hash can use the HTTP API for, or sql.ErrNoRows if it's not a token.
+func (d *Handler) APITokenTeam(hash string) (team.Team, error) {
+	return d.getTeam("SELECT t.* FROM api_tokens a JOIN teams t ON t.id = a.team WHERE a.token_hash = $1", hash)
+}
+
+// TournamentLinks returns the links to a team's Battlefy and GameBattles tournaments, or empty strings for ones they
+// aren't in.
+func (d *Handler) TournamentLinks(teamID int) (battlefy, gamebattles string, err error) {
+	var link sql.NullString
+	err = d.QueryRow("SELECT tournament_link FROM battlefy WHERE team = $1", teamID).Scan(&link)
+	if err != nil && err != sql.ErrNoRows {
+		return
+	}
+	battlefy = link.String
+	link = sql.NullString{}
+	err = d.QueryRow("SELECT tournament_link FROM gamebattles WHERE team = $1", teamID).Scan(&link)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return battlefy, link.String, err
+}
+
+// ReminderConfig returns the reminder config for a team, or an empty config if it doesn't have one.
+func (d *Handler) ReminderConfig(teamID int) (*team.ReminderConfig, error) {
+	c := &team.ReminderConfig{Team: teamID}
+	err := d.QueryRow("SELECT intervals, activities, COALESCE(announce_channel, ''), role_mention FROM reminders WHERE team = $1", teamID).Scan(pq.Array(&c.Intervals), pq.Array(&c.Activities), &c.AnnounceChannel, &c.RoleMention)
+	if err == sql.ErrNoRows {
+		return c, nil
+	}
+	return c, err
+}
+
+// SetReminderConfig saves a reminder config.
+func (d *Handler) SetReminderConfig(c *team.ReminderConfig) error {
+	_, err := d.Exec("INSERT INTO reminders (team, intervals, activities, announce_channel, role_mention) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (team) DO UPDATE SET intervals = EXCLUDED.intervals, activities = EXCLUDED.activities, announce_channel = EXCLUDED.announce_channel, role_mention = EXCLUDED.role_mention", c.Team, pq.Array(c.Intervals), pq.Array(c.Activities), c.AnnounceChannel, c.RoleMention)
+	return err
+}
+
+// NagConfig returns the nag config for a team, or a config that never nags if it doesn't have one.
+func (d *Handler) NagConfig(teamID int) (*team.NagConfig, error) {
+	c := &team.NagConfig{Team: teamID, Days: 3}
+	err := d.QueryRow("SELECT hours, days, channel, digest_channel FROM nags WHERE team = $1", teamID).Scan(pq.Array(&c.Hours), &c.Days, &c.Channel, &c.DigestChannel)
+	if err == sql.ErrNoRows {
+		return c, nil
+	}
+	return c, err
+}
+
+// SetNagConfig saves a nag config.
+func (d *Handler) SetNagConfig(c *team.NagConfig) error {
+	_, err := d.Exec("INSERT INTO nags (team, hours, days, channel, digest_channel) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (team) DO UPDATE SET hours = EXCLUDED.hours, days = EXCLUDED.days, channel = EXCLUDED.channel, digest_channel = EXCLUDED.digest_channel", c.Team, pq.Array(c.Hours), c.Days, c.Channel, c.DigestChannel)
+	return err
+}
+
+// tournamentTable returns the table a tournament site's tournaments are kept in.
+func tournamentTable(site string) (string, error) {
+	switch site {
+	case team.Battlefy, team.Gamebattles:
+		return site, nil
+	}
+	return "", fmt.Errorf("unknown tournament site %q", site)
+}
+
+// Tournament returns the tournament a team is in on a site, or sql.ErrNoRows if they aren't in one there.
+func (d *Handler) Tournament(site string, teamID int) (t team.Tournament, err error) {
+	table, err := tournamentTable(site)
+	if err != nil {
+		return
+	}
+	err = d.Get(&t, "SELECT team, COALESCE(tournament_link, '') AS tournament_link, COALESCE(team_id, '') AS team_id FROM "+table+" WHERE team = $1", teamID)
+	t.Site = site
+	return
+}
+
+// SetTournament sets the tournament a team is in on a site.
+func (d *Handler) SetTournament(t team.Tournament) (err error) {
+	switch t.Site {
+	case team.Battlefy:
+		_, err = d.Exec("INSERT INTO battlefy (team, stage_id, tournament_link, team_id) VALUES ($1, $2, $3, $4) ON CONFLICT (team) DO UPDATE SET stage_id = EXCLUDED.stage_id, tournament_link = EXCLUDED.tournament_link, team_id = EXCLUDED.team_id", t.Team, t.Link[strings.LastIndex(t.Link, "/")+1:], t.Link, t.TeamID)
+	case team.Gamebattles:
+		_, err = d.Exec("INSERT INTO gamebattles (team, tournament_link, team_id) VALUES ($1, $2, $3) ON CONFLICT (team) DO UPDATE SET tournament_link = EXCLUDED.tournament_link, team_id = EXCLUDED.team_id", t.Team, t.Link, t.TeamID)
+	default:
+		_, err = tournamentTable(t.Site)
+	}
+	return
+}
+
+// AddScrim books a scrim, and returns its ID.
+func (d *Handler) AddScrim(s schedule.Scrim) (id int, err error) {
+	err = d.QueryRow("INSERT INTO scrims (team, starts, ends, opponent, contact, region, maps, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id", s.Team, s.Start, s.End, s.Opponent, s.Contact, s.Region, s.Maps, s.Status).Scan(&id)
+	return
+}
+
+// Scrim returns one of a team's scrims, or sql.ErrNoRows if the team doesn't have it.
+func (d *Handler) Scrim(teamID, id int) (s schedule.Scrim, err error) {
+	err = d.Get(&s, "SELECT * FROM scrims WHERE team = $1 AND id = $2", teamID, id)
+	return
+}
+
+// Scrims returns a team's scrims that end after a time, soonest first.
+func (d *Handler) Scrims(teamID int, after time.Time) (scrims []schedule.Scrim, err error) {
+	err = d.Select(&scrims, "SELECT * FROM scrims WHERE team = $1 AND ends > $2 ORDER BY starts, id", teamID, after)
+	return
+}
+
+// SetScrimStatus confirms or cancels a scrim.
+func (d *Handler) SetScrimStatus(teamID, id int, status schedule.ScrimStatus) error {
+	_, err := d.Exec("UPDATE scrims SET status = $1 WHERE team = $2 AND id = $3", status, teamID, id)
+	return err
+}
+
+// LFSListing returns a team's listing on the looking for scrim board, or sql.ErrNoRows if it isn't on it.
+func (d *Handler) LFSListing(teamID int) (l team.LFSListing, err error) {
+	err = d.Get(&l, "SELECT * FROM lfs_listings WHERE team = $1", teamID)
+	return
+}
+
+// LFSListings returns every team on the looking for scrim board.
+func (d *Handler) LFSListings() (listings []team.LFSListing, err error) {
+	err = d.Select(&listings, "SELECT * FROM lfs_listings ORDER BY team")
+	return
+}
+
+// SetLFSListing puts a team on the looking for scrim board, or updates its listing.
+func (d *Handler) SetLFSListing(l team.LFSListing) error {
+	_, err := d.Exec("INSERT INTO lfs_listings (team, region, min_sr, max_sr, channel_id) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (team) DO UPDATE SET region = EXCLUDED.region, min_sr = EXCLUDED.min_sr, max_sr = EXCLUDED.max_sr, channel_id = EXCLUDED.channel_id", l.Team, l.Region, l.MinSR, l.MaxSR, l.ChannelID)
+	return err
+}
+
+// RemoveLFSListing takes a team off the looking for scrim board.
+func (d *Handler) RemoveLFSListing(teamID int) error {
+	_, err := d.Exec("DELETE FROM lfs_listings WHERE team = $1", teamID)
+	return err
+}
+
+// AddScrimRequest sends a request for a scrim, and returns its ID.
+func (d *Handler) AddScrimRequest(r schedule.ScrimRequest) (id int, err error) {
+	err = d.QueryRow("INSERT INTO scrim_requests (from_team, to_team, starts, ends, status) VALUES ($1, $2, $3, $4, $5) RETURNING id", r.From, r.To, r.Start, r.End, r.Status).Scan(&id)
+	return
+}
+
+// ScrimRequest returns a request for a scrim, or sql.ErrNoRows if there isn't one with the ID.
+func (d *Handler) ScrimRequest(id int) (r schedule.ScrimRequest, err error) {
+	err = d.Get(&r, "SELECT * FROM scrim_requests WHERE id = $1", id)
+	return
+}
+
+// SetScrimRequestStatus accepts or declines a request for a scrim.
+func (d *Handler) SetScrimRequestStatus(id int, status schedule.ScrimStatus) error {
+	_, err := d.Exec("UPDATE scrim_requests SET status = $1 WHERE id = $2", status, id)
+	return err
+}
+
+// SetResults replaces the maps a team played in the block starting at a time.
+func (d *Handler) SetResults(teamID int, played time.Time, results []schedule.MapResult) error {
+	tx, err := d.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec("DELETE FROM map_results WHERE team = $1 AND played = $2", teamID, played)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		_, err = tx.Exec("INSERT INTO map_results (team, played, opponent, map, mode, score, opponent_score) VALUES ($1, $2, $3, $4, $5, $6, $7)", teamID, played, r.Opponent, r.Map, r.Mode, r.Score, r.OpponentScore)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Results returns a team's map results from a time on, oldest first.
+func (d *Handler) Results(teamID int, since time.Time) (results []schedule.MapResult, err error) {
+	err = d.Select(&results, "SELECT * FROM map_results WHERE team = $1 AND played >= $2 ORDER BY played, id", teamID, since)
+	return
+}
+
+// VoiceChannel returns the voice channel a team's attendance is taken in, or an empty string if it isn't taken.
+func (d *Handler) VoiceChannel(teamID int) (string, error) {
+	var channel sql.NullString
+	err := d.QueryRow("SELECT voice_channel FROM schedules WHERE team = $1", teamID).Scan(&channel)
+	return channel.String, err
+}
+
+// SetVoiceChannel sets the voice channel a team's attendance is taken in, or stops taking it if channelID is empty.
+func (d *Handler) SetVoiceChannel(teamID int, channelID string) error {
+	channel := sql.NullString{String: channelID, Valid: channelID != ""}
+	_, err := d.Exec("UPDATE schedules SET voice_channel = $1 WHERE team = $2", channel, teamID)
+	return err
+}
+
+// AddAttendance records whether a player was in voice during a block. Players already recorded as present for the
+// block stay present.
+func (d *Handler) AddAttendance(a schedule.Attendance) error {
+	_, err := d.Exec("INSERT INTO attendance (team, block, player, activity, availability, present) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (team, block, player) DO UPDATE SET activity = EXCLUDED.activity, availability = EXCLUDED.availability, present = attendance.present OR EXCLUDED.present", a.Team, a.Block, a.Player, a.Activity, a.Availability, a.Present)
+	return err
+}
+
+// Attendance returns a team's attendance for blocks starting from a time on, oldest first.
+func (d *Handler) Attendance(teamID int, since time.Time) (attendance []schedule.Attendance, err error) {
+	err = d.Select(&attendance, "SELECT * FROM attendance WHERE team = $1 AND block >= $2 ORDER BY block, player", teamID, since)
+	return
+}
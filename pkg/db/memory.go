@@ -0,0 +1,842 @@
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+)
+
+// Memory keeps thonky's Store in memory, so the bot and its tests can run without Postgres. Nothing in it is saved
+// between runs.
+type Memory struct {
+	mu sync.Mutex
+
+	teams      []team.Team
+	roles      map[int]team.Roles
+	links      map[int][]team.PlayerLink
+	apiTokens  map[string]int
+	schedules  map[int]*memorySchedule
+	posts      map[string]team.SchedulePost
+	cache      map[string]memoryCache
+	history    []memorySnapshot
+	defaults   map[string][]byte
+	reminders  map[int]team.ReminderConfig
+	nags       map[int]team.NagConfig
+	tournament map[string]map[int]team.Tournament
+	scrims     []schedule.Scrim
+	listings   map[int]team.LFSListing
+	requests   []schedule.ScrimRequest
+	results    []schedule.MapResult
+	resultID   int
+	attendance []schedule.Attendance
+}
+
+// memorySchedule is a team's row in the schedules table.
+type memorySchedule struct {
+	config         team.ScheduleConfig
+	changesChannel string
+	lineup         schedule.Lineup
+	starters       []string
+	calendarToken  string
+	voiceChannel   string
+}
+
+// memoryCache is a cached schedule, kept as JSON like it is in Postgres so it can't be changed from outside.
+type memoryCache struct {
+	modified        time.Time
+	players, week   []byte
+	validActivities []string
+}
+
+type memorySnapshot struct {
+	schedule.Snapshot
+	week, players []byte
+}
+
+// NewMemory returns an empty in-memory Store.
+func NewMemory() *Memory {
+	return &Memory{
+		roles:      make(map[int]team.Roles),
+		links:      make(map[int][]team.PlayerLink),
+		apiTokens:  make(map[string]int),
+		schedules:  make(map[int]*memorySchedule),
+		posts:      make(map[string]team.SchedulePost),
+		cache:      make(map[string]memoryCache),
+		defaults:   make(map[string][]byte),
+		reminders:  make(map[int]team.ReminderConfig),
+		nags:       make(map[int]team.NagConfig),
+		tournament: map[string]map[int]team.Tournament{team.Battlefy: {}, team.Gamebattles: {}},
+		listings:   make(map[int]team.LFSListing),
+	}
+}
+
+// NewTeam adds a team and returns it with its ID. Teams without a name are their guild's team.
+func (m *Memory) NewTeam(t team.Team) team.Team {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t.ID = len(m.teams) + 1
+	t.Channels = append(t.Channels[:0:0], t.Channels...)
+	m.teams = append(m.teams, t)
+	return t
+}
+
+// SetScheduleConfig sets where a team's schedule comes from, which Postgres only has set by hand.
+func (m *Memory) SetScheduleConfig(c team.ScheduleConfig) {
+	c.Layout = append(c.Layout[:0:0], c.Layout...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s := m.schedules[c.Team]; s != nil {
+		s.config = c
+		return
+	}
+	m.schedules[c.Team] = &memorySchedule{config: c}
+}
+
+// AddTeam adds a team to a guild in a channel.
+func (m *Memory) AddTeam(guildID, name, channel string) error {
+	m.NewTeam(team.Team{GuildID: guildID, Name: name, Channels: []string{channel}})
+	return nil
+}
+
+// findTeam returns the first team matching a filter. m.mu must be held.
+func (m *Memory) findTeam(match func(t team.Team) bool) (team.Team, error) {
+	for _, t := range m.teams {
+		if match(t) {
+			t.Channels = append(t.Channels[:0:0], t.Channels...)
+			return t, nil
+		}
+	}
+	return team.Team{}, sql.ErrNoRows
+}
+
+// GetName returns the name of a team in a given channel
+func (m *Memory) GetName(channelID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, err := m.findTeam(func(t team.Team) bool { return contains(t.Channels, channelID) })
+	return t.Name, err
+}
+
+// Team returns a team by its ID.
+func (m *Memory) Team(teamID int) (team.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.findTeam(func(t team.Team) bool { return t.ID == teamID })
+}
+
+// GuildTeams returns every team in a guild.
+func (m *Memory) GuildTeams(guildID string) (teams []team.Team, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.teams {
+		if t.GuildID == guildID {
+			t.Channels = append(t.Channels[:0:0], t.Channels...)
+			teams = append(teams, t)
+		}
+	}
+	return
+}
+
+// ChannelTeam returns the team in a channel in a guild.
+func (m *Memory) ChannelTeam(guildID, channelID string) (team.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.findTeam(func(t team.Team) bool { return t.GuildID == guildID && contains(t.Channels, channelID) })
+}
+
+// GuildTeam returns the team for a whole guild.
+func (m *Memory) GuildTeam(guildID string) (team.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.findTeam(func(t team.Team) bool { return t.GuildID == guildID && t.Guild() })
+}
+
+// SetTeamChannels sets the channels a team is in.
+func (m *Memory) SetTeamChannels(teamID int, channels []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.teams {
+		if m.teams[i].ID == teamID {
+			m.teams[i].Channels = append(channels[:0:0], channels...)
+		}
+	}
+	return nil
+}
+
+// TeamRoles returns the roles that grant permission levels on a team.
+// Teams without any roles configured get an empty Roles.
+func (m *Memory) TeamRoles(teamID int) (team.Roles, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.roles[teamID]; ok {
+		return r, nil
+	}
+	return team.Roles{Team: teamID}, nil
+}
+
+// SetTeamRoles updates the roles that grant permission levels on a team.
+func (m *Memory) SetTeamRoles(r team.Roles) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roles[r.Team] = r
+	return nil
+}
+
+// PlayerLinks returns every Discord user linked to a player on a team.
+func (m *Memory) PlayerLinks(teamID int) ([]team.PlayerLink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]team.PlayerLink(nil), m.links[teamID]...), nil
+}
+
+// PlayerLink returns the player a Discord user is linked to on a team, or sql.ErrNoRows if they aren't linked.
+func (m *Memory) PlayerLink(teamID int, userID string) (team.PlayerLink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, l := range m.links[teamID] {
+		if l.UserID == userID {
+			return l, nil
+		}
+	}
+	return team.PlayerLink{}, sql.ErrNoRows
+}
+
+// SetPlayerLink links a Discord user to a player on a team, replacing any link they had before.
+func (m *Memory) SetPlayerLink(l team.PlayerLink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	links := m.links[l.Team]
+	for i := range links {
+		if links[i].UserID == l.UserID {
+			links[i] = l
+			return nil
+		}
+	}
+	m.links[l.Team] = append(links, l)
+	return nil
+}
+
+// RemovePlayerLink unlinks a Discord user from their player on a team.
+func (m *Memory) RemovePlayerLink(teamID int, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var links []team.PlayerLink
+	for _, l := range m.links[teamID] {
+		if l.UserID != userID {
+			links = append(links, l)
+		}
+	}
+	m.links[teamID] = links
+	return nil
+}
+
+// AddAPIToken lets a token use the HTTP API for a team.
+func (m *Memory) AddAPIToken(teamID int, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apiTokens[hash] = teamID
+	return nil
+}
+
+// RemoveAPITokens stops every token for a team from using the HTTP API.
+func (m *Memory) RemoveAPITokens(teamID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for hash, id := range m.apiTokens {
+		if id == teamID {
+			delete(m.apiTokens, hash)
+		}
+	}
+	return nil
+}
+
+// APITokenTeam returns the team a token's hash can use the HTTP API for, or sql.ErrNoRows if it's not a token.
+func (m *Memory) APITokenTeam(hash string) (team.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.apiTokens[hash]
+	if !ok {
+		return team.Team{}, sql.ErrNoRows
+	}
+	return m.findTeam(func(t team.Team) bool { return t.ID == id })
+}
+
+// schedule returns a team's schedule, or sql.ErrNoRows if it doesn't have one. m.mu must be held.
+func (m *Memory) schedule(teamID int) (*memorySchedule, error) {
+	if s := m.schedules[teamID]; s != nil {
+		return s, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// ScheduleConfig returns where a team's schedule comes from and how it's read.
+func (m *Memory) ScheduleConfig(teamID int) (team.ScheduleConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.schedule(teamID)
+	if err != nil {
+		return team.ScheduleConfig{}, err
+	}
+	return s.config, nil
+}
+
+// SpreadsheetID returns the spreadsheet ID for the team with the given ID.
+func (m *Memory) SpreadsheetID(teamID int) (string, error) {
+	c, err := m.ScheduleConfig(teamID)
+	return c.SpreadsheetID, err
+}
+
+// updateSchedule changes a team's schedule, if it has one.
+func (m *Memory) updateSchedule(teamID int, update func(s *memorySchedule)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s := m.schedules[teamID]; s != nil {
+		update(s)
+	}
+	return nil
+}
+
+// SetLayout sets where everything is on a team's spreadsheet.
+func (m *Memory) SetLayout(teamID int, layout []byte) error {
+	return m.updateSchedule(teamID, func(s *memorySchedule) { s.config.Layout = append(layout[:0:0], layout...) })
+}
+
+// SetTimezone sets the IANA timezone a team's schedule is in.
+func (m *Memory) SetTimezone(teamID int, timezone string) error {
+	return m.updateSchedule(teamID, func(s *memorySchedule) { s.config.Timezone = timezone })
+}
+
+// SetChangesChannel sets the channel changes to a team's schedule are announced in, or stops announcing them if
+// channelID is empty.
+func (m *Memory) SetChangesChannel(teamID int, channelID string) error {
+	return m.updateSchedule(teamID, func(s *memorySchedule) { s.changesChannel = channelID })
+}
+
+// ChangesChannels returns the channels changes to a schedule are announced in, for all of the teams using it.
+func (m *Memory) ChangesChannels(spreadsheetID string) (channels []string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.sorted() {
+		if s.config.SpreadsheetID == spreadsheetID && s.changesChannel != "" {
+			channels = append(channels, s.changesChannel)
+		}
+	}
+	return
+}
+
+// sorted returns the teams' schedules in the order the teams were added. m.mu must be held.
+func (m *Memory) sorted() []*memorySchedule {
+	var schedules []*memorySchedule
+	for _, s := range m.schedules {
+		schedules = append(schedules, s)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].config.Team < schedules[j].config.Team })
+	return schedules
+}
+
+// SchedulePosts returns every message kept up to date with a schedule, for all of the teams using it.
+func (m *Memory) SchedulePosts(spreadsheetID string) (posts []team.SchedulePost, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.posts {
+		if s := m.schedules[p.Team]; s != nil && s.config.SpreadsheetID == spreadsheetID {
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ChannelID < posts[j].ChannelID })
+	return
+}
+
+// SetSchedulePost keeps a message up to date with a team's schedule, replacing any message kept in its channel before.
+func (m *Memory) SetSchedulePost(p team.SchedulePost) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.posts[p.ChannelID] = p
+	return nil
+}
+
+// RemoveSchedulePost stops keeping the message in a channel up to date.
+func (m *Memory) RemoveSchedulePost(channelID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.posts, channelID)
+	return nil
+}
+
+// Lineup returns how many players of each role a team plays with, or the default lineup if the team hasn't set one.
+func (m *Memory) Lineup(teamID int) (schedule.Lineup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.schedule(teamID)
+	if err != nil || s.lineup == nil {
+		return schedule.DefaultLineup, err
+	}
+	l := make(schedule.Lineup, len(s.lineup))
+	for role, n := range s.lineup {
+		l[role] = n
+	}
+	return l, nil
+}
+
+// SetLineup sets how many players of each role a team plays with.
+func (m *Memory) SetLineup(teamID int, l schedule.Lineup) error {
+	return m.updateSchedule(teamID, func(s *memorySchedule) {
+		s.lineup = make(schedule.Lineup, len(l))
+		for role, n := range l {
+			s.lineup[role] = n
+		}
+	})
+}
+
+// Starters returns the players a team prefers to start when picking lineups, in order.
+func (m *Memory) Starters(teamID int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.schedule(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), s.starters...), nil
+}
+
+// SetStarters sets the players a team prefers to start when picking lineups.
+func (m *Memory) SetStarters(teamID int, starters []string) error {
+	return m.updateSchedule(teamID, func(s *memorySchedule) { s.starters = append([]string(nil), starters...) })
+}
+
+// CalendarToken returns the secret in the URL of a team's calendar feed, or an empty string if it doesn't have one.
+func (m *Memory) CalendarToken(teamID int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.schedule(teamID)
+	if err != nil {
+		return "", err
+	}
+	return s.calendarToken, nil
+}
+
+// SetCalendarToken sets the secret in the URL of a team's calendar feed.
+func (m *Memory) SetCalendarToken(teamID int, token string) error {
+	return m.updateSchedule(teamID, func(s *memorySchedule) { s.calendarToken = token })
+}
+
+// CalendarTeam returns the team and spreadsheet ID of the schedule whose calendar feed has a token,
+// or sql.ErrNoRows if no feed does.
+func (m *Memory) CalendarTeam(token string) (team.Team, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.schedules {
+		if token != "" && s.calendarToken == token {
+			t, err := m.findTeam(func(t team.Team) bool { return t.ID == s.config.Team })
+			return t, s.config.SpreadsheetID, err
+		}
+	}
+	return team.Team{}, "", sql.ErrNoRows
+}
+
+// CacheSchedule adds a new schedule to the cache, or updates an existing cache for the schedule.
+func (m *Memory) CacheSchedule(s *schedule.Schedule) error {
+	players, err := json.Marshal(s.Players)
+	if err != nil {
+		return err
+	}
+	week, err := json.Marshal(s.Week)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache[s.ID] = memoryCache{
+		modified:        s.LastModified,
+		players:         players,
+		week:            week,
+		validActivities: append([]string(nil), s.ValidActivities...),
+	}
+
+	// like in Postgres, a snapshot is only saved if the week changed since the last snapshot of it
+	for i := len(m.history) - 1; i >= 0; i-- {
+		last := m.history[i]
+		if last.SpreadsheetID == s.ID && last.Date == s.Week.Date {
+			if bytes.Equal(last.week, week) && bytes.Equal(last.players, players) {
+				return nil
+			}
+			break
+		}
+	}
+	m.history = append(m.history, memorySnapshot{
+		Snapshot: schedule.Snapshot{ID: len(m.history) + 1, SpreadsheetID: s.ID, Date: s.Week.Date, Saved: time.Now()},
+		week:     week,
+		players:  players,
+	})
+	return nil
+}
+
+// CachedSchedule returns a cached schedule
+func (m *Memory) CachedSchedule(s *schedule.Schedule) error {
+	m.mu.Lock()
+	c, ok := m.cache[s.ID]
+	m.mu.Unlock()
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if err := json.Unmarshal(c.players, &s.Players); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(c.week, &s.Week); err != nil {
+		return err
+	}
+	s.ValidActivities = append([]string(nil), c.validActivities...)
+	return nil
+}
+
+// CacheModified returns when the cached copy of a schedule was last modified at its source.
+func (m *Memory) CacheModified(spreadsheetID string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.cache[spreadsheetID]
+	if !ok {
+		return time.Time{}, sql.ErrNoRows
+	}
+	return c.modified, nil
+}
+
+// Snapshots returns when each snapshot in a schedule's history was saved, newest first, without their weeks or players.
+func (m *Memory) Snapshots(spreadsheetID string) (snapshots []schedule.Snapshot, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.history) - 1; i >= 0; i-- {
+		if m.history[i].SpreadsheetID == spreadsheetID {
+			snapshots = append(snapshots, m.history[i].Snapshot)
+		}
+	}
+	return
+}
+
+// Snapshot returns a snapshot in a schedule's history, or sql.ErrNoRows if the schedule doesn't have it.
+func (m *Memory) Snapshot(spreadsheetID string, id int) (snap schedule.Snapshot, err error) {
+	m.mu.Lock()
+	if id < 1 || id > len(m.history) || m.history[id-1].SpreadsheetID != spreadsheetID {
+		m.mu.Unlock()
+		return snap, sql.ErrNoRows
+	}
+	h := m.history[id-1]
+	m.mu.Unlock()
+
+	snap = h.Snapshot
+	err = json.Unmarshal(h.week, &snap.Week)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(h.players, &snap.Players)
+	return
+}
+
+// DefaultWeek returns the week a schedule is reset to.
+func (m *Memory) DefaultWeek(spreadsheetID string) (w schedule.Week, err error) {
+	m.mu.Lock()
+	b, ok := m.defaults[spreadsheetID]
+	m.mu.Unlock()
+	if !ok {
+		return w, sql.ErrNoRows
+	}
+	err = json.Unmarshal(b, &w)
+	return
+}
+
+// SetDefaultWeek sets the week a schedule is reset to.
+func (m *Memory) SetDefaultWeek(spreadsheetID string, w schedule.Week) error {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaults[spreadsheetID] = b
+	return nil
+}
+
+// ReminderConfig returns the reminder config for a team, or an empty config if it doesn't have one.
+func (m *Memory) ReminderConfig(teamID int) (*team.ReminderConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.reminders[teamID]
+	if !ok {
+		return &team.ReminderConfig{Team: teamID}, nil
+	}
+	c.Activities = append(c.Activities[:0:0], c.Activities...)
+	c.Intervals = append(c.Intervals[:0:0], c.Intervals...)
+	return &c, nil
+}
+
+// SetReminderConfig saves a reminder config.
+func (m *Memory) SetReminderConfig(c *team.ReminderConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved := *c
+	saved.Activities = append(c.Activities[:0:0], c.Activities...)
+	saved.Intervals = append(c.Intervals[:0:0], c.Intervals...)
+	m.reminders[c.Team] = saved
+	return nil
+}
+
+// NagConfig returns the nag config for a team, or a config that never nags if it doesn't have one.
+func (m *Memory) NagConfig(teamID int) (*team.NagConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.nags[teamID]
+	if !ok {
+		return &team.NagConfig{Team: teamID, Days: 3}, nil
+	}
+	c.Hours = append(c.Hours[:0:0], c.Hours...)
+	return &c, nil
+}
+
+// SetNagConfig saves a nag config.
+func (m *Memory) SetNagConfig(c *team.NagConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved := *c
+	saved.Hours = append(c.Hours[:0:0], c.Hours...)
+	m.nags[c.Team] = saved
+	return nil
+}
+
+// Tournament returns the tournament a team is in on a site, or sql.ErrNoRows if they aren't in one there.
+func (m *Memory) Tournament(site string, teamID int) (team.Tournament, error) {
+	if _, err := tournamentTable(site); err != nil {
+		return team.Tournament{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tournament[site][teamID]
+	if !ok {
+		return team.Tournament{Site: site}, sql.ErrNoRows
+	}
+	return t, nil
+}
+
+// SetTournament sets the tournament a team is in on a site.
+func (m *Memory) SetTournament(t team.Tournament) error {
+	if _, err := tournamentTable(t.Site); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tournament[t.Site][t.Team] = t
+	return nil
+}
+
+// TournamentLinks returns the links to a team's Battlefy and GameBattles tournaments, or empty strings for ones they
+// aren't in.
+func (m *Memory) TournamentLinks(teamID int) (battlefy, gamebattles string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tournament[team.Battlefy][teamID].Link, m.tournament[team.Gamebattles][teamID].Link, nil
+}
+
+// AddScrim books a scrim, and returns its ID.
+func (m *Memory) AddScrim(s schedule.Scrim) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ID = len(m.scrims) + 1
+	m.scrims = append(m.scrims, s)
+	return s.ID, nil
+}
+
+// Scrim returns one of a team's scrims, or sql.ErrNoRows if the team doesn't have it.
+func (m *Memory) Scrim(teamID, id int) (schedule.Scrim, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || id > len(m.scrims) || m.scrims[id-1].Team != teamID {
+		return schedule.Scrim{}, sql.ErrNoRows
+	}
+	return m.scrims[id-1], nil
+}
+
+// Scrims returns a team's scrims that end after a time, soonest first.
+func (m *Memory) Scrims(teamID int, after time.Time) (scrims []schedule.Scrim, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.scrims {
+		if s.Team == teamID && s.End.After(after) {
+			scrims = append(scrims, s)
+		}
+	}
+	sort.SliceStable(scrims, func(i, j int) bool { return scrims[i].Start.Before(scrims[j].Start) })
+	return
+}
+
+// SetScrimStatus confirms or cancels a scrim.
+func (m *Memory) SetScrimStatus(teamID, id int, status schedule.ScrimStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id >= 1 && id <= len(m.scrims) && m.scrims[id-1].Team == teamID {
+		m.scrims[id-1].Status = status
+	}
+	return nil
+}
+
+// LFSListing returns a team's listing on the looking for scrim board, or sql.ErrNoRows if it isn't on it.
+func (m *Memory) LFSListing(teamID int) (team.LFSListing, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.listings[teamID]
+	if !ok {
+		return team.LFSListing{}, sql.ErrNoRows
+	}
+	return l, nil
+}
+
+// LFSListings returns every team on the looking for scrim board.
+func (m *Memory) LFSListings() (listings []team.LFSListing, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, l := range m.listings {
+		listings = append(listings, l)
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].Team < listings[j].Team })
+	return
+}
+
+// SetLFSListing puts a team on the looking for scrim board, or updates its listing.
+func (m *Memory) SetLFSListing(l team.LFSListing) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listings[l.Team] = l
+	return nil
+}
+
+// RemoveLFSListing takes a team off the looking for scrim board.
+func (m *Memory) RemoveLFSListing(teamID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.listings, teamID)
+	return nil
+}
+
+// AddScrimRequest sends a request for a scrim, and returns its ID.
+func (m *Memory) AddScrimRequest(r schedule.ScrimRequest) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.ID = len(m.requests) + 1
+	m.requests = append(m.requests, r)
+	return r.ID, nil
+}
+
+// ScrimRequest returns a request for a scrim, or sql.ErrNoRows if there isn't one with the ID.
+func (m *Memory) ScrimRequest(id int) (schedule.ScrimRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || id > len(m.requests) {
+		return schedule.ScrimRequest{}, sql.ErrNoRows
+	}
+	return m.requests[id-1], nil
+}
+
+// SetScrimRequestStatus accepts or declines a request for a scrim.
+func (m *Memory) SetScrimRequestStatus(id int, status schedule.ScrimStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id >= 1 && id <= len(m.requests) {
+		m.requests[id-1].Status = status
+	}
+	return nil
+}
+
+// SetResults replaces the maps a team played in the block starting at a time.
+func (m *Memory) SetResults(teamID int, played time.Time, results []schedule.MapResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.results[:0]
+	for _, r := range m.results {
+		if r.Team != teamID || !r.Played.Equal(played) {
+			kept = append(kept, r)
+		}
+	}
+	m.results = kept
+	for _, r := range results {
+		m.resultID++
+		r.ID, r.Team, r.Played = m.resultID, teamID, played
+		m.results = append(m.results, r)
+	}
+	return nil
+}
+
+// Results returns a team's map results from a time on, oldest first.
+func (m *Memory) Results(teamID int, since time.Time) (results []schedule.MapResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.results {
+		if r.Team == teamID && !r.Played.Before(since) {
+			results = append(results, r)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Played.Before(results[j].Played) })
+	return
+}
+
+// VoiceChannel returns the voice channel a team's attendance is taken in, or an empty string if it isn't taken.
+func (m *Memory) VoiceChannel(teamID int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.schedule(teamID)
+	if err != nil {
+		return "", err
+	}
+	return s.voiceChannel, nil
+}
+
+// SetVoiceChannel sets the voice channel a team's attendance is taken in, or stops taking it if channelID is empty.
+func (m *Memory) SetVoiceChannel(teamID int, channelID string) error {
+	return m.updateSchedule(teamID, func(s *memorySchedule) { s.voiceChannel = channelID })
+}
+
+// AddAttendance records whether a player was in voice during a block. Players already recorded as present for the
+// block stay present.
+func (m *Memory) AddAttendance(a schedule.Attendance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, recorded := range m.attendance {
+		if recorded.Team == a.Team && recorded.Block.Equal(a.Block) && recorded.Player == a.Player {
+			a.Present = a.Present || recorded.Present
+			m.attendance[i] = a
+			return nil
+		}
+	}
+	m.attendance = append(m.attendance, a)
+	return nil
+}
+
+// Attendance returns a team's attendance for blocks starting from a time on, oldest first.
+func (m *Memory) Attendance(teamID int, since time.Time) (attendance []schedule.Attendance, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range m.attendance {
+		if a.Team == teamID && !a.Block.Before(since) {
+			attendance = append(attendance, a)
+		}
+	}
+	sort.SliceStable(attendance, func(i, j int) bool {
+		if !attendance[i].Block.Equal(attendance[j].Block) {
+			return attendance[i].Block.Before(attendance[j].Block)
+		}
+		return attendance[i].Player < attendance[j].Player
+	})
+	return
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+)
+
+func TestMemoryTeams(t *testing.T) {
+	m := NewMemory()
+	guild := m.NewTeam(team.Team{GuildID: "1"})
+	if err := m.AddTeam("1", "Ascension", "10"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := m.GuildTeam("1"); err != nil || got.ID != guild.ID {
+		t.Errorf("wrong guild team: %+v (%v)", got, err)
+	}
+	got, err := m.ChannelTeam("1", "10")
+	if err != nil || got.Name != "Ascension" {
+		t.Fatalf("wrong team in channel: %+v (%v)", got, err)
+	}
+	if _, err := m.ChannelTeam("2", "10"); err != sql.ErrNoRows {
+		t.Errorf("expected no team in another guild's channel, got %v", err)
+	}
+
+	got.Channels = append(got.Channels, "11")
+	if _, err := m.ChannelTeam("1", "11"); err != sql.ErrNoRows {
+		t.Error("changing a team changed the stored team")
+	}
+	if err := m.SetTeamChannels(got.ID, got.Channels); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := m.GetName("11"); err != nil || name != "Ascension" {
+		t.Errorf("wrong team in added channel: %q (%v)", name, err)
+	}
+	if teams, _ := m.GuildTeams("1"); len(teams) != 2 {
+		t.Errorf("expected 2 teams in the guild, got %+v", teams)
+	}
+}
+
+func TestMemorySchedules(t *testing.T) {
+	m := NewMemory()
+	if _, err := m.SpreadsheetID(1); err != sql.ErrNoRows {
+		t.Errorf("expected no schedule, got %v", err)
+	}
+	if err := m.SetTimezone(1, "UTC"); err != nil {
+		t.Error("setting something on a missing schedule should do nothing:", err)
+	}
+
+	m.SetScheduleConfig(team.ScheduleConfig{Team: 1, SpreadsheetID: "sheet", Source: "file"})
+	m.SetScheduleConfig(team.ScheduleConfig{Team: 2, SpreadsheetID: "sheet", Source: "file"})
+	m.SetChangesChannel(1, "10")
+	m.SetChangesChannel(2, "20")
+	m.SetChangesChannel(2, "")
+	if channels, _ := m.ChangesChannels("sheet"); len(channels) != 1 || channels[0] != "10" {
+		t.Errorf("wrong changes channels: %v", channels)
+	}
+
+	if l, err := m.Lineup(1); err != nil || l["Tanks"] != schedule.DefaultLineup["Tanks"] {
+		t.Errorf("expected the default lineup, got %v (%v)", l, err)
+	}
+	m.SetStarters(1, []string{"Taub"})
+	if starters, _ := m.Starters(1); len(starters) != 1 || starters[0] != "Taub" {
+		t.Errorf("wrong starters: %v", starters)
+	}
+
+	m.NewTeam(team.Team{GuildID: "1", Name: "Ascension"})
+	m.SetCalendarToken(1, "secret")
+	if got, id, err := m.CalendarTeam("secret"); err != nil || got.Name != "Ascension" || id != "sheet" {
+		t.Errorf("wrong calendar team: %+v %q (%v)", got, id, err)
+	}
+	if _, _, err := m.CalendarTeam(""); err != sql.ErrNoRows {
+		t.Errorf("expected no team for an empty token, got %v", err)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	m := NewMemory()
+	s := &schedule.Schedule{ID: "sheet", ValidActivities: []string{"Scrim"}}
+	s.Week.Date = "10/8/2018"
+	s.Players = []schedule.Player{{Name: "Taub", Role: "Tanks"}}
+	if err := m.CachedSchedule(s); err != sql.ErrNoRows {
+		t.Errorf("expected nothing cached, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := m.CacheSchedule(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if snapshots, _ := m.Snapshots("sheet"); len(snapshots) != 1 {
+		t.Fatalf("caching the same week twice should save one snapshot, got %d", len(snapshots))
+	}
+	s.Players[0].Role = "Supports"
+	m.CacheSchedule(s)
+	snapshots, _ := m.Snapshots("sheet")
+	if len(snapshots) != 2 || snapshots[0].ID != 2 {
+		t.Fatalf("expected a new snapshot first, got %+v", snapshots)
+	}
+	snap, err := m.Snapshot("sheet", 1)
+	if err != nil || snap.Players[0].Role != "Tanks" {
+		t.Errorf("wrong snapshot: %+v (%v)", snap, err)
+	}
+	if _, err := m.Snapshot("other", 1); err != sql.ErrNoRows {
+		t.Errorf("expected another schedule's snapshot to be missing, got %v", err)
+	}
+
+	cached := &schedule.Schedule{ID: "sheet"}
+	if err := m.CachedSchedule(cached); err != nil {
+		t.Fatal(err)
+	}
+	if cached.Players[0].Role != "Supports" || len(cached.ValidActivities) != 1 {
+		t.Errorf("wrong cached schedule: %+v", cached)
+	}
+}
+
+func TestMemoryConfigs(t *testing.T) {
+	m := NewMemory()
+	nag, err := m.NagConfig(1)
+	if err != nil || nag.Days != 3 || len(nag.Hours) != 0 {
+		t.Errorf("expected a nag config that never nags, got %+v (%v)", nag, err)
+	}
+	nag.Hours = []int64{18}
+	m.SetNagConfig(nag)
+	nag.Hours[0] = 20
+	if saved, _ := m.NagConfig(1); saved.Hours[0] != 18 {
+		t.Errorf("changing a saved config changed the stored config: %v", saved.Hours)
+	}
+
+	if _, err := m.Tournament(team.Battlefy, 1); err != sql.ErrNoRows {
+		t.Errorf("expected no tournament, got %v", err)
+	}
+	if err := m.SetTournament(team.Tournament{Team: 1, Site: "challonge"}); err == nil {
+		t.Error("expected an error for an unknown site")
+	}
+	m.SetTournament(team.Tournament{Team: 1, Site: team.Gamebattles, Link: "https://gamebattles.majorleaguegaming.com/pc/overwatch/tournament/x"})
+	if battlefy, gamebattles, err := m.TournamentLinks(1); err != nil || battlefy != "" || gamebattles == "" {
+		t.Errorf("wrong tournament links: %q %q (%v)", battlefy, gamebattles, err)
+	}
+}
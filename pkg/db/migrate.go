@@ -0,0 +1,193 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// migrationFiles are the SQL files for each migration, named <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is a versioned change to the database schema, with the SQL to make it and to undo it.
+type Migration struct {
+	Version  int
+	Name     string
+	Up, Down string
+}
+
+var migrationRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var migrations, migrationsErr = loadMigrations()
+
+// loadMigrations reads the embedded migrations, making sure every version from 1 up has both directions.
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		b, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	var all []Migration
+	for _, m := range byVersion {
+		all = append(all, *m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	for i, m := range all {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("missing migration %d", i+1)
+		} else if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d (%s) needs both an up and a down file", m.Version, m.Name)
+		}
+	}
+	return all, nil
+}
+
+// Migrations returns every migration, oldest first.
+func Migrations() ([]Migration, error) {
+	return migrations, migrationsErr
+}
+
+// LatestVersion returns the schema version this build of thonky expects.
+func LatestVersion() int {
+	return len(migrations)
+}
+
+// SchemaVersion returns the version of the database's schema, which is the last migration applied to it.
+// Databases made from thonkydb.sql before there were migrations have the first migration's schema, so they're at
+// version 1, and empty databases are at version 0.
+func (d *Handler) SchemaVersion() (version int, err error) {
+	var tracked, legacy bool
+	err = d.QueryRow("SELECT to_regclass('public.schema_version') IS NOT NULL, to_regclass('public.teams') IS NOT NULL").Scan(&tracked, &legacy)
+	if err != nil || !tracked {
+		if legacy {
+			version = 1
+		}
+		return
+	}
+	err = d.Get(&version, "SELECT COALESCE(MAX(version), 0) FROM schema_version")
+	return
+}
+
+// Migrate applies or undoes migrations until the schema is at a version. Each migration runs in its own transaction,
+// so a failed migration leaves the schema at the version before it.
+func (d *Handler) Migrate(version int) error {
+	if migrationsErr != nil {
+		return migrationsErr
+	} else if version < 0 || version > LatestVersion() {
+		return fmt.Errorf("no schema version %d; the latest is %d", version, LatestVersion())
+	}
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	_, err = d.Exec("CREATE TABLE IF NOT EXISTS schema_version (version integer PRIMARY KEY, name text NOT NULL, applied timestamp with time zone DEFAULT now() NOT NULL)")
+	if err != nil {
+		return err
+	}
+	if current == 1 {
+		// record the schema made from thonkydb.sql, in case this is the first migration on it
+		_, err = d.Exec("INSERT INTO schema_version (version, name) VALUES (1, $1) ON CONFLICT DO NOTHING", migrations[0].Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for ; current < version; current++ {
+		if err = d.apply(migrations[current], true); err != nil {
+			return err
+		}
+	}
+	for ; current > version; current-- {
+		if err = d.apply(migrations[current-1], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs a migration up or down, and records the schema's new version.
+func (d *Handler) apply(m Migration, up bool) error {
+	direction, sql := "up", m.Up
+	if !up {
+		direction, sql = "down", m.Down
+	}
+	log.Printf("migrating %s: %d_%s\n", direction, m.Version, m.Name)
+
+	tx, err := d.Beginx()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(sql)
+	if err == nil {
+		if up {
+			_, err = tx.Exec("INSERT INTO schema_version (version, name) VALUES ($1, $2)", m.Version, m.Name)
+		} else {
+			_, err = tx.Exec("DELETE FROM schema_version WHERE version = $1", m.Version)
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error migrating %s %d_%s: %s", direction, m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
+
+// MigrateUp applies any migrations the database doesn't have yet, then makes sure its schema is the one this build
+// expects. Databases with a newer schema than this build are left alone with an error, since running against them
+// could lose data.
+func (d *Handler) MigrateUp() error {
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version < LatestVersion() {
+		if err = d.Migrate(LatestVersion()); err != nil {
+			return err
+		}
+	}
+	return d.CheckSchema()
+}
+
+// CheckSchema returns an error if the database's schema isn't at the version this build expects.
+func (d *Handler) CheckSchema() error {
+	if migrationsErr != nil {
+		return migrationsErr
+	}
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version != LatestVersion() {
+		return fmt.Errorf("database schema is at version %d, but this build of thonky2 needs version %d; run `thonky2 migrate to %d` or update thonky2", version, LatestVersion(), LatestVersion())
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	all, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) == 0 || LatestVersion() != len(all) {
+		t.Fatalf("expected migrations up to the latest version %d, got %d", LatestVersion(), len(all))
+	}
+
+	// every table a migration makes, undoing it should drop
+	createRe := regexp.MustCompile(`CREATE TABLE (\w+)`)
+	for _, m := range all {
+		for _, match := range createRe.FindAllStringSubmatch(m.Up, -1) {
+			if !regexp.MustCompile(`DROP TABLE ` + match[1] + `;`).MatchString(m.Down) {
+				t.Errorf("migration %d_%s makes table %s, but doesn't drop it going down", m.Version, m.Name, match[1])
+			}
+		}
+	}
+}
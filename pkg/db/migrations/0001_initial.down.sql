@@ -0,0 +1,7 @@
+DROP TABLE teams;
+DROP TABLE sheet_info;
+DROP TABLE schedules;
+DROP TABLE reminders;
+DROP TABLE gamebattles;
+DROP TABLE cache;
+DROP TABLE battlefy;
@@ -0,0 +1,50 @@
+-- The schema from thonkydb.sql, before migrations.
+
+CREATE TABLE battlefy (
+    team integer NOT NULL UNIQUE,
+    stage_id character(24),
+    team_id text,
+    tournament_link text
+);
+
+CREATE TABLE cache (
+    id character(44) PRIMARY KEY,
+    modified timestamp without time zone NOT NULL,
+    players json NOT NULL,
+    week json NOT NULL,
+    activities text[] NOT NULL
+);
+
+COMMENT ON COLUMN cache.id IS 'spreadsheet id';
+
+CREATE TABLE gamebattles (
+    team integer NOT NULL UNIQUE,
+    team_id text,
+    tournament_link text
+);
+
+CREATE TABLE reminders (
+    team integer NOT NULL UNIQUE,
+    intervals integer[],
+    activities text[],
+    announce_channel text,
+    role_mention text
+);
+
+CREATE TABLE schedules (
+    team integer NOT NULL UNIQUE,
+    spreadsheet_id text NOT NULL,
+    update_interval integer NOT NULL
+);
+
+CREATE TABLE sheet_info (
+    id character(44) NOT NULL,
+    default_week json
+);
+
+CREATE TABLE teams (
+    server_id bigint,
+    team_name text,
+    channels text[],
+    id serial NOT NULL
+);
@@ -0,0 +1,9 @@
+ALTER TABLE schedules
+    DROP COLUMN timezone,
+    DROP COLUMN layout,
+    DROP COLUMN source;
+
+COMMENT ON COLUMN cache.id IS 'spreadsheet id';
+
+ALTER TABLE sheet_info ALTER COLUMN id TYPE character(44);
+ALTER TABLE cache ALTER COLUMN id TYPE character(44);
@@ -0,0 +1,14 @@
+-- Schedules can be kept in files and laid out differently, so their IDs aren't always 44 character spreadsheet IDs.
+
+ALTER TABLE cache ALTER COLUMN id TYPE text;
+ALTER TABLE sheet_info ALTER COLUMN id TYPE text;
+
+COMMENT ON COLUMN cache.id IS 'schedule source id: a spreadsheet id or a file path';
+
+ALTER TABLE schedules
+    ADD COLUMN source text DEFAULT 'sheets' NOT NULL,
+    ADD COLUMN layout json,
+    ADD COLUMN timezone text;
+
+COMMENT ON COLUMN schedules.layout IS 'where everything is on the spreadsheet, null for the default layout';
+COMMENT ON COLUMN schedules.timezone IS 'IANA timezone the schedule is in, ex. America/Los_Angeles; null to read it from the sheet';
@@ -0,0 +1,6 @@
+CREATE TABLE team_roles (
+    team integer NOT NULL UNIQUE,
+    player_roles text[],
+    captain_roles text[],
+    admin_roles text[]
+);
@@ -0,0 +1,2 @@
+DROP TABLE nags;
+DROP TABLE player_links;
@@ -0,0 +1,15 @@
+CREATE TABLE player_links (
+    team integer NOT NULL,
+    user_id text NOT NULL,
+    player text NOT NULL,
+    dm_reminders boolean DEFAULT false NOT NULL,
+    UNIQUE (team, user_id)
+);
+
+CREATE TABLE nags (
+    team integer NOT NULL UNIQUE,
+    hours integer[],
+    days integer DEFAULT 3 NOT NULL,
+    channel text DEFAULT '' NOT NULL,
+    digest_channel text DEFAULT '' NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE schedules DROP COLUMN changes_channel;
+
+DROP TABLE schedule_posts;
@@ -0,0 +1,10 @@
+CREATE TABLE schedule_posts (
+    team integer NOT NULL,
+    channel_id text NOT NULL UNIQUE,
+    message_id text NOT NULL,
+    kind text DEFAULT 'week' NOT NULL
+);
+
+ALTER TABLE schedules ADD COLUMN changes_channel text;
+
+COMMENT ON COLUMN schedules.changes_channel IS 'channel changes to the sheet are announced in, null to not announce them';
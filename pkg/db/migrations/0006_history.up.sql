@@ -0,0 +1,12 @@
+CREATE TABLE history (
+    id serial PRIMARY KEY,
+    spreadsheet_id text NOT NULL,
+    date text NOT NULL,
+    saved timestamp with time zone DEFAULT now() NOT NULL,
+    week json NOT NULL,
+    players json NOT NULL
+);
+
+COMMENT ON TABLE history IS 'every version of each week on a schedule, appended whenever the cache changes';
+
+CREATE INDEX history_spreadsheet_id_date_idx ON history (spreadsheet_id, date);
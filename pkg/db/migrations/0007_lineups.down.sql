@@ -0,0 +1,3 @@
+ALTER TABLE schedules
+    DROP COLUMN starters,
+    DROP COLUMN lineup;
@@ -0,0 +1,5 @@
+ALTER TABLE schedules
+    ADD COLUMN lineup json,
+    ADD COLUMN starters text[];
+
+COMMENT ON COLUMN schedules.lineup IS 'how many players of each role the team plays with, ex. {"Tanks": 2}; null for 2 of each';
@@ -0,0 +1,3 @@
+DROP TABLE api_tokens;
+
+ALTER TABLE schedules DROP COLUMN calendar_token;
@@ -0,0 +1,11 @@
+ALTER TABLE schedules ADD COLUMN calendar_token text UNIQUE;
+
+COMMENT ON COLUMN schedules.calendar_token IS 'secret in the URL of the team''s calendar feed, null if it has none';
+
+CREATE TABLE api_tokens (
+    token_hash text PRIMARY KEY,
+    team integer NOT NULL,
+    created timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON COLUMN api_tokens.token_hash IS 'hex SHA-256 of the token, so tokens can''t be read back from the database';
@@ -0,0 +1,15 @@
+CREATE TABLE scrims (
+    id serial PRIMARY KEY,
+    team integer NOT NULL,
+    starts timestamp with time zone NOT NULL,
+    ends timestamp with time zone NOT NULL,
+    opponent text NOT NULL,
+    contact text DEFAULT '' NOT NULL,
+    region text DEFAULT '' NOT NULL,
+    maps text DEFAULT '' NOT NULL,
+    status text DEFAULT 'proposed' NOT NULL
+);
+
+COMMENT ON COLUMN scrims.status IS 'proposed, confirmed or cancelled';
+
+CREATE INDEX scrims_team_starts_idx ON scrims (team, starts);
@@ -0,0 +1,2 @@
+DROP TABLE scrim_requests;
+DROP TABLE lfs_listings;
@@ -0,0 +1,21 @@
+CREATE TABLE lfs_listings (
+    team integer PRIMARY KEY,
+    region text NOT NULL,
+    min_sr integer NOT NULL,
+    max_sr integer NOT NULL,
+    channel_id text NOT NULL
+);
+
+COMMENT ON TABLE lfs_listings IS 'teams looking for scrims against teams in other guilds';
+COMMENT ON COLUMN lfs_listings.channel_id IS 'channel scrim requests to the team are sent to';
+
+CREATE TABLE scrim_requests (
+    id serial PRIMARY KEY,
+    from_team integer NOT NULL,
+    to_team integer NOT NULL,
+    starts timestamp with time zone NOT NULL,
+    ends timestamp with time zone NOT NULL,
+    status text DEFAULT 'proposed' NOT NULL
+);
+
+COMMENT ON COLUMN scrim_requests.status IS 'proposed until to_team accepts (confirmed) or declines (cancelled)';
@@ -0,0 +1,15 @@
+CREATE TABLE map_results (
+    id serial PRIMARY KEY,
+    team integer NOT NULL,
+    played timestamp with time zone NOT NULL,
+    opponent text NOT NULL,
+    map text NOT NULL,
+    mode text DEFAULT '' NOT NULL,
+    score integer NOT NULL,
+    opponent_score integer NOT NULL
+);
+
+COMMENT ON COLUMN map_results.played IS 'when the block the map was played in started';
+COMMENT ON COLUMN map_results.mode IS 'mode of the map, empty if the map isn''t known';
+
+CREATE INDEX map_results_team_played_idx ON map_results (team, played);
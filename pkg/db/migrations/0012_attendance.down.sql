@@ -0,0 +1,3 @@
+DROP TABLE attendance;
+
+ALTER TABLE schedules DROP COLUMN voice_channel;
@@ -0,0 +1,16 @@
+ALTER TABLE schedules ADD COLUMN voice_channel text;
+
+COMMENT ON COLUMN schedules.voice_channel IS 'voice channel attendance is taken in, null if it isn''t taken';
+
+CREATE TABLE attendance (
+    team integer NOT NULL,
+    block timestamp with time zone NOT NULL,
+    player text NOT NULL,
+    activity text NOT NULL,
+    availability text DEFAULT '' NOT NULL,
+    present boolean DEFAULT false NOT NULL,
+    PRIMARY KEY (team, block, player)
+);
+
+COMMENT ON COLUMN attendance.block IS 'when the block attendance was taken for started';
+COMMENT ON COLUMN attendance.availability IS 'what the player put on the schedule for the block';
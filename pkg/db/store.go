@@ -0,0 +1,166 @@
+package db
+
+import (
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+)
+
+// Store is everything thonky keeps between restarts. Handler keeps it in Postgres, and Memory keeps it in memory for
+// tests and trying thonky out locally.
+// Getting one thing that doesn't exist returns sql.ErrNoRows, whichever backend it's kept in.
+type Store interface {
+	Teams
+	Schedules
+	Cache
+	Reminders
+	Tournaments
+	Scrims
+	LFS
+	Results
+	Attendance
+}
+
+// Teams keeps the teams in each guild and who can do what on them.
+type Teams interface {
+	// AddTeam adds a team to a guild in a channel.
+	AddTeam(guildID, name, channel string) error
+	// Team returns a team by its ID.
+	Team(teamID int) (team.Team, error)
+	// GetName returns the name of the team in a channel.
+	GetName(channelID string) (string, error)
+	// GuildTeams returns every team in a guild, including the guild's own team.
+	GuildTeams(guildID string) ([]team.Team, error)
+	// ChannelTeam returns the team in a channel in a guild.
+	ChannelTeam(guildID, channelID string) (team.Team, error)
+	// GuildTeam returns the team for a whole guild, which has no name.
+	GuildTeam(guildID string) (team.Team, error)
+	// SetTeamChannels sets the channels a team is in.
+	SetTeamChannels(teamID int, channels []string) error
+
+	TeamRoles(teamID int) (team.Roles, error)
+	SetTeamRoles(r team.Roles) error
+
+	PlayerLinks(teamID int) ([]team.PlayerLink, error)
+	PlayerLink(teamID int, userID string) (team.PlayerLink, error)
+	SetPlayerLink(l team.PlayerLink) error
+	RemovePlayerLink(teamID int, userID string) error
+
+	AddAPIToken(teamID int, hash string) error
+	RemoveAPITokens(teamID int) error
+	APITokenTeam(hash string) (team.Team, error)
+}
+
+// Schedules keeps where teams' schedules come from and the settings for them.
+type Schedules interface {
+	ScheduleConfig(teamID int) (team.ScheduleConfig, error)
+	SpreadsheetID(teamID int) (string, error)
+	// SetLayout sets where everything is on a team's spreadsheet, as JSON.
+	SetLayout(teamID int, layout []byte) error
+	SetTimezone(teamID int, timezone string) error
+	// SetChangesChannel sets the channel changes to a team's schedule are announced in, or stops announcing them if
+	// it's empty.
+	SetChangesChannel(teamID int, channelID string) error
+	ChangesChannels(spreadsheetID string) ([]string, error)
+
+	SchedulePosts(spreadsheetID string) ([]team.SchedulePost, error)
+	SetSchedulePost(p team.SchedulePost) error
+	RemoveSchedulePost(channelID string) error
+
+	Lineup(teamID int) (schedule.Lineup, error)
+	SetLineup(teamID int, l schedule.Lineup) error
+	Starters(teamID int) ([]string, error)
+	SetStarters(teamID int, starters []string) error
+
+	CalendarToken(teamID int) (string, error)
+	SetCalendarToken(teamID int, token string) error
+	CalendarTeam(token string) (t team.Team, spreadsheetID string, err error)
+}
+
+// Cache keeps copies of schedules, so they don't have to be read from their source on startup, and their history.
+type Cache interface {
+	CacheSchedule(s *schedule.Schedule) error
+	CachedSchedule(s *schedule.Schedule) error
+	// CacheModified returns when the cached copy of a schedule was last modified at its source.
+	CacheModified(spreadsheetID string) (time.Time, error)
+	Snapshots(spreadsheetID string) ([]schedule.Snapshot, error)
+	Snapshot(spreadsheetID string, id int) (schedule.Snapshot, error)
+
+	// DefaultWeek returns the week a schedule is reset to.
+	DefaultWeek(spreadsheetID string) (schedule.Week, error)
+	SetDefaultWeek(spreadsheetID string, w schedule.Week) error
+}
+
+// Reminders keeps when teams are reminded of activities and nagged to fill in their availability.
+type Reminders interface {
+	// ReminderConfig returns a team's reminder config, or an empty config if it doesn't have one.
+	ReminderConfig(teamID int) (*team.ReminderConfig, error)
+	SetReminderConfig(c *team.ReminderConfig) error
+	// NagConfig returns a team's nag config, or a config that never nags if it doesn't have one.
+	NagConfig(teamID int) (*team.NagConfig, error)
+	SetNagConfig(c *team.NagConfig) error
+}
+
+// Tournaments keeps the tournaments teams are in.
+type Tournaments interface {
+	// Tournament returns the tournament a team is in on a site.
+	Tournament(site string, teamID int) (team.Tournament, error)
+	SetTournament(t team.Tournament) error
+	// TournamentLinks returns the links to a team's Battlefy and GameBattles tournaments, or empty strings for ones
+	// they aren't in.
+	TournamentLinks(teamID int) (battlefy, gamebattles string, err error)
+}
+
+// Scrims keeps the scrims teams book.
+type Scrims interface {
+	// AddScrim books a scrim, and returns its ID.
+	AddScrim(s schedule.Scrim) (int, error)
+	// Scrim returns one of a team's scrims.
+	Scrim(teamID, id int) (schedule.Scrim, error)
+	// Scrims returns a team's scrims that end after a time, soonest first, including cancelled ones.
+	Scrims(teamID int, after time.Time) ([]schedule.Scrim, error)
+	SetScrimStatus(teamID, id int, status schedule.ScrimStatus) error
+}
+
+// LFS keeps the teams on the looking for scrim board, which teams in every guild can search, and the scrims they ask
+// each other for.
+type LFS interface {
+	LFSListing(teamID int) (team.LFSListing, error)
+	// LFSListings returns every team on the board.
+	LFSListings() ([]team.LFSListing, error)
+	SetLFSListing(l team.LFSListing) error
+	RemoveLFSListing(teamID int) error
+
+	// AddScrimRequest sends a request for a scrim, and returns its ID.
+	AddScrimRequest(r schedule.ScrimRequest) (int, error)
+	ScrimRequest(id int) (schedule.ScrimRequest, error)
+	// SetScrimRequestStatus accepts or declines a request for a scrim.
+	SetScrimRequestStatus(id int, status schedule.ScrimStatus) error
+}
+
+// Results keeps the scores of the maps teams play in scrims.
+type Results interface {
+	// SetResults replaces the maps a team played in the block starting at a time.
+	SetResults(teamID int, played time.Time, results []schedule.MapResult) error
+	// Results returns a team's map results from a time on, oldest first.
+	Results(teamID int, since time.Time) ([]schedule.MapResult, error)
+}
+
+// Attendance keeps which linked players were in their team's voice channel during each block.
+type Attendance interface {
+	// VoiceChannel returns the voice channel a team's attendance is taken in, or an empty string if it isn't taken.
+	VoiceChannel(teamID int) (string, error)
+	// SetVoiceChannel sets the voice channel a team's attendance is taken in, or stops taking it if it's empty.
+	SetVoiceChannel(teamID int, channelID string) error
+	// AddAttendance records whether a player was in voice during a block. Players already recorded as present for the
+	// block stay present.
+	AddAttendance(a schedule.Attendance) error
+	// Attendance returns a team's attendance for blocks starting from a time on, oldest first.
+	Attendance(teamID int, since time.Time) ([]schedule.Attendance, error)
+}
+
+var (
+	_ Store = (*Handler)(nil)
+	_ Store = (*Memory)(nil)
+)
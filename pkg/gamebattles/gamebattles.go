@@ -24,7 +24,23 @@ type Player struct {
 	UserID   uint
 	Username string
 	Gamertag string
-	active   bool `json:"active"`
+	active   bool
+}
+
+// UnmarshalJSON reads a player, along with whether they're active.
+func (p *Player) UnmarshalJSON(b []byte) error {
+	type player Player
+	var v struct {
+		player
+		Active bool `json:"active"`
+	}
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+	*p = Player(v.player)
+	p.active = v.Active
+	return nil
 }
 
 func (p Player) Battletag() string {
@@ -1,6 +1,9 @@
 package gamebattles
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 const (
 	teamID             = "33834248"
@@ -37,3 +40,17 @@ func TestGetTournamentID(t *testing.T) {
 		t.Fatalf("mismatched IDs: %s != %s", id, teamID)
 	}
 }
+
+func TestPlayerActive(t *testing.T) {
+	var players []Player
+	err := json.Unmarshal([]byte(`[{"username": "Tydra", "gamertag": "Tydra#11863.", "active": true}, {"username": "Taub"}]`), &players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if players[0].Username != "Tydra" || players[0].Battletag() != "Tydra#11863" || !players[0].Active() {
+		t.Errorf("wrong player: %+v", players[0])
+	}
+	if players[1].Active() {
+		t.Errorf("expected %s not to be active", players[1].Username)
+	}
+}
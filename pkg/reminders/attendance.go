@@ -0,0 +1,96 @@
+package reminders
+
+import (
+	"log"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/db"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/robfig/cron/v3"
+)
+
+// Attendance takes attendance in a team's voice channel during blocks with an activity on.
+type Attendance struct {
+	State *state.State
+	Team  *team.Team
+	// Channel is the voice channel attendance is taken in.
+	Channel string
+}
+
+// Run records which linked players are in the team's voice channel, if a block with an activity is on now.
+func (a Attendance) Run() {
+	sched := a.State.TeamSchedule(a.Team.ID)
+	if sched == nil {
+		return
+	}
+	links, err := a.State.DB.PlayerLinks(a.Team.ID)
+	if err != nil {
+		log.Printf("error grabbing linked players for team %d: %s\n", a.Team.ID, err)
+		return
+	}
+	present := a.State.Voice.In(a.Team.GuildID, a.Channel)
+	_, err = TakeAttendance(a.State.DB, a.Team.ID, sched, links, present, sched.Week.Now())
+	if err != nil {
+		log.Printf("error taking attendance for team %d: %s\n", a.Team.ID, err)
+	}
+}
+
+// TakeAttendance records whether each linked player is present, by user ID, for the block on at a time, along with
+// what they said their availability for it was. Nothing is recorded if no block with an activity is on, and taken is
+// false.
+func TakeAttendance(store db.Attendance, teamID int, sched *schedule.Schedule, links []team.PlayerLink, present map[string]bool, now time.Time) (taken bool, err error) {
+	week := &sched.Week
+	day, block, ok := blockOn(week, now)
+	if !ok || !week.Scheduled(day, block) {
+		return false, nil
+	}
+	for _, link := range links {
+		a := schedule.Attendance{
+			Team:     teamID,
+			Block:    week.BlockStart(day, block),
+			Player:   link.Player,
+			Activity: week.ActivitiesOn(day)[block],
+			Present:  present[link.UserID],
+		}
+		for _, p := range sched.Players {
+			if p.Name == link.Player {
+				a.Availability = p.AvailabilityOn(day)[block]
+				break
+			}
+		}
+		err = store.AddAttendance(a)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// blockOn returns the block on a week that's on at a time.
+func blockOn(week *schedule.Week, t time.Time) (day, block int, ok bool) {
+	for day := range week.Container {
+		for block := range week.Container[day] {
+			if !t.Before(week.BlockStart(day, block)) && t.Before(week.BlockEnd(day, block)) {
+				return day, block, true
+			}
+		}
+	}
+	return -1, -1, false
+}
+
+// AddAttendance adds a team's attendance taking to the scheduler, replacing the one it had before, or stops taking it
+// if it doesn't have a channel. Attendance is taken every 5 minutes, so players who join late or drop out early still
+// count.
+func AddAttendance(a Attendance) error {
+	specs := make(map[string]cron.Job)
+	if a.Channel != "" {
+		specs["0 */5 * * * *"] = a
+	}
+	err := replaceJobs(jobKey{"attendance", a.Team.ID}, specs)
+	if err != nil {
+		log.Printf("error adding attendance for team %d: %s\n", a.Team.ID, err)
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+package reminders
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/state"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/robfig/cron/v3"
+)
+
+// Nag pings players who haven't filled in their availability for the coming days.
+type Nag struct {
+	State  *state.State
+	Team   *team.Team
+	Config *team.NagConfig
+}
+
+// Missing is a player and the blocks they haven't filled in.
+type Missing struct {
+	Player string
+	// Blocks are the blocks left empty on each day, by day.
+	Blocks map[int][]int
+}
+
+// Count returns how many blocks are missing.
+func (m Missing) Count() (n int) {
+	for _, blocks := range m.Blocks {
+		n += len(blocks)
+	}
+	return
+}
+
+// Summary lists the blocks that are missing, ex. "Wednesday 4pm, 5pm; Thursday 4pm".
+func (m Missing) Summary(week *schedule.Week) string {
+	var days []int
+	for day := range m.Blocks {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+
+	var summary []string
+	for _, day := range days {
+		var hours []string
+		for _, block := range m.Blocks[day] {
+			hours = append(hours, week.BlockStart(day, block).Format("3pm"))
+		}
+		summary = append(summary, week.WeekdayOf(day).String()+" "+strings.Join(hours, ", "))
+	}
+	return strings.Join(summary, "; ")
+}
+
+// FindMissing returns the players with empty availability for blocks that haven't started yet,
+// from now until the end of the given amount of days. Days past the end of the week aren't checked.
+func FindMissing(week *schedule.Week, players []schedule.Player, now time.Time, days int) []Missing {
+	today := week.Weekday(int(now.Weekday()))
+	var missing []Missing
+	for _, p := range players {
+		m := Missing{Player: p.Name, Blocks: make(map[int][]int)}
+		for day := today; day < today+days && day < len(p.Container); day++ {
+			for block, availability := range p.AvailabilityOn(day) {
+				if availability == "" && week.BlockStart(day, block).After(now) {
+					m.Blocks[day] = append(m.Blocks[day], block)
+				}
+			}
+		}
+		if len(m.Blocks) > 0 {
+			missing = append(missing, m)
+		}
+	}
+	return missing
+}
+
+// Run nags missing players if it's one of the team's hours to, and sends managers a digest.
+func (n Nag) Run() {
+	spreadsheetID, err := n.State.DB.SpreadsheetID(n.Team.ID)
+	if err != nil {
+		log.Printf("error grabbing spreadsheet id for team %d: %s\n", n.Team.ID, err)
+		return
+	}
+	sched := n.State.Schedules[spreadsheetID]
+	if sched == nil {
+		return
+	}
+	now := sched.Week.Now()
+	if indexInt64(n.Config.Hours, int64(now.Hour())) == -1 {
+		return
+	}
+
+	links, err := n.State.DB.PlayerLinks(n.Team.ID)
+	if err != nil {
+		log.Printf("error grabbing linked players for team %d: %s\n", n.Team.ID, err)
+		return
+	}
+	missing := FindMissing(&sched.Week, sched.Players, now, n.Config.Days)
+	SendNags(n.State.Messenger, &sched.Week, missing, links, n.Config)
+	log.Printf("nagged %d players on team %d\n", len(missing), n.Team.ID)
+}
+
+// SendNags pings or DMs missing players with the blocks they need to fill in, and sends a digest to managers.
+func SendNags(m state.Messenger, week *schedule.Week, missing []Missing, links []team.PlayerLink, config *team.NagConfig) {
+	users := make(map[string]string)
+	for _, link := range links {
+		users[link.Player] = link.UserID
+	}
+
+	var pings []string
+	for _, mi := range missing {
+		if config.Channel != "" {
+			name := mi.Player
+			if id, ok := users[mi.Player]; ok {
+				name = "<@" + id + ">"
+			}
+			pings = append(pings, fmt.Sprintf("%s: %s", name, mi.Summary(week)))
+			continue
+		}
+
+		id, ok := users[mi.Player]
+		if !ok {
+			continue
+		}
+		channel, err := m.UserChannelCreate(id)
+		if err != nil {
+			log.Printf("error opening DM with %s: %s\n", id, err)
+			continue
+		}
+		msg := fmt.Sprintf("You haven't filled in your availability for %s. Let your team know with `!set %q <day> <time range> <yes, maybe or no>`.", mi.Summary(week), mi.Player)
+		if _, err = m.ChannelMessageSend(channel.ID, msg); err != nil {
+			log.Printf("error nagging %s: %s\n", id, err)
+		}
+	}
+	if len(pings) > 0 {
+		m.ChannelMessageSend(config.Channel, "Fill in your availability for:\n"+strings.Join(pings, "\n"))
+	}
+
+	if config.DigestChannel == "" {
+		return
+	}
+	digest := "Everyone's filled in their availability. :)"
+	if len(missing) > 0 {
+		var lines []string
+		for _, mi := range missing {
+			line := fmt.Sprintf("%s: %d blocks", mi.Player, mi.Count())
+			if _, ok := users[mi.Player]; !ok && config.Channel == "" {
+				line += " (not linked, so they weren't messaged)"
+			}
+			lines = append(lines, line)
+		}
+		digest = "Still missing availability:\n" + strings.Join(lines, "\n")
+	}
+	m.ChannelMessageSend(config.DigestChannel, digest)
+}
+
+// AddNag adds a team's nag to the scheduler, replacing the one it had before.
+// Nags are checked every hour, since the hours to send them at are in the team's timezone.
+func AddNag(n Nag) error {
+	specs := make(map[string]cron.Job)
+	if len(n.Config.Hours) > 0 {
+		specs["0 0 * * * *"] = n
+	}
+	err := replaceJobs(jobKey{"nag", n.Team.ID}, specs)
+	if err != nil {
+		log.Printf("error adding nag for team %d: %s\n", n.Team.ID, err)
+	}
+	return err
+}
+
+func indexInt64(s []int64, v int64) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
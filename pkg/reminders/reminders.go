@@ -1,93 +1,221 @@
 package reminders
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/bigheadgeorge/thonky2/pkg/command"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
 	"github.com/bigheadgeorge/thonky2/pkg/state"
 	"github.com/bigheadgeorge/thonky2/pkg/team"
-	"github.com/lib/pq"
-	"github.com/robfig/cron"
+	"github.com/bwmarrin/discordgo"
+	"github.com/robfig/cron/v3"
 )
 
-var scheduler *cron.Cron
+var (
+	scheduler *cron.Cron
 
-// Config holds a team's reminder configuration.
-type Config struct {
-	Team            int            `db:"team"`
-	Activities      pq.StringArray `db:"activities"`
-	AnnounceChannel string         `db:"announce_channel"`
-	RoleMention     sql.NullString `db:"role_mention"`
-	Intervals       pq.Int64Array  `db:"intervals"`
+	// jobs holds the scheduler entries for each team's jobs, so they can be replaced when the config changes.
+	jobs   = make(map[jobKey][]cron.EntryID)
+	jobsMu sync.Mutex
+)
+
+// jobKey identifies one kind of job for a team.
+type jobKey struct {
+	kind string
+	team int
 }
 
 // Reminder will check if there's an activity coming up that needs pinging
 type Reminder struct {
 	State  *state.State
 	Team   *team.Team
-	Config *Config
+	Config *team.ReminderConfig
 
 	time int
 }
 
-// Run checks if there's an activity coming up, and sends an announcement if it's the first one of the day
+// Run checks if there's a tracked activity coming up, and sends an announcement and DMs to linked players if there is.
 func (r Reminder) Run() {
-	var activities pq.StringArray
-	err := r.State.DB.QueryRow("SELECT activities FROM schedules WHERE team = $1", r.Team.ID).Scan(&activities)
+	spreadsheetID, err := r.State.DB.SpreadsheetID(r.Team.ID)
 	if err != nil {
-		log.Printf("error grabbing reminder activities for team %d: %s\n", r.Team.ID, err)
+		log.Printf("error grabbing spreadsheet id for team %d: %s\n", r.Team.ID, err)
 		return
 	}
-	spreadsheetID, err := r.State.DB.SpreadsheetID(r.Team.ID)
+	sched := r.State.Schedules[spreadsheetID]
+	if sched == nil {
+		return
+	}
+
+	// the reminder runs r.time minutes into the hour, so the block it's for starts within the next hour
+	day, block, ok := r.upcoming(&sched.Week, sched.Week.Now(), time.Hour)
+	if !ok {
+		return
+	}
+	activity := sched.Week.ActivitiesOn(day)[block]
+	minutes := int(sched.Week.BlockStart(day, block).Sub(sched.Week.Now()).Round(time.Minute).Minutes())
+
+	if r.Config.AnnounceChannel != "" {
+		announcement := fmt.Sprintf("%s in %d minutes", activity, minutes)
+		if r.Config.RoleMention.Valid {
+			announcement = fmt.Sprintf("%s %s", r.Config.RoleMention.String, announcement)
+		}
+
+		r.State.Messenger.ChannelMessageSend(r.Config.AnnounceChannel, announcement)
+
+		announceLog := fmt.Sprintf("send announcement for %q in [%s]", activity, r.Team.GuildID)
+		if !r.Team.Guild() {
+			announceLog += fmt.Sprintf("for %q", r.Team.Name)
+		}
+		log.Println(announceLog)
+	}
+
+	links, err := r.State.DB.PlayerLinks(r.Team.ID)
 	if err != nil {
-		log.Printf("error grabbing spreadsheet id for team %d: %s\n", r.Team.ID, err)
+		log.Printf("error grabbing linked players for team %d: %s\n", r.Team.ID, err)
 		return
 	}
-	week := r.State.Schedules[spreadsheetID].Week
+	SendPlayerReminders(r.State.Messenger, sched, links, day, block, minutes)
+}
+
+// upcoming returns the first block with a tracked activity that starts within d after now.
+func (r Reminder) upcoming(week *schedule.Week, now time.Time, d time.Duration) (day, block int, ok bool) {
+	for _, b := range blocksWithin(week, now, d) {
+		activity := week.ActivitiesOn(b[0])[b[1]]
+		for _, tracked := range r.Config.Activities {
+			if activity == tracked {
+				return b[0], b[1], true
+			}
+		}
+	}
+	return -1, -1, false
+}
+
+// SendPlayerReminders DMs every linked player who opted in and said they're available for a block.
+// Players who said Maybe are asked to confirm with Yes and No buttons, which are handled by the "remind" component.
+func SendPlayerReminders(m state.Messenger, sched *schedule.Schedule, links []team.PlayerLink, day, block, minutes int) {
+	activity := sched.Week.ActivitiesOn(day)[block]
+	for _, link := range links {
+		if !link.DMReminders {
+			continue
+		}
+		var availability string
+		for _, p := range sched.Players {
+			if p.Name == link.Player {
+				availability = p.AvailabilityOn(day)[block]
+				break
+			}
+		}
 
-	today := time.Now()
-	for i, activity := range week.ActivitiesOn(week.Weekday(int(today.Weekday()))) {
-		if i != today.Hour()-15 {
+		msg := &discordgo.MessageSend{Content: fmt.Sprintf("%s in %d minutes.", activity, minutes)}
+		switch availability {
+		case "Yes":
+		case "Maybe":
+			msg.Content = fmt.Sprintf("%s in %d minutes, and you said maybe. Are you coming?", activity, minutes)
+			msg.Components = confirmButtons(&sched.Week, link.Team, day, block)
+		default:
+			continue
+		}
+
+		channel, err := m.UserChannelCreate(link.UserID)
+		if err != nil {
+			log.Printf("error opening DM with %s: %s\n", link.UserID, err)
 			continue
 		}
-		for _, reminder := range activities {
-			if activity == reminder {
-				announcement := fmt.Sprintf("%s in %d minutes", activity, 60-r.time)
-				if r.Config.RoleMention.Valid {
-					announcement = fmt.Sprintf("%s %s", r.Config.RoleMention.Value, announcement)
-				}
-
-				r.State.Session.ChannelMessageSend(r.Config.AnnounceChannel, announcement)
-
-				announceLog := fmt.Sprintf("send announcement for %q in [%s]", activity, r.Team.GuildID)
-				if !r.Team.Guild() {
-					announceLog += fmt.Sprintf("for %q", r.Team.Name)
-				}
-				log.Println(announceLog)
-				return
+		_, err = m.ChannelMessageSendComplex(channel.ID, msg)
+		if err != nil {
+			log.Printf("error sending reminder to %s: %s\n", link.UserID, err)
+			continue
+		}
+		log.Printf("sent reminder for %q to %s (%s)\n", activity, link.Player, link.UserID)
+	}
+}
+
+// confirmButtons returns the buttons a player who said maybe to a block can answer Yes or No with. Their custom IDs
+// are the team, the date of the day the block is on, the day, the block and the answer.
+func confirmButtons(week *schedule.Week, teamID, day, block int) []discordgo.MessageComponent {
+	row := discordgo.ActionsRow{}
+	for _, answer := range []struct {
+		value string
+		style discordgo.ButtonStyle
+	}{{"Yes", discordgo.SuccessButton}, {"No", discordgo.DangerButton}} {
+		row.Components = append(row.Components, discordgo.Button{
+			Label:    answer.value,
+			Style:    answer.style,
+			CustomID: command.ComponentID("remind", strconv.Itoa(teamID), week.DayDate(day).Format("0102"), strconv.Itoa(day), strconv.Itoa(block), answer.value),
+		})
+	}
+	return []discordgo.MessageComponent{row}
+}
+
+// blocksWithin returns the blocks starting in the given amount of time after now, as a day and block, soonest first.
+func blocksWithin(week *schedule.Week, now time.Time, d time.Duration) [][2]int {
+	var blocks [][2]int
+	for day := range week.Container {
+		for block := range week.Container[day] {
+			until := week.BlockStart(day, block).Sub(now)
+			if until > 0 && until <= d {
+				blocks = append(blocks, [2]int{day, block})
 			}
 		}
 	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return week.BlockStart(blocks[i][0], blocks[i][1]).Before(week.BlockStart(blocks[j][0], blocks[j][1]))
+	})
+	return blocks
 }
 
-// AddReminder adds a reminder to the scheduler.
+// AddReminder adds a reminder to the scheduler, replacing any reminders the team already had.
+// Reminders run every hour, since blocks can be at any time of day in the team's timezone.
 func AddReminder(r Reminder) error {
+	specs := make(map[string]cron.Job)
 	for _, time := range r.Config.Intervals {
 		r.time = int(time)
-		err := scheduler.AddJob(fmt.Sprintf("0 %d 13-23 * * *", time), r)
+		specs[fmt.Sprintf("0 %d * * * *", time)] = r
+	}
+	err := replaceJobs(jobKey{"reminders", r.Team.ID}, specs)
+	if err != nil {
+		log.Printf("error adding reminders for team %d: %s\n", r.Team.ID, err)
+	}
+	return err
+}
+
+// RemoveReminders removes a team's reminders from the scheduler.
+func RemoveReminders(teamID int) {
+	replaceJobs(jobKey{"reminders", teamID}, nil)
+}
+
+// replaceJobs removes a team's jobs of one kind from the scheduler and adds new ones, keyed by their specs.
+// If any of the new jobs can't be added, none of them are.
+func replaceJobs(key jobKey, specs map[string]cron.Job) error {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	for _, id := range jobs[key] {
+		scheduler.Remove(id)
+	}
+	delete(jobs, key)
+
+	for spec, job := range specs {
+		id, err := scheduler.AddJob(spec, job)
 		if err != nil {
-			log.Println("error adding reminders for team %d, interval %d: %s", r.Team.ID, time, err)
-			return err
+			for _, id := range jobs[key] {
+				scheduler.Remove(id)
+			}
+			delete(jobs, key)
+			return fmt.Errorf("invalid schedule %q: %s", spec, err)
 		}
+		jobs[key] = append(jobs[key], id)
 	}
 	return nil
 }
 
 // Init initializes the reminder scheduler.
 func Init() {
-	scheduler = cron.New()
+	scheduler = cron.New(cron.WithSeconds())
 }
 
 // Start starts the reminder scheduler.
@@ -0,0 +1,191 @@
+package reminders
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/spreadsheet"
+	"github.com/bigheadgeorge/thonky2/pkg/command/commandtest"
+	"github.com/bigheadgeorge/thonky2/pkg/db"
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"github.com/bigheadgeorge/thonky2/pkg/team"
+	"github.com/bwmarrin/discordgo"
+)
+
+// grid makes a container with the same values every day.
+func grid(values ...string) schedule.Container {
+	c := make(schedule.Container, 7)
+	for i := range c {
+		for j, v := range values {
+			c[i] = append(c[i], &spreadsheet.Cell{Row: uint(i), Column: uint(j), Value: v})
+		}
+	}
+	return c
+}
+
+func TestSendPlayerReminders(t *testing.T) {
+	sched := &schedule.Schedule{
+		Week: schedule.Week{
+			Days:        [7]string{"Monday, 10/08", "Tuesday, 10/09", "Wednesday, 10/10", "Thursday, 10/11", "Friday, 10/12", "Saturday, 10/13", "Sunday, 10/14"},
+			Location:    time.UTC,
+			StartTime:   16,
+			BlockLength: 1,
+			Container:   grid("Free", "Scrim"),
+		},
+		Players: []schedule.Player{
+			{Name: "Taub", Container: grid("Yes", "Yes")},
+			{Name: "Tydra", Container: grid("Yes", "Maybe")},
+			{Name: "Lyar", Container: grid("Yes", "No")},
+			{Name: "Nub", Container: grid("Yes", "Yes")},
+		},
+	}
+	links := []team.PlayerLink{
+		{UserID: "1", Player: "Taub", DMReminders: true},
+		{UserID: "2", Player: "Tydra", DMReminders: true},
+		{UserID: "3", Player: "Lyar", DMReminders: true},
+		{UserID: "4", Player: "Nub"},
+	}
+
+	m := commandtest.NewMessenger()
+	SendPlayerReminders(m, sched, links, 2, 1, 15)
+	sent := m.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("wrong amount of DMs sent: %d != 2: %+v", len(sent), sent)
+	}
+	if sent[0].ChannelID != commandtest.DMChannel("1") || sent[0].Content != "Scrim in 15 minutes." {
+		t.Errorf("wrong reminder for Taub: %+v", sent[0])
+	}
+	if sent[1].ChannelID != commandtest.DMChannel("2") || !strings.Contains(sent[1].Content, "you said maybe") || len(sent[1].Components) != 1 {
+		t.Fatalf("wrong prompt for Tydra: %+v", sent[1])
+	}
+	yes := sent[1].Components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	if yes.Label != "Yes" || yes.CustomID != "remind:0:1010:2:1:Yes" {
+		t.Errorf("wrong Yes button for Tydra: %+v", yes)
+	}
+}
+
+// thisWeek returns the days of the current week starting on Monday, and when it starts, in UTC.
+func thisWeek() ([7]string, time.Time) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day()-(int(now.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	var days [7]string
+	for i := range days {
+		days[i] = start.AddDate(0, 0, i).Format("Monday, 01/02")
+	}
+	return days, start
+}
+
+func TestFindMissing(t *testing.T) {
+	days, monday := thisWeek()
+	week := &schedule.Week{Days: days, Location: time.UTC, StartTime: 16, BlockLength: 1, Container: grid("Free", "Scrim")}
+	players := []schedule.Player{
+		{Name: "Taub", Container: grid("Yes", "Yes")},
+		{Name: "Tydra", Container: grid("", "")},
+		{Name: "Lyar", Container: grid("Yes", "")},
+	}
+
+	// Tuesday's first block has already started, so it shouldn't count.
+	now := monday.AddDate(0, 0, 1).Add(16*time.Hour + 30*time.Minute)
+	missing := FindMissing(week, players, now, 2)
+	if len(missing) != 2 {
+		t.Fatalf("wrong amount of missing players: %d != 2: %+v", len(missing), missing)
+	}
+	if missing[0].Player != "Tydra" || missing[0].Count() != 3 {
+		t.Errorf("wrong blocks for Tydra: %+v", missing[0])
+	}
+	if summary := missing[0].Summary(week); summary != "Tuesday 5pm; Wednesday 4pm, 5pm" {
+		t.Errorf("wrong summary for Tydra: %q", summary)
+	}
+	if missing[1].Player != "Lyar" || missing[1].Count() != 2 {
+		t.Errorf("wrong blocks for Lyar: %+v", missing[1])
+	}
+}
+
+func TestSendNags(t *testing.T) {
+	days, _ := thisWeek()
+	week := &schedule.Week{Days: days, Location: time.UTC, StartTime: 16, BlockLength: 1, Container: grid("Free")}
+	missing := []Missing{
+		{Player: "Tydra", Blocks: map[int][]int{0: {0}}},
+		{Player: "Lyar", Blocks: map[int][]int{0: {0}, 1: {0}}},
+	}
+	links := []team.PlayerLink{{UserID: "2", Player: "Tydra"}}
+
+	m := commandtest.NewMessenger()
+	SendNags(m, week, missing, links, &team.NagConfig{DigestChannel: "managers"})
+	sent := m.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("wrong amount of messages sent: %d != 2: %+v", len(sent), sent)
+	}
+	if sent[0].ChannelID != commandtest.DMChannel("2") || !strings.Contains(sent[0].Content, "Monday 4pm") {
+		t.Errorf("wrong DM for Tydra: %+v", sent[0])
+	}
+	if sent[1].ChannelID != "managers" || !strings.Contains(sent[1].Content, "Lyar: 2 blocks (not linked") {
+		t.Errorf("wrong digest: %+v", sent[1])
+	}
+
+	m = commandtest.NewMessenger()
+	SendNags(m, week, missing, links, &team.NagConfig{Channel: "availability"})
+	sent = m.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("wrong amount of messages sent: %d != 1: %+v", len(sent), sent)
+	}
+	if !strings.Contains(sent[0].Content, "<@2>: Monday 4pm") || !strings.Contains(sent[0].Content, "Lyar: Monday 4pm; Tuesday 4pm") {
+		t.Errorf("wrong pings: %q", sent[0].Content)
+	}
+}
+
+func TestTakeAttendance(t *testing.T) {
+	days, monday := thisWeek()
+	sched := &schedule.Schedule{
+		Week: schedule.Week{Days: days, Location: time.UTC, StartTime: 16, BlockLength: 1, Container: grid("Free", "Scrim")},
+		Players: []schedule.Player{
+			{Name: "Taub", Container: grid("Yes", "Yes")},
+			{Name: "Tydra", Container: grid("Yes", "No")},
+		},
+	}
+	links := []team.PlayerLink{{UserID: "1", Player: "Taub"}, {UserID: "2", Player: "Tydra"}}
+	store := db.NewMemory()
+
+	// nothing's on in a free block
+	if taken, err := TakeAttendance(store, 1, sched, links, nil, monday.Add(16*time.Hour+5*time.Minute)); err != nil || taken {
+		t.Fatalf("expected no attendance in a free block: %v, %v", taken, err)
+	}
+	scrim := monday.Add(17 * time.Hour)
+	if _, err := TakeAttendance(store, 1, sched, links, map[string]bool{"2": true}, scrim.Add(5*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	// Tydra left, but was there earlier in the block
+	if _, err := TakeAttendance(store, 1, sched, links, map[string]bool{}, scrim.Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.Attendance(1, monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []schedule.Attendance{
+		{Team: 1, Block: scrim, Player: "Taub", Activity: "Scrim", Availability: "Yes"},
+		{Team: 1, Block: scrim, Player: "Tydra", Activity: "Scrim", Availability: "No", Present: true},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("wrong attendance:\n%+v\n!=\n%+v", records, want)
+	}
+}
+
+func TestBlocksWithin(t *testing.T) {
+	days, monday := thisWeek()
+	week := &schedule.Week{Days: days, Location: time.UTC, StartTime: 16, BlockLength: 1, Container: grid("Free", "Scrim")}
+
+	blocks := blocksWithin(week, monday.Add(16*time.Hour+30*time.Minute), 48*time.Hour)
+	want := [][2]int{{0, 1}, {1, 0}, {1, 1}, {2, 0}}
+	if !reflect.DeepEqual(blocks, want) {
+		t.Errorf("wrong blocks: %v != %v", blocks, want)
+	}
+
+	r := Reminder{Config: &team.ReminderConfig{Activities: []string{"Free"}}}
+	if day, block, ok := r.upcoming(week, monday.Add(16*time.Hour+30*time.Minute), 48*time.Hour); !ok || day != 1 || block != 0 {
+		t.Errorf("expected Tuesday's first block to be upcoming, got %d, %d (%v)", day, block, ok)
+	}
+}
@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Attendance is whether a linked player was in their team's voice channel during a block, and what they said their
+// availability for it was.
+type Attendance struct {
+	Team int `db:"team"`
+	// Block is when the block started.
+	Block    time.Time `db:"block"`
+	Player   string    `db:"player"`
+	Activity string    `db:"activity"`
+	// Availability is what the player put on the schedule for the block, ex. Yes, or empty if they didn't.
+	Availability string `db:"availability"`
+	Present      bool   `db:"present"`
+}
+
+// Scheduled returns whether a block has an activity on, so attendance is taken for it. Blocks that are empty, free or
+// TBD don't.
+func (w *Week) Scheduled(day, block int) bool {
+	switch strings.ToLower(w.Container[day][block].Value) {
+	case "", "free", "tbd":
+		return false
+	}
+	return true
+}
+
+// Finished returns the attendance taken for blocks that are over, so players who haven't joined a block that's still
+// on aren't counted as no-shows for it.
+func (w *Week) Finished(records []Attendance) []Attendance {
+	now := w.Now()
+	var finished []Attendance
+	for _, a := range records {
+		if !a.Block.Add(time.Duration(w.BlockLength) * time.Hour).After(now) {
+			finished = append(finished, a)
+		}
+	}
+	return finished
+}
+
+// Reliability is how often a player showed up to blocks, compared to what they said they'd do.
+type Reliability struct {
+	Player string
+	// Blocks is how many blocks attendance was taken for, and Attended is how many of them the player was in voice for.
+	Blocks, Attended int
+	// Promised is how many blocks the player said yes to, and Kept is how many of those they showed up for.
+	Promised, Kept int
+	// NoShows are the blocks the player said yes to but didn't show up for, oldest first.
+	NoShows []Attendance
+}
+
+// Rate returns the share of blocks the player said yes to that they showed up for, from 0 to 1, or 1 if they didn't
+// say yes to any.
+func (r Reliability) Rate() float64 {
+	if r.Promised == 0 {
+		return 1
+	}
+	return float64(r.Kept) / float64(r.Promised)
+}
+
+// Reliabilities sums up attendance for each player, least reliable first, then by name. Records should be oldest
+// first.
+func Reliabilities(records []Attendance) []Reliability {
+	players := make(map[string]*Reliability)
+	for _, a := range records {
+		r := players[a.Player]
+		if r == nil {
+			r = &Reliability{Player: a.Player}
+			players[a.Player] = r
+		}
+		r.Blocks++
+		if a.Present {
+			r.Attended++
+		}
+		if strings.EqualFold(a.Availability, "Yes") {
+			r.Promised++
+			if a.Present {
+				r.Kept++
+			} else {
+				r.NoShows = append(r.NoShows, a)
+			}
+		}
+	}
+
+	reliabilities := make([]Reliability, 0, len(players))
+	for _, r := range players {
+		reliabilities = append(reliabilities, *r)
+	}
+	sort.Slice(reliabilities, func(i, j int) bool {
+		if a, b := reliabilities[i].Rate(), reliabilities[j].Rate(); a != b {
+			return a < b
+		}
+		return reliabilities[i].Player < reliabilities[j].Player
+	})
+	return reliabilities
+}
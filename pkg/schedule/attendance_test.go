@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/spreadsheet"
+)
+
+func TestReliabilities(t *testing.T) {
+	first := time.Date(2018, 10, 8, 16, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	records := []Attendance{
+		{Block: first, Player: "Taub", Availability: "Yes", Present: true},
+		{Block: first, Player: "Tydra", Availability: "Yes"},
+		{Block: first, Player: "Lyar", Availability: "No", Present: true},
+		{Block: second, Player: "Taub", Availability: "Yes"},
+		{Block: second, Player: "Tydra", Availability: "yes", Present: true},
+		{Block: second, Player: "Lyar", Availability: "Maybe"},
+	}
+	reliabilities := Reliabilities(records)
+	if len(reliabilities) != 3 {
+		t.Fatalf("wrong amount of players: %d != 3", len(reliabilities))
+	}
+	taub, tydra, lyar := reliabilities[0], reliabilities[1], reliabilities[2]
+	if taub.Player != "Taub" || taub.Rate() != 0.5 || len(taub.NoShows) != 1 || !taub.NoShows[0].Block.Equal(second) {
+		t.Errorf("expected Taub to have missed the second block: %+v", taub)
+	}
+	if tydra.Player != "Tydra" || tydra.Kept != 1 || tydra.Promised != 2 || tydra.Attended != 1 {
+		t.Errorf("wrong reliability for Tydra: %+v", tydra)
+	}
+	if lyar.Player != "Lyar" || lyar.Rate() != 1 || lyar.Attended != 1 || lyar.Blocks != 2 || len(lyar.NoShows) != 0 {
+		t.Errorf("expected Lyar not to have promised anything: %+v", lyar)
+	}
+
+	w := &Week{Container: make(Container, 1)}
+	for _, v := range []string{"", "Free", "tbd", "Scrim", "Player VOD"} {
+		w.Container[0] = append(w.Container[0], &spreadsheet.Cell{Value: v})
+	}
+	for block, want := range []bool{false, false, false, true, true} {
+		if got := w.Scheduled(0, block); got != want {
+			t.Errorf("Scheduled(%q) = %v, expected %v", w.Container[0][block].Value, got, want)
+		}
+	}
+}
+
+func TestFinished(t *testing.T) {
+	w := testWeek(time.UTC, 16, 1, 2)
+	first, second := w.BlockStart(0, 0), w.BlockStart(0, 1)
+	records := []Attendance{
+		{Block: first, Player: "Taub", Availability: "Yes", Present: true},
+		{Block: second, Player: "Taub", Availability: "Yes"},
+	}
+
+	// halfway through the second block, Taub could still show up
+	defer setNow(second.Add(30 * time.Minute))()
+	if finished := w.Finished(records); len(finished) != 1 || !finished[0].Block.Equal(first) {
+		t.Errorf("expected only the first block to be finished: %+v", finished)
+	}
+	setNow(second.Add(time.Hour))
+	if finished := w.Finished(records); len(finished) != 2 {
+		t.Errorf("expected both blocks to be finished: %+v", finished)
+	}
+}
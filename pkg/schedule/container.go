@@ -25,9 +25,9 @@ func (c *Container) Fill(sheet *spreadsheet.Sheet, rowStart, rows, colStart, col
 	for i := rowStart; i < rowStart+rows; i++ {
 		rowValues := make([]*spreadsheet.Cell, cols)
 		for j := colStart; j < colStart+cols; j++ {
-			rowValues[j-2] = &sheet.Rows[i][j]
+			rowValues[j-colStart] = &sheet.Rows[i][j]
 		}
-		values[i-2] = rowValues
+		values[i-rowStart] = rowValues
 	}
 	*c = values
 }
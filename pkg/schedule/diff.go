@@ -0,0 +1,137 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bigheadgeorge/spreadsheet"
+)
+
+// Change is a difference between two versions of a schedule.
+type Change struct {
+	// Grid is WeekGrid for changes to the week, or the name of the player whose availability changed.
+	Grid string
+	// Day and Block are where the change is. Day is -1 for changes to a whole grid, like a new week or a player
+	// being added or removed.
+	Day, Block int
+	Old, New   string
+	// OldNote and NewNote are the notes on the cell, which only the week has.
+	OldNote, NewNote string
+}
+
+// Diff returns the changes from one version of a week and players to another.
+// If the week's date changed, the whole week is new, so that's the only change returned.
+func Diff(oldWeek *Week, oldPlayers []Player, newWeek *Week, newPlayers []Player) []Change {
+	if oldWeek.Date != newWeek.Date {
+		return []Change{{Grid: WeekGrid, Day: -1, Old: oldWeek.Date, New: newWeek.Date}}
+	}
+
+	changes := diffContainer(WeekGrid, oldWeek.Container, newWeek.Container)
+	old := make(map[string]Player)
+	for _, p := range oldPlayers {
+		old[p.Name] = p
+	}
+	for _, p := range newPlayers {
+		before, ok := old[p.Name]
+		if !ok {
+			changes = append(changes, Change{Grid: p.Name, Day: -1, New: p.Name})
+			continue
+		}
+		delete(old, p.Name)
+		changes = append(changes, diffContainer(p.Name, before.Container, p.Container)...)
+	}
+	for _, p := range oldPlayers {
+		if _, ok := old[p.Name]; ok {
+			changes = append(changes, Change{Grid: p.Name, Day: -1, Old: p.Name})
+		}
+	}
+	return changes
+}
+
+// diffContainer returns the cells that changed between two versions of a grid.
+// Cells only in one version count as empty in the other.
+func diffContainer(grid string, old, new Container) []Change {
+	var changes []Change
+	for day := 0; day < len(old) || day < len(new); day++ {
+		for block := 0; block < rowLen(old, day) || block < rowLen(new, day); block++ {
+			before, after := cellIn(old, day, block), cellIn(new, day, block)
+			if before.Value != after.Value || before.Note != after.Note {
+				changes = append(changes, Change{
+					Grid: grid, Day: day, Block: block,
+					Old: before.Value, New: after.Value,
+					OldNote: before.Note, NewNote: after.Note,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+func rowLen(c Container, day int) int {
+	if day >= len(c) {
+		return 0
+	}
+	return len(c[day])
+}
+
+// cellIn returns a cell in a grid, or an empty cell if the grid doesn't have it.
+func cellIn(c Container, day, block int) *spreadsheet.Cell {
+	if block >= rowLen(c, day) || c[day][block] == nil {
+		return &spreadsheet.Cell{}
+	}
+	return c[day][block]
+}
+
+// String describes a change using the blocks on a week, ex. "Thursday 7-8pm: Free → Scrim (note: vs Inked)"
+// or "Taub: Friday 5pm Yes → No".
+func (c Change) String(week *Week) string {
+	if c.Day == -1 {
+		switch {
+		case c.Grid == WeekGrid:
+			return "New week of " + c.New + "."
+		case c.Old == "":
+			return c.Grid + " was added to the schedule."
+		default:
+			return c.Grid + " was removed from the schedule."
+		}
+	}
+
+	value := orEmpty(c.New)
+	if c.Old != c.New {
+		value = orEmpty(c.Old) + " → " + value
+	}
+	day := week.WeekdayOf(c.Day).String()
+	if c.Grid != WeekGrid {
+		return fmt.Sprintf("%s: %s %s %s", c.Grid, day, week.BlockStart(c.Day, c.Block).Format("3pm"), value)
+	}
+
+	msg := fmt.Sprintf("%s %s: %s", day, week.BlockHours(c.Day, c.Block), value)
+	if c.NewNote != c.OldNote {
+		if c.NewNote == "" {
+			msg += " (note removed)"
+		} else {
+			msg += " (note: " + c.NewNote + ")"
+		}
+	}
+	return msg
+}
+
+func orEmpty(v string) string {
+	if v == "" {
+		return "empty"
+	}
+	return v
+}
+
+// FormatChanges describes changes one per line, leaving some out with a count if there are more than max.
+func FormatChanges(week *Week, changes []Change, max int) string {
+	var lines []string
+	for i, c := range changes {
+		if i == max {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(changes)-max))
+			break
+		}
+		lines = append(lines, c.String(week))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	old := testFileSchedule(t, dir, "old.yaml")
+	s := testFileSchedule(t, dir, "new.yaml")
+	s.SetLocation(time.UTC)
+
+	if changes := Diff(&old.Week, old.Players, &s.Week, s.Players); len(changes) != 0 {
+		t.Fatalf("expected no changes between the same schedule: %+v", changes)
+	}
+
+	s.SetValue(WeekGrid, s.Week.Container[3][0], "Scrim")
+	s.SetNote(WeekGrid, s.Week.Container[3][0], "vs Inked")
+	s.SetNote(WeekGrid, s.Week.Container[0][1], "")
+	s.SetValue("Taub", s.Players[0].Container[4][1], "No")
+	s.Players[1].Name = "Nub"
+
+	want := []string{
+		"Monday 5-6pm: Scrim (note removed)",
+		"Thursday 4-5pm: Free → Scrim (note: vs Inked)",
+		"Taub: Friday 5pm Maybe → No",
+		"Nub was added to the schedule.",
+		"Tydra was removed from the schedule.",
+	}
+	changes := Diff(&old.Week, old.Players, &s.Week, s.Players)
+	if len(changes) != len(want) {
+		t.Fatalf("wrong amount of changes: %d != %d: %+v", len(changes), len(want), changes)
+	}
+	for i, c := range changes {
+		if got := c.String(&s.Week); got != want[i] {
+			t.Errorf("change %d: %q != %q", i, got, want[i])
+		}
+	}
+
+	if got := FormatChanges(&s.Week, changes, 2); got != want[0]+"\n"+want[1]+"\n...and 3 more" {
+		t.Errorf("wrong formatted changes: %q", got)
+	}
+
+	s.Week.Date = "10/15"
+	if changes = Diff(&old.Week, old.Players, &s.Week, s.Players); len(changes) != 1 || changes[0].String(&s.Week) != "New week of 10/15." {
+		t.Errorf("expected only a new week: %+v", changes)
+	}
+}
@@ -44,15 +44,15 @@ func lastModified(c *http.Client, sheetID string) (t time.Time, err error) {
 	return
 }
 
-// validActivities returns a list of valid activities based on a spreadsheet's conditional format rules.
-func validActivities(c *http.Client, sheetID string) (activities []string, err error) {
+// validActivities returns a list of valid activities based on the conditional format rules on a sheet in a spreadsheet.
+func validActivities(c *http.Client, sheetID, title string) (activities []string, err error) {
 	var f file
 	err = utils.Gets(c, &f, "https://sheets.googleapis.com/v4/spreadsheets/"+sheetID)
 	if err != nil {
 		return
 	}
 	for _, sheet := range f.Sheets {
-		if sheet.Properties.Title == "Weekly Schedule" {
+		if sheet.Properties.Title == title {
 			for _, rule := range sheet.ConditionalFormats {
 				for _, value := range rule.BooleanRule.Condition.Values {
 					activities = append(activities, value.UserEnteredValue)
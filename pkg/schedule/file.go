@@ -0,0 +1,232 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bigheadgeorge/spreadsheet"
+	"gopkg.in/yaml.v2"
+)
+
+// File is a Source backed by a JSON or YAML file, for teams that don't keep their schedule on Google Sheets.
+//
+// A file looks like this in YAML:
+//
+//	activities: [Free, Scrim, Player VOD]
+//	week:
+//	  date: 10/08
+//	  timezone: PST
+//	  start_time: 16
+//	  block_length: 1
+//	  days: [Monday 10/08, Tuesday 10/09, ...]
+//	  activities:
+//	    - [Free, Scrim, Scrim]
+//	    ...
+//	players:
+//	  - name: Taub
+//	    role: Tanks
+//	    availability:
+//	      - [Maybe, Yes, Yes]
+//	      ...
+//
+// Each row of a grid is a day and each column is a block. start_time is the hour the first block starts at in 24-hour time.
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// fileSchedule is the layout of a schedule file.
+type fileSchedule struct {
+	Activities []string     `json:"activities" yaml:"activities"`
+	Week       fileWeek     `json:"week" yaml:"week"`
+	Players    []filePlayer `json:"players" yaml:"players"`
+}
+
+type fileWeek struct {
+	Date        string     `json:"date" yaml:"date"`
+	Timezone    string     `json:"timezone" yaml:"timezone"`
+	StartTime   int        `json:"start_time" yaml:"start_time"`
+	BlockLength int        `json:"block_length" yaml:"block_length"`
+	Days        [7]string  `json:"days" yaml:"days"`
+	Activities  [][]string `json:"activities" yaml:"activities"`
+	Notes       [][]string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+type filePlayer struct {
+	Name         string     `json:"name" yaml:"name"`
+	Role         string     `json:"role" yaml:"role"`
+	Availability [][]string `json:"availability" yaml:"availability"`
+}
+
+// NewFile returns a Source that reads and writes the schedule in a file.
+// Files ending in .yml or .yaml are YAML, anything else is JSON.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+func (f *File) yaml() bool {
+	ext := strings.ToLower(filepath.Ext(f.path))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+func (f *File) load() (fs fileSchedule, err error) {
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return
+	}
+	if f.yaml() {
+		err = yaml.Unmarshal(b, &fs)
+	} else {
+		err = json.Unmarshal(b, &fs)
+	}
+	return
+}
+
+func (f *File) save(fs fileSchedule) (err error) {
+	var b []byte
+	if f.yaml() {
+		b, err = yaml.Marshal(fs)
+	} else {
+		b, err = json.MarshalIndent(fs, "", "\t")
+	}
+	if err != nil {
+		return
+	}
+	return ioutil.WriteFile(f.path, b, 0644)
+}
+
+// ID returns the path to the file.
+func (f *File) ID() string {
+	return f.path
+}
+
+// Link returns nothing, since files can't be linked to.
+func (f *File) Link() string {
+	return ""
+}
+
+// LastModified returns the file's modification time.
+func (f *File) LastModified() (time.Time, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime().UTC(), nil
+}
+
+// ValidActivities returns the activities listed in the file.
+func (f *File) ValidActivities() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fs, err := f.load()
+	return fs.Activities, err
+}
+
+// grid converts rows of values into a container, where each cell's row is the day and its column is the block.
+func grid(values, notes [][]string) (Container, error) {
+	if len(values) != 7 {
+		return nil, fmt.Errorf("%d days given, should be 7", len(values))
+	}
+	c := make(Container, len(values))
+	for i, row := range values {
+		if len(row) != len(values[0]) {
+			return nil, fmt.Errorf("day %d has %d blocks, should be %d", i+1, len(row), len(values[0]))
+		}
+		c[i] = make([]*spreadsheet.Cell, len(row))
+		for j, value := range row {
+			c[i][j] = &spreadsheet.Cell{Row: uint(i), Column: uint(j), Value: value}
+			if i < len(notes) && j < len(notes[i]) {
+				c[i][j].Note = notes[i][j]
+			}
+		}
+	}
+	return c, nil
+}
+
+// Fetch reads the week and players from the file.
+func (f *File) Fetch(activities []string) (week Week, players []Player, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fs, err := f.load()
+	if err != nil {
+		return
+	}
+
+	week = Week{
+		Date:        fs.Week.Date,
+		Days:        fs.Week.Days,
+		Timezone:    fs.Week.Timezone,
+		StartTime:   fs.Week.StartTime,
+		BlockLength: fs.Week.BlockLength,
+	}
+	week.Container, err = grid(fs.Week.Activities, fs.Week.Notes)
+	if err != nil {
+		err = fmt.Errorf("error reading week: %s", err)
+		return
+	}
+
+	for _, p := range fs.Players {
+		player := Player{Name: p.Name, Role: p.Role}
+		player.Container, err = grid(p.Availability, nil)
+		if err != nil {
+			err = fmt.Errorf("error reading availability for %s: %s", p.Name, err)
+			return
+		}
+		players = append(players, player)
+	}
+	return
+}
+
+// set sets a value in a grid, growing it if it needs to.
+func set(rows *[][]string, row, column uint, value string) {
+	for uint(len(*rows)) <= row {
+		*rows = append(*rows, nil)
+	}
+	for uint(len((*rows)[row])) <= column {
+		(*rows)[row] = append((*rows)[row], "")
+	}
+	(*rows)[row][column] = value
+}
+
+// Write applies edits to the file.
+func (f *File) Write(edits []Edit) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fs, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range edits {
+		if e.Grid == WeekGrid {
+			if e.Note {
+				set(&fs.Week.Notes, e.Row, e.Column, e.Value)
+			} else {
+				set(&fs.Week.Activities, e.Row, e.Column, e.Value)
+			}
+			continue
+		}
+
+		found := false
+		for i := range fs.Players {
+			if fs.Players[i].Name == e.Grid {
+				if e.Note {
+					return fmt.Errorf("can't add notes to a player's availability")
+				}
+				set(&fs.Players[i].Availability, e.Row, e.Column, e.Value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no player named %q", e.Grid)
+		}
+	}
+	return f.save(fs)
+}
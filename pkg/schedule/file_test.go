@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempDir makes a directory for a test to write schedules in, and a function to remove it.
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "thonky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// testFileSchedule copies the test schedule file into dir so it can be written to, and loads it.
+func testFileSchedule(t *testing.T, dir, name string) *Schedule {
+	b, err := ioutil.ReadFile("testdata/schedule.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err = ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(NewFile(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Update(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFileFetch(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	s := testFileSchedule(t, dir, "schedule.yaml")
+
+	week := [][]string{
+		{"Free", "Scrim", "Scrim", "Scrim", "Scrim", "Free"},
+		{"Free", "Scrim", "Scrim", "Free", "Free", "Free"},
+		{"Free", "Scrim", "Scrim", "Free", "Free", "Free"},
+		{"Free", "Free", "Free", "Scrim", "Scrim", "Player VOD"},
+		{"Free", "Scrim", "Scrim", "Scrim", "Scrim", "Free"},
+		{"Free", "Free", "Free", "Free", "Free", "Free"},
+		{"Free", "Free", "Free", "Free", "Free", "Free"},
+	}
+	verifyContainer(&s.Week.Container, week, t)
+	verifyWeek(&s.Week, &Week{StartTime: 16, BlockLength: 1}, t)
+	if note := s.Week.Container[0][1].Note; note != "Inked" {
+		t.Errorf("wrong note: %q != %q", note, "Inked")
+	}
+	if len(s.ValidActivities) != 4 {
+		t.Errorf("wrong amount of activities: %d != 4", len(s.ValidActivities))
+	}
+	if len(s.Players) != 2 || s.Players[0].Name != "Taub" || s.Players[0].Role != "Tanks" {
+		t.Fatalf("wrong players: %+v", s.Players)
+	}
+	if a := s.Players[0].AvailabilityAt(1, 7, 4); a != "No" {
+		t.Errorf("wrong availability: %q != %q", a, "No")
+	}
+}
+
+func TestFileWrite(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	for _, name := range []string{"schedule.yaml", "schedule.json"} {
+		s := testFileSchedule(t, dir, "schedule.yaml")
+		if name == "schedule.json" {
+			// round trip through JSON by saving the YAML schedule as JSON
+			fs, err := s.Source.(*File).load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			json := NewFile(filepath.Join(dir, name))
+			if err = json.save(fs); err != nil {
+				t.Fatal(err)
+			}
+			if s, err = New(json); err != nil {
+				t.Fatal(err)
+			}
+			if err = s.Update(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		s.SetValue(WeekGrid, s.Week.Container[5][0], "Scrim")
+		s.SetNote(WeekGrid, s.Week.Container[5][0], "vs Inked")
+		s.SetValue("Tydra", s.Players[1].Container[6][2], "Maybe")
+		if err := s.Sync(); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		if err := s.Update(); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if cell := s.Week.Container[5][0]; cell.Value != "Scrim" || cell.Note != "vs Inked" {
+			t.Errorf("%s: week wasn't written: %+v", name, *cell)
+		}
+		if a := s.Players[1].AvailabilityOn(6)[2]; a != "Maybe" {
+			t.Errorf("%s: availability wasn't written: %q != %q", name, a, "Maybe")
+		}
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	s := testFileSchedule(t, dir, "schedule.yaml")
+
+	var changes int
+	s.OnChange(func(changed *Schedule) {
+		if changed != s {
+			t.Errorf("listener got the wrong schedule")
+		}
+		changes++
+	})
+	if err := s.Sync(); err != nil || changes != 0 {
+		t.Errorf("syncing without edits should be a no-op: %d changes (%v)", changes, err)
+	}
+	s.SetValue(WeekGrid, s.Week.Container[5][0], "Scrim")
+	if err := s.Sync(); err != nil || changes != 1 {
+		t.Errorf("expected a change after syncing an edit: %d changes (%v)", changes, err)
+	}
+	if err := s.Update(); err != nil || changes != 2 {
+		t.Errorf("expected a change after updating: %d changes (%v)", changes, err)
+	}
+}
+
+func TestFileBadGrid(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	s := testFileSchedule(t, dir, "schedule.yaml")
+	s.SetValue("Nobody", s.Players[0].Container[0][0], "Yes")
+	if err := s.Sync(); err == nil {
+		t.Error("expected an error writing to a player that doesn't exist")
+	}
+}
@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"strconv"
+	"time"
+)
+
+// Snapshot is a saved version of a schedule's week and players.
+type Snapshot struct {
+	ID            int       `db:"id"`
+	SpreadsheetID string    `db:"spreadsheet_id"`
+	Date          string    `db:"date"`
+	Saved         time.Time `db:"saved"`
+	Week          Week      `db:"-"`
+	Players       []Player  `db:"-"`
+}
+
+// SameDate returns whether two dates written like the week's date, ex. "10/08" and "10/8", are the same day.
+func SameDate(a, b string) bool {
+	ma, mb := dateRe.FindStringSubmatch(a), dateRe.FindStringSubmatch(b)
+	if ma == nil || mb == nil {
+		return a == b
+	}
+	for i := 1; i <= 2; i++ {
+		x, _ := strconv.Atoi(ma[i])
+		y, _ := strconv.Atoi(mb[i])
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
+
+// Restore changes the week's activities and notes back to how they were in a snapshot, along with each player's
+// availability if availability is true. Players that aren't in the snapshot, and blocks that weren't on the schedule then,
+// are left alone. The changes are written to the source with Sync. The number of cells changed is returned.
+func (s *Schedule) Restore(snap *Snapshot, availability bool) int {
+	n := s.restore(WeekGrid, s.Week.Container, snap.Week.Container, true)
+	if !availability {
+		return n
+	}
+	for _, p := range s.Players {
+		for _, old := range snap.Players {
+			if old.Name == p.Name {
+				n += s.restore(p.Name, p.Container, old.Container, false)
+			}
+		}
+	}
+	return n
+}
+
+func (s *Schedule) restore(grid string, current, old Container, notes bool) (n int) {
+	for day := range current {
+		for block, cell := range current[day] {
+			if block >= rowLen(old, day) || old[day][block] == nil {
+				continue
+			}
+			before := old[day][block]
+			if cell.Value != before.Value {
+				s.SetValue(grid, cell, before.Value)
+				n++
+			}
+			if notes && cell.Note != before.Note {
+				s.SetNote(grid, cell, before.Note)
+				n++
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,57 @@
+package schedule
+
+import "testing"
+
+func TestSameDate(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10/08", "10/8", true},
+		{"10/08", "10/08", true},
+		{"10/08", "10/09", false},
+		{"1/2", "01/02", true},
+		{"Week 3", "Week 3", true},
+		{"Week 3", "10/08", false},
+	}
+	for _, test := range tests {
+		if got := SameDate(test.a, test.b); got != test.want {
+			t.Errorf("SameDate(%q, %q) = %t, want %t", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	old := testFileSchedule(t, dir, "old.yaml")
+	snap := &Snapshot{Date: old.Week.Date, Week: old.Week, Players: old.Players}
+	s := testFileSchedule(t, dir, "schedule.yaml")
+
+	for _, day := range s.Week.Container {
+		for _, cell := range day {
+			s.SetValue(WeekGrid, cell, "")
+		}
+	}
+	s.SetNote(WeekGrid, s.Week.Container[0][1], "")
+	s.SetValue("Taub", s.Players[0].Container[0][0], "No")
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := s.Restore(snap, false); n != 43 {
+		t.Errorf("wrong amount of cells restored without availability: %d != 43", n)
+	}
+	if n := s.Restore(snap, true); n != 1 {
+		t.Errorf("wrong amount of cells restored with availability: %d != 1", n)
+	}
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if changes := Diff(&old.Week, old.Players, &s.Week, s.Players); len(changes) != 0 {
+		t.Errorf("schedule wasn't restored: %+v", changes)
+	}
+}
@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// icalTime is how times are written in iCalendar files. Times are in UTC so calendars don't need the week's timezone
+// spelled out, and show blocks in whatever timezone the player is in.
+const icalTime = "20060102T150405Z"
+
+// Calendar returns an iCalendar (RFC 5545) file with an event for every block in the week that has something on it.
+// Blocks in a row with the same activity and note are joined into one event, and notes are the events' descriptions.
+// id identifies the schedule in event UIDs, and name is what calendar apps call the calendar.
+func Calendar(week *Week, id, name string) []byte {
+	var b bytes.Buffer
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+	}
+	stamp := now().UTC().Format(icalTime)
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//thonky2//schedule//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeText(name))
+	line("X-WR-TIMEZONE:" + week.location().String())
+	for day := range week.Container {
+		for block := 0; block < len(week.Container[day]); block++ {
+			cell := week.Container[day][block]
+			if cell == nil || !busy(cell.Value) {
+				continue
+			}
+			last := block
+			for last+1 < len(week.Container[day]) {
+				next := week.Container[day][last+1]
+				if next == nil || next.Value != cell.Value || next.Note != cell.Note {
+					break
+				}
+				last++
+			}
+
+			start := week.BlockStart(day, block).UTC()
+			line("BEGIN:VEVENT")
+			line(fmt.Sprintf("UID:%s-%s@thonky2", start.Format(icalTime), id))
+			line("DTSTAMP:" + stamp)
+			line("DTSTART:" + start.Format(icalTime))
+			line("DTEND:" + week.BlockEnd(day, last).UTC().Format(icalTime))
+			line("SUMMARY:" + escapeText(cell.Value))
+			if cell.Note != "" {
+				line("DESCRIPTION:" + escapeText(cell.Note))
+			}
+			line("END:VEVENT")
+			block = last
+		}
+	}
+	line("END:VCALENDAR")
+	return b.Bytes()
+}
+
+// busy returns whether a block with an activity has something on it worth putting in a calendar.
+func busy(activity string) bool {
+	activity = strings.TrimSpace(activity)
+	return activity != "" && !strings.EqualFold(activity, "free")
+}
+
+// escapeText escapes the characters iCalendar TEXT values can't have as is.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldLine ends a content line with CRLF, folding it so no line is longer than 75 octets without splitting a character.
+func foldLine(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
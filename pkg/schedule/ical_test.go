@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	la, err := LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("no timezone database:", err)
+	}
+	defer setNow(time.Date(2018, 10, 8, 12, 0, 0, 0, la))()
+
+	w := testWeek(la, 16, 1, 4)
+	w.Container[0][0].Value = "Free"
+	w.Container[0][1].Value = "Scrim"
+	w.Container[0][1].Note = "vs Inked; bring, snacks"
+	w.Container[0][2].Value = "Scrim"
+	w.Container[0][2].Note = "vs Inked; bring, snacks"
+	w.Container[0][3].Value = "Scrim"
+	w.Container[3][0].Value = "Tryouts for the new off-tank, everyone please show up on time and warmed up"
+
+	cal := string(Calendar(w, "sheet", "Ascension Schedule"))
+	if strings.Count(cal, "BEGIN:VEVENT") != 3 {
+		t.Fatalf("expected 3 events, got:\n%s", cal)
+	}
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Ascension Schedule\r\n",
+		// Monday 5-7pm PDT, joined into one event
+		"UID:20181009T000000Z-sheet@thonky2\r\nDTSTAMP:20181008T190000Z\r\nDTSTART:20181009T000000Z\r\nDTEND:20181009T020000Z\r\nSUMMARY:Scrim\r\nDESCRIPTION:vs Inked\\; bring\\, snacks\r\n",
+		// the last block has a different note, so it's its own event
+		"DTSTART:20181009T020000Z\r\nDTEND:20181009T030000Z\r\nSUMMARY:Scrim\r\nEND:VEVENT",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(cal, want) {
+			t.Errorf("expected calendar to contain %q, got:\n%s", want, cal)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(cal, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+	if !strings.Contains(cal, "SUMMARY:Tryouts for the new off-tank\\, everyone please show up on time and \r\n warmed up") {
+		t.Errorf("expected a long summary to be folded, got:\n%s", cal)
+	}
+}
@@ -0,0 +1,137 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bigheadgeorge/spreadsheet"
+)
+
+// Layout describes where everything is on a team's spreadsheet. Cells are given in A1 notation, ex. "C2".
+type Layout struct {
+	// WeekSheet is the title of the sheet with the week schedule.
+	WeekSheet string `json:"week_sheet"`
+	// Days is the cell with the first day's name and date, ex. "Monday, 10/08".
+	// The other six days are below it, and each day's blocks start in the column to the right.
+	Days string `json:"days"`
+	// Times is the cell with the first block's time range, ex. "4-5".
+	Times string `json:"times"`
+	// Timezone is the cell with the timezone the week is in.
+	Timezone string `json:"timezone"`
+	// Blocks is how many blocks are in a day. If it's 0, blocks are read until a cell isn't a valid activity.
+	Blocks int `json:"blocks"`
+	// Afternoon is whether bare hours before noon in the times cell are in the afternoon, ex. "4-5" for 4 PM to 5 PM.
+	// Otherwise they're read as 24-hour time. Hours given with AM or PM are always read as written.
+	Afternoon bool `json:"afternoon"`
+
+	// RosterSheet is the title of the sheet listing every player.
+	RosterSheet string `json:"roster_sheet"`
+	// Roster is the cell with the first player's role. Their name is in the column to the right,
+	// and the rest of the players are listed below.
+	Roster string `json:"roster"`
+	// RosterSize is how many rows of the roster are read.
+	RosterSize int `json:"roster_size"`
+	// Availability is the cell with a player's availability for the first block of the first day,
+	// on the sheet titled with their name.
+	Availability string `json:"availability"`
+}
+
+// DefaultLayout is the layout of the spreadsheet template thonky was written for.
+var DefaultLayout = Layout{
+	WeekSheet:    WeekGrid,
+	Days:         "B3",
+	Times:        "C2",
+	Timezone:     "I2",
+	Afternoon:    true,
+	RosterSheet:  "Team Availability",
+	Roster:       "B4",
+	RosterSize:   12,
+	Availability: "C3",
+}
+
+// ParseLayout parses a layout from JSON. Anything left out is taken from DefaultLayout.
+func ParseLayout(b []byte) (Layout, error) {
+	l := DefaultLayout
+	if len(b) == 0 {
+		return l, nil
+	}
+	err := json.Unmarshal(b, &l)
+	if err != nil {
+		return l, err
+	}
+	return l, l.Validate()
+}
+
+// Validate checks that every cell in a layout is in A1 notation and that the sizes make sense.
+func (l *Layout) Validate() error {
+	for name, cell := range map[string]string{"days": l.Days, "times": l.Times, "timezone": l.Timezone, "roster": l.Roster, "availability": l.Availability} {
+		if _, _, err := parseCell(cell); err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+	}
+	if l.WeekSheet == "" || l.RosterSheet == "" {
+		return fmt.Errorf("sheet titles can't be empty")
+	}
+	if l.RosterSize <= 0 {
+		return fmt.Errorf("roster size has to be at least 1")
+	}
+	if l.Blocks < 0 {
+		return fmt.Errorf("blocks can't be negative")
+	}
+	return nil
+}
+
+var cellRe = regexp.MustCompile(`^([A-Z]+)([1-9]\d*)$`)
+
+// parseCell converts a cell in A1 notation into a zero-indexed row and column.
+func parseCell(a1 string) (row, column int, err error) {
+	match := cellRe.FindStringSubmatch(strings.ToUpper(a1))
+	if match == nil {
+		return 0, 0, fmt.Errorf("invalid cell %q", a1)
+	}
+	for _, c := range match[1] {
+		column = column*26 + int(c-'A'+1)
+	}
+	row, _ = strconv.Atoi(match[2])
+	return row - 1, column - 1, nil
+}
+
+// cellName converts a zero-indexed row and column into A1 notation.
+func cellName(row, column int) string {
+	var letters string
+	for column++; column > 0; column = (column - 1) / 26 {
+		letters = string(rune('A'+(column-1)%26)) + letters
+	}
+	return letters + strconv.Itoa(row+1)
+}
+
+// CellError reports a problem with a specific cell on a spreadsheet.
+type CellError struct {
+	Sheet  string
+	Cell   string
+	Reason string
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("%s!%s: %s", e.Sheet, e.Cell, e.Reason)
+}
+
+// cellAt returns the cell at a row and column on a sheet, or a CellError if the sheet doesn't reach it.
+func cellAt(sheet *spreadsheet.Sheet, row, column int) (*spreadsheet.Cell, error) {
+	if row < 0 || row >= len(sheet.Rows) || column < 0 || column >= len(sheet.Rows[row]) {
+		return nil, &CellError{sheet.Properties.Title, cellName(row, column), "outside of the sheet"}
+	}
+	return &sheet.Rows[row][column], nil
+}
+
+// checkRange makes sure a rectangle of cells is all on a sheet.
+func checkRange(sheet *spreadsheet.Sheet, row, rows, column, columns int) error {
+	if _, err := cellAt(sheet, row, column); err != nil {
+		return err
+	}
+	_, err := cellAt(sheet, row+rows-1, column+columns-1)
+	return err
+}
@@ -0,0 +1,179 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bigheadgeorge/spreadsheet"
+)
+
+func TestParseCell(t *testing.T) {
+	tests := []struct {
+		a1       string
+		row, col int
+	}{
+		{"A1", 0, 0},
+		{"C2", 1, 2},
+		{"i2", 1, 8},
+		{"Z10", 9, 25},
+		{"AA1", 0, 26},
+		{"AZ3", 2, 51},
+	}
+	for _, test := range tests {
+		row, col, err := parseCell(test.a1)
+		if err != nil {
+			t.Errorf("%s: %s", test.a1, err)
+			continue
+		}
+		if row != test.row || col != test.col {
+			t.Errorf("%s: expected (%d, %d), got (%d, %d)", test.a1, test.row, test.col, row, col)
+		}
+		if name := cellName(row, col); name != strings.ToUpper(test.a1) {
+			t.Errorf("cellName(%d, %d): expected %s, got %s", row, col, test.a1, name)
+		}
+	}
+
+	for _, bad := range []string{"", "A", "1", "A0", "1A", "B3:C4"} {
+		if _, _, err := parseCell(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	l, err := ParseLayout(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != DefaultLayout {
+		t.Errorf("expected the default layout, got %+v", l)
+	}
+
+	l, err = ParseLayout([]byte(`{"roster_size": 8, "week_sheet": "Week"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.RosterSize != 8 || l.WeekSheet != "Week" || l.Days != DefaultLayout.Days {
+		t.Errorf("expected the given fields over the defaults, got %+v", l)
+	}
+
+	for _, bad := range []string{`{"days": "3B"}`, `{"roster_size": 0}`, `{"blocks": -1}`, `{"week_sheet": ""}`, `{`} {
+		if _, err := ParseLayout([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
+
+// testSheet returns an empty sheet with a title and the given size.
+func testSheet(title string, rows, cols int) spreadsheet.Sheet {
+	sheet := spreadsheet.Sheet{Rows: make([][]spreadsheet.Cell, rows)}
+	sheet.Properties.Title = title
+	for i := range sheet.Rows {
+		sheet.Rows[i] = make([]spreadsheet.Cell, cols)
+		for j := range sheet.Rows[i] {
+			sheet.Rows[i][j] = spreadsheet.Cell{Row: uint(i), Column: uint(j)}
+		}
+	}
+	return sheet
+}
+
+// testSheets returns a spreadsheet laid out like the default layout with one player.
+func testSheets() *Sheets {
+	week := testSheet("Weekly Schedule", 10, 9)
+	days := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	for i, day := range days {
+		week.Rows[2+i][1].Value = day + ", 10/0" + string(rune('8'+i%2))
+		for j := 2; j < 8; j++ {
+			week.Rows[2+i][j].Value = "Scrims"
+		}
+	}
+	week.Rows[1][2].Value = "4-5"
+	week.Rows[1][8].Value = "PDT"
+
+	roster := testSheet("Team Availability", 16, 3)
+	roster.Rows[3][1].Value = "Tanks"
+	roster.Rows[3][2].Value = "Taub"
+
+	player := testSheet("Taub", 10, 8)
+	for i := 2; i < 9; i++ {
+		for j := 2; j < 8; j++ {
+			player.Rows[i][j].Value = "Yes"
+		}
+	}
+
+	return &Sheets{
+		Layout:      DefaultLayout,
+		Spreadsheet: &spreadsheet.Spreadsheet{Sheets: []spreadsheet.Sheet{week, roster, player}},
+	}
+}
+
+func TestSheetsLayout(t *testing.T) {
+	s := testSheets()
+	activities := []string{"Scrims", "Free"}
+
+	week, err := s.getWeek(s.Layout.WeekSheet, activities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if week.Days[0] != "Monday, 10/08" || week.StartTime != 16 || week.BlockLength != 1 || week.Timezone != "PDT" {
+		t.Errorf("wrong week: %+v", week)
+	}
+	if len(week.Container[0]) != 6 {
+		t.Errorf("expected 6 blocks, got %d", len(week.Container[0]))
+	}
+	if c := week.Container[0][0]; c.Row != 2 || c.Column != 2 {
+		t.Errorf("expected the first block to be C3, got %s", cellName(int(c.Row), int(c.Column)))
+	}
+
+	s.Layout.Afternoon = false
+	week, err = s.getWeek(s.Layout.WeekSheet, activities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if week.StartTime != 4 || week.BlockLength != 1 {
+		t.Errorf("expected 4-5 to be read as 4 AM with afternoon off, got %+v", week)
+	}
+
+	players, err := s.getPlayers(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 1 || players[0].Name != "Taub" || players[0].Role != "Tanks" {
+		t.Fatalf("wrong players: %+v", players)
+	}
+	if v := players[0].Container[6][5].Value; v != "Yes" {
+		t.Errorf("expected Taub to be available Sunday's last block, got %q", v)
+	}
+}
+
+func TestSheetsLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(s *Sheets)
+		cell string
+	}{
+		{"bad day", func(s *Sheets) { s.Sheets[0].Rows[4][1].Value = "Wednesday" }, "Weekly Schedule!B5"},
+		{"bad times", func(s *Sheets) { s.Sheets[0].Rows[1][2].Value = "4pm" }, "Weekly Schedule!C2"},
+		{"no blocks", func(s *Sheets) { s.Sheets[0].Rows[2][2].Value = "Nap" }, "Weekly Schedule!C3"},
+		{"off the sheet", func(s *Sheets) { s.Layout.Timezone = "Z2" }, "Weekly Schedule!Z2"},
+		{"missing player", func(s *Sheets) { s.Sheets[1].Rows[4][2].Value = "Tydra" }, "Team Availability!C5"},
+		{"bad availability", func(s *Sheets) { s.Sheets[2].Rows[3][4].Value = "Sure" }, "Taub!E4"},
+		{"roster too long", func(s *Sheets) { s.Layout.RosterSize = 20 }, "Team Availability!C23"},
+	}
+	for _, test := range tests {
+		s := testSheets()
+		test.edit(s)
+		week, err := s.getWeek(s.Layout.WeekSheet, []string{"Scrims"})
+		if err == nil {
+			_, err = s.getPlayers(len(week.Container[0]))
+		}
+		cellErr, ok := err.(*CellError)
+		if !ok {
+			t.Errorf("%s: expected a *CellError, got %v", test.name, err)
+			continue
+		}
+		if got := cellErr.Sheet + "!" + cellErr.Cell; got != test.cell {
+			t.Errorf("%s: expected an error at %s, got %s", test.name, test.cell, cellErr)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FlexRole is the role of players who can fill in for any role in a lineup.
+const FlexRole = "Flex"
+
+// Lineup is how many players of each role a team plays with, by role name, ex. {"Tanks": 2, "DPS": 2, "Supports": 2}.
+// Role names match players' roles on the sheet, ignoring case.
+type Lineup map[string]int
+
+// DefaultLineup is the usual Overwatch team composition.
+var DefaultLineup = Lineup{"Tanks": 2, "DPS": 2, "Supports": 2}
+
+// ParseLineup parses roles and how many of each a lineup needs, ex. "tanks=2 dps=2 supports=2".
+// Roles can be separated by spaces or commas.
+func ParseLineup(s string) (Lineup, error) {
+	l := make(Lineup)
+	for _, field := range strings.Fields(strings.Replace(s, ",", " ", -1)) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid role %q; use role=count, ex. tanks=2", field)
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid count for %s: %q", parts[0], parts[1])
+		}
+		l[parts[0]] = n
+	}
+	if len(l) == 0 {
+		return nil, fmt.Errorf("no roles given")
+	}
+	return l, nil
+}
+
+// Size returns how many players are in the lineup.
+func (l Lineup) Size() (n int) {
+	for _, count := range l {
+		n += count
+	}
+	return
+}
+
+// Roles returns the roles in the lineup in alphabetical order.
+func (l Lineup) Roles() []string {
+	var roles []string
+	for role := range l {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// String describes the lineup, ex. "2 DPS, 2 Supports, 2 Tanks".
+func (l Lineup) String() string {
+	var roles []string
+	for _, role := range l.Roles() {
+		roles = append(roles, fmt.Sprintf("%d %s", l[role], role))
+	}
+	return strings.Join(roles, ", ")
+}
+
+// Role returns the role in the lineup a player plays, FlexRole if they can play any, or an empty string if they
+// don't play in the lineup, ex. coaches.
+func (l Lineup) Role(p *Player) string {
+	for role := range l {
+		if strings.EqualFold(role, p.Role) {
+			return role
+		}
+	}
+	if strings.EqualFold(p.Role, FlexRole) {
+		return FlexRole
+	}
+	return ""
+}
+
+// Pick is a player picked for a role in a lineup, or a substitute.
+type Pick struct {
+	Player string
+	// Role is the role the player fills, or their own role for substitutes.
+	Role string
+	// Maybe is whether the player said maybe instead of yes.
+	Maybe bool
+	// Flex is whether a flex player is filling in for the role.
+	Flex bool
+}
+
+// LineupPick is a lineup picked for a block.
+type LineupPick struct {
+	Starters []Pick
+	// Subs are the rest of the available players, best first.
+	Subs []Pick
+	// Missing is how many players of each role couldn't be filled.
+	Missing Lineup
+}
+
+// PickLineup picks players for each role in a lineup from who's available for a block.
+// Players who said yes are picked over players who said maybe, and players in their own role are picked over flex
+// players. Ties go to preferred starters in the order they're given, then to players who more reliably show up, by
+// their share of the blocks they said yes to that they attended, then to the order players are on the sheet. Players
+// without a reliability count as fully reliable.
+func PickLineup(players []Player, day, block int, lineup Lineup, preferred []string, reliability map[string]float64) LineupPick {
+	rank := func(name string) int {
+		for i, p := range preferred {
+			if strings.EqualFold(p, name) {
+				return i
+			}
+		}
+		return len(preferred)
+	}
+	reliable := func(name string) float64 {
+		if rate, ok := reliability[name]; ok {
+			return rate
+		}
+		return 1
+	}
+
+	var candidates []Pick
+	for i := range players {
+		p := &players[i]
+		role := lineup.Role(p)
+		if role == "" || day >= len(p.Container) || block >= len(p.Container[day]) {
+			continue
+		}
+		switch strings.ToLower(p.Container[day][block].Value) {
+		case "yes":
+			candidates = append(candidates, Pick{Player: p.Name, Role: role})
+		case "maybe":
+			candidates = append(candidates, Pick{Player: p.Name, Role: role, Maybe: true})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Maybe != candidates[j].Maybe {
+			return !candidates[i].Maybe
+		}
+		if a, b := rank(candidates[i].Player), rank(candidates[j].Player); a != b {
+			return a < b
+		}
+		return reliable(candidates[i].Player) > reliable(candidates[j].Player)
+	})
+
+	need := make(Lineup)
+	for role, n := range lineup {
+		need[role] = n
+	}
+	var pick LineupPick
+	picked := make(map[string]bool)
+	fill := func(maybe, flex bool) {
+		for _, c := range candidates {
+			isFlex := c.Role == FlexRole && lineup[FlexRole] == 0
+			if picked[c.Player] || c.Maybe != maybe || isFlex != flex {
+				continue
+			}
+			role := c.Role
+			if flex {
+				// flex players go wherever's still short, in alphabetical order
+				role = ""
+				for _, r := range lineup.Roles() {
+					if need[r] > 0 {
+						role = r
+						break
+					}
+				}
+			}
+			if role == "" || need[role] == 0 {
+				continue
+			}
+			need[role]--
+			picked[c.Player] = true
+			c.Role, c.Flex = role, flex
+			pick.Starters = append(pick.Starters, c)
+		}
+	}
+	fill(false, false)
+	fill(false, true)
+	fill(true, false)
+	fill(true, true)
+
+	for _, c := range candidates {
+		if !picked[c.Player] {
+			pick.Subs = append(pick.Subs, c)
+		}
+	}
+	pick.Missing = make(Lineup)
+	for role, n := range need {
+		if n > 0 {
+			pick.Missing[role] = n
+		}
+	}
+	return pick
+}
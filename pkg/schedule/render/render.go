@@ -0,0 +1,298 @@
+// Package render draws schedules as images, so they look the same in every server without custom emoji.
+package render
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	cellWidth  = 64
+	cellHeight = 22
+	padding    = 6
+	// swatchSize is the size of the colored squares in legends.
+	swatchSize = 12
+)
+
+// face is the font everything is drawn in. Its glyphs are all charWidth wide.
+var face = basicfont.Face7x13
+
+const charWidth = 7
+
+var (
+	background = color.RGBA{0x2f, 0x31, 0x36, 0xff}
+	lineColor  = color.RGBA{0x20, 0x22, 0x25, 0xff}
+	textColor  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	dimText    = color.RGBA{0xb9, 0xbb, 0xbe, 0xff}
+	// blank is the color of blocks with nothing in them.
+	blank = color.RGBA{0x4f, 0x54, 0x5c, 0xff}
+)
+
+// activityColors are the colors of common activities.
+var activityColors = map[string]color.RGBA{
+	"free":       {0x3b, 0x8e, 0x5a, 0xff},
+	"tbd":        blank,
+	"scrim":      {0x34, 0x6f, 0xc4, 0xff},
+	"practice":   {0xd0, 0x8a, 0x1e, 0xff},
+	"review":     {0x8e, 0x5b, 0xc4, 0xff},
+	"tournament": {0xc4, 0x3b, 0x3b, 0xff},
+	"match":      {0xc4, 0x3b, 0x3b, 0xff},
+	"off":        {0x36, 0x39, 0x3f, 0xff},
+}
+
+// palette is where other activities' colors come from.
+var palette = []color.RGBA{
+	{0x1a, 0x9c, 0x9c, 0xff},
+	{0xb0, 0x4f, 0x8e, 0xff},
+	{0x7a, 0x8f, 0x2e, 0xff},
+	{0xc4, 0x6a, 0x3b, 0xff},
+	{0x4a, 0x5c, 0xc4, 0xff},
+	{0x9c, 0x7a, 0x4f, 0xff},
+}
+
+// availabilityColors are the colors of players' answers.
+var availabilityColors = map[string]color.RGBA{
+	"yes":   {0x3b, 0x8e, 0x5a, 0xff},
+	"maybe": {0xc9, 0xa2, 0x27, 0xff},
+	"no":    {0xb0, 0x3a, 0x3a, 0xff},
+}
+
+// ActivityColor returns the color blocks with an activity are drawn in.
+// Activities without their own color always get the same one from a palette, so they match from week to week.
+func ActivityColor(activity string) color.RGBA {
+	activity = strings.ToLower(strings.TrimSpace(activity))
+	if activity == "" {
+		return blank
+	}
+	if c, ok := activityColors[activity]; ok {
+		return c
+	}
+	h := fnv.New32a()
+	h.Write([]byte(activity))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// AvailabilityColor returns the color an answer to whether a player is available is drawn in.
+func AvailabilityColor(answer string) color.RGBA {
+	if c, ok := availabilityColors[strings.ToLower(strings.TrimSpace(answer))]; ok {
+		return c
+	}
+	return blank
+}
+
+// Week draws the week's activities, with a row for each day starting from today and a legend of the activities.
+func Week(w *schedule.Week) *image.RGBA {
+	g := grid{title: "Week of " + w.Date, subtitle: "Times in " + w.Zone(), columns: hours(w)}
+	today := w.Today()
+	seen := make(map[string]bool)
+	for i := 0; i < 7; i++ {
+		day := (i + today) % 7
+		r := row{label: w.Days[day], highlight: day == today}
+		for _, cell := range w.Container[day] {
+			r.cells = append(r.cells, gridCell{text: cell.Value, fill: ActivityColor(cell.Value)})
+			name := strings.TrimSpace(cell.Value)
+			if name == "" {
+				name = "Nothing"
+			}
+			if key := strings.ToLower(name); !seen[key] {
+				seen[key] = true
+				g.legend = append(g.legend, legendItem{label: name, fill: ActivityColor(cell.Value)})
+			}
+		}
+		g.rows = append(g.rows, r)
+	}
+	return g.draw()
+}
+
+// Day draws whether each player is available for the blocks on a day, followed by how many players of each role
+// said yes.
+func Day(w *schedule.Week, players []schedule.Player, day int) *image.RGBA {
+	g := grid{title: "Schedule for " + w.Days[day], subtitle: "Times in " + w.Zone(), columns: hours(w)}
+	var roles []string
+	yes := make(map[string][]int)
+	for _, p := range players {
+		if _, ok := yes[p.Role]; !ok {
+			roles = append(roles, p.Role)
+			yes[p.Role] = make([]int, len(w.Container[day]))
+		}
+		r := row{label: p.Name}
+		for i, answer := range p.AvailabilityOn(day) {
+			r.cells = append(r.cells, gridCell{text: answer, fill: AvailabilityColor(answer)})
+			if strings.EqualFold(answer, "yes") && i < len(yes[p.Role]) {
+				yes[p.Role][i]++
+			}
+		}
+		g.rows = append(g.rows, r)
+	}
+	for _, role := range roles {
+		r := row{label: role + " (yes)", highlight: true}
+		for _, n := range yes[role] {
+			r.cells = append(r.cells, gridCell{text: strconv.Itoa(n), fill: background})
+		}
+		g.rows = append(g.rows, r)
+	}
+	g.legend = []legendItem{
+		{label: "Yes", fill: AvailabilityColor("yes")},
+		{label: "Maybe", fill: AvailabilityColor("maybe")},
+		{label: "No", fill: AvailabilityColor("no")},
+		{label: "No answer", fill: blank},
+	}
+	return g.draw()
+}
+
+// hours returns the start time of each block, ex. "4pm", using the day with the most blocks.
+func hours(w *schedule.Week) []string {
+	longest := 0
+	for day := range w.Container {
+		if len(w.Container[day]) > len(w.Container[longest]) {
+			longest = day
+		}
+	}
+	var hours []string
+	for block := range w.Container[longest] {
+		hours = append(hours, w.BlockStart(longest, block).Format("3pm"))
+	}
+	return hours
+}
+
+// grid is a table of colored cells with labels on the left, hours along the top and a legend below.
+type grid struct {
+	title, subtitle string
+	columns         []string
+	rows            []row
+	legend          []legendItem
+}
+
+type row struct {
+	label string
+	// highlight draws the label brighter, ex. for today.
+	highlight bool
+	cells     []gridCell
+}
+
+type gridCell struct {
+	text string
+	fill color.RGBA
+}
+
+type legendItem struct {
+	label string
+	fill  color.RGBA
+}
+
+func (i legendItem) width() int {
+	return swatchSize + padding + textWidth(i.label) + 2*padding
+}
+
+// draw lays out and draws the grid.
+func (g *grid) draw() *image.RGBA {
+	labelWidth := 0
+	for _, r := range g.rows {
+		if w := textWidth(r.label); w > labelWidth {
+			labelWidth = w
+		}
+	}
+	labelWidth += 2 * padding
+	width := labelWidth + len(g.columns)*cellWidth + padding
+	if w := textWidth(g.title+" - "+g.subtitle) + 2*padding; w > width {
+		width = w
+	}
+
+	// legend items wrap onto more lines when they don't fit
+	var legendLines [][]legendItem
+	lineWidth := width
+	for _, item := range g.legend {
+		if lineWidth+item.width() > width-padding {
+			legendLines = append(legendLines, nil)
+			lineWidth = padding
+		}
+		legendLines[len(legendLines)-1] = append(legendLines[len(legendLines)-1], item)
+		lineWidth += item.width()
+	}
+
+	height := (2+len(g.rows)+len(legendLines))*cellHeight + 3*padding
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	y := padding
+	drawText(img, padding, y, g.title, textColor)
+	drawText(img, padding+textWidth(g.title+" - "), y, g.subtitle, dimText)
+	y += cellHeight
+	for i, column := range g.columns {
+		x := labelWidth + i*cellWidth
+		drawText(img, x+(cellWidth-textWidth(column))/2, y, column, dimText)
+	}
+	y += cellHeight
+
+	for _, r := range g.rows {
+		labelColor := dimText
+		if r.highlight {
+			labelColor = textColor
+		}
+		drawText(img, padding, y, r.label, labelColor)
+		for i, c := range r.cells {
+			if i >= len(g.columns) {
+				break
+			}
+			x := labelWidth + i*cellWidth
+			fill(img, image.Rect(x, y, x+cellWidth, y+cellHeight), lineColor)
+			fill(img, image.Rect(x+1, y+1, x+cellWidth-1, y+cellHeight-1), c.fill)
+			text := fit(c.text, (cellWidth-4)/charWidth)
+			drawText(img, x+(cellWidth-textWidth(text))/2, y, text, textColor)
+		}
+		y += cellHeight
+	}
+
+	y += padding
+	for _, line := range legendLines {
+		x := padding
+		for _, item := range line {
+			top := y + (cellHeight-swatchSize)/2
+			fill(img, image.Rect(x, top, x+swatchSize, top+swatchSize), lineColor)
+			fill(img, image.Rect(x+1, top+1, x+swatchSize-1, top+swatchSize-1), item.fill)
+			drawText(img, x+swatchSize+padding, y, item.label, dimText)
+			x += item.width()
+		}
+		y += cellHeight
+	}
+	return img
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// drawText draws text vertically centered in a row of cells starting at top.
+func drawText(img *image.RGBA, x, top int, text string, c color.Color) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, top+(cellHeight+face.Ascent-face.Descent)/2),
+	}
+	d.DrawString(text)
+}
+
+// fit shortens text to at most n characters, marking that it was cut off.
+func fit(text string, n int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n-1]) + "~"
+}
+
+// textWidth returns how wide text is drawn in pixels.
+func textWidth(text string) int {
+	return utf8.RuneCountInString(text) * charWidth
+}
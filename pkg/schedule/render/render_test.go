@@ -0,0 +1,74 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/thonky2/pkg/schedule"
+)
+
+func testSchedule(t *testing.T) *schedule.Schedule {
+	sched, err := schedule.New(schedule.NewFile("../testdata/schedule.yaml"))
+	if err == nil {
+		err = sched.Update()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched.SetLocation(time.UTC)
+	return sched
+}
+
+// cellColor returns the color inside a cell of a grid drawn with labels labelWidth wide, away from its text.
+func cellColor(img *image.RGBA, labelWidth, row, column int) color.RGBA {
+	return img.RGBAAt(labelWidth+column*cellWidth+3, padding+(2+row)*cellHeight+3)
+}
+
+func TestWeek(t *testing.T) {
+	sched := testSchedule(t)
+	img := Week(&sched.Week)
+
+	labelWidth := textWidth("Wednesday, 10/10") + 2*padding
+	if got, want := img.Bounds().Dx(), labelWidth+6*cellWidth+padding; got != want {
+		t.Errorf("expected the image to be %d wide, got %d", want, got)
+	}
+	// days start from today
+	monday := (7 - sched.Week.Today()) % 7
+	for block, want := range []string{"Free", "Scrim", "Scrim", "Scrim", "Scrim", "Free"} {
+		if got := cellColor(img, labelWidth, monday, block); got != ActivityColor(want) {
+			t.Errorf("expected Monday block %d to be %s's color %v, got %v", block, want, ActivityColor(want), got)
+		}
+	}
+}
+
+func TestDay(t *testing.T) {
+	sched := testSchedule(t)
+	img := Day(&sched.Week, sched.Players, 0)
+
+	labelWidth := 0
+	for _, p := range sched.Players {
+		if w := textWidth(p.Name); w > labelWidth {
+			labelWidth = w
+		}
+		if w := textWidth(p.Role + " (yes)"); w > labelWidth {
+			labelWidth = w
+		}
+	}
+	labelWidth += 2 * padding
+	for block, want := range sched.Players[0].AvailabilityOn(0) {
+		if got := cellColor(img, labelWidth, 0, block); got != AvailabilityColor(want) {
+			t.Errorf("expected %s's block %d to be %s's color %v, got %v", sched.Players[0].Name, block, want, AvailabilityColor(want), got)
+		}
+	}
+}
+
+func TestActivityColor(t *testing.T) {
+	if ActivityColor("Player VOD") != ActivityColor("player vod ") {
+		t.Error("expected activities to get the same color regardless of case and spacing")
+	}
+	if ActivityColor("") != ActivityColor("TBD") {
+		t.Error("expected empty blocks to look like TBD blocks")
+	}
+}
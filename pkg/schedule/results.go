@@ -0,0 +1,188 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MapResult is the score of one map played in a scrim.
+type MapResult struct {
+	ID   int `db:"id"`
+	Team int `db:"team"`
+	// Played is when the block the map was played in started.
+	Played   time.Time `db:"played"`
+	Opponent string    `db:"opponent"`
+	Map      string    `db:"map"`
+	// Mode is the map's mode, ex. Control, or empty if the map isn't known.
+	Mode string `db:"mode"`
+	// Score and OpponentScore are the points or rounds each team won on the map.
+	Score         int `db:"score"`
+	OpponentScore int `db:"opponent_score"`
+}
+
+// mapModes are the modes of the maps in the competitive pool.
+var mapModes = map[string]string{
+	"Busan":                 "Control",
+	"Ilios":                 "Control",
+	"Lijiang Tower":         "Control",
+	"Nepal":                 "Control",
+	"Oasis":                 "Control",
+	"Hanamura":              "Assault",
+	"Horizon Lunar Colony":  "Assault",
+	"Paris":                 "Assault",
+	"Temple of Anubis":      "Assault",
+	"Volskaya Industries":   "Assault",
+	"Dorado":                "Escort",
+	"Havana":                "Escort",
+	"Junkertown":            "Escort",
+	"Rialto":                "Escort",
+	"Route 66":              "Escort",
+	"Watchpoint: Gibraltar": "Escort",
+	"Blizzard World":        "Hybrid",
+	"Eichenwalde":           "Hybrid",
+	"Hollywood":             "Hybrid",
+	"King's Row":            "Hybrid",
+	"Numbani":               "Hybrid",
+}
+
+var nonAlnumRe = regexp.MustCompile(`[^a-z0-9]`)
+
+// FindMap returns the full name of a map and its mode from the start of its name, ex. "lijiang" for Lijiang Tower or
+// "kings row" for King's Row. Maps that aren't known, or that more than one map starts with, are returned as they are
+// with no mode.
+func FindMap(name string) (string, string) {
+	key := nonAlnumRe.ReplaceAllString(strings.ToLower(name), "")
+	var found []string
+	for m := range mapModes {
+		full := nonAlnumRe.ReplaceAllString(strings.ToLower(m), "")
+		if full == key {
+			return m, mapModes[m]
+		} else if key != "" && strings.HasPrefix(full, key) {
+			found = append(found, m)
+		}
+	}
+	if len(found) == 1 {
+		return found[0], mapModes[found[0]]
+	}
+	return name, ""
+}
+
+var scoreRe = regexp.MustCompile(`^(\d+)-(\d+)$`)
+
+// ParseScore parses the score of a map, ours first, ex. 3-2.
+func ParseScore(s string) (score, opponentScore int, err error) {
+	match := scoreRe.FindStringSubmatch(s)
+	if match == nil {
+		return 0, 0, fmt.Errorf("invalid score %q", s)
+	}
+	score, _ = strconv.Atoi(match[1])
+	opponentScore, _ = strconv.Atoi(match[2])
+	return
+}
+
+// Record is how many maps a team won, lost and drew.
+type Record struct {
+	Wins, Losses, Draws int
+}
+
+func (r *Record) add(m MapResult) {
+	switch {
+	case m.Score > m.OpponentScore:
+		r.Wins++
+	case m.Score < m.OpponentScore:
+		r.Losses++
+	default:
+		r.Draws++
+	}
+}
+
+// Played returns how many maps the record is from.
+func (r Record) Played() int {
+	return r.Wins + r.Losses + r.Draws
+}
+
+// WinRate returns the share of maps won, from 0 to 1.
+func (r Record) WinRate() float64 {
+	if r.Played() == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Played())
+}
+
+// String formats a record as wins, losses and draws if there are any, with the win rate, ex. "3-1 (75%)".
+func (r Record) String() string {
+	record := fmt.Sprintf("%d-%d", r.Wins, r.Losses)
+	if r.Draws > 0 {
+		record += fmt.Sprintf("-%d", r.Draws)
+	}
+	return fmt.Sprintf("%s (%.0f%%)", record, r.WinRate()*100)
+}
+
+// WeekRecord is a team's record over a week.
+type WeekRecord struct {
+	// Start is midnight on the Monday the week starts on.
+	Start time.Time
+	Record
+}
+
+// Stats sums up a team's map results.
+type Stats struct {
+	Overall Record
+	// Maps, Modes and Opponents are the records on each map, mode and against each opponent. Maps without a known mode
+	// aren't in Modes.
+	Maps, Modes, Opponents map[string]Record
+	// Weeks are the records of each week with results, oldest first.
+	Weeks []WeekRecord
+}
+
+// NewStats sums up map results, splitting them into weeks in a timezone.
+func NewStats(results []MapResult, loc *time.Location) Stats {
+	stats := Stats{Maps: make(map[string]Record), Modes: make(map[string]Record), Opponents: make(map[string]Record)}
+	add := func(records map[string]Record, key string, m MapResult) {
+		r := records[key]
+		r.add(m)
+		records[key] = r
+	}
+	weeks := make(map[time.Time]*WeekRecord)
+	for _, m := range results {
+		stats.Overall.add(m)
+		add(stats.Maps, m.Map, m)
+		if m.Mode != "" {
+			add(stats.Modes, m.Mode, m)
+		}
+		add(stats.Opponents, m.Opponent, m)
+
+		played := m.Played.In(loc)
+		start := time.Date(played.Year(), played.Month(), played.Day()-(int(played.Weekday())+6)%7, 0, 0, 0, 0, loc)
+		if weeks[start] == nil {
+			weeks[start] = &WeekRecord{Start: start}
+		}
+		weeks[start].add(m)
+	}
+	for _, w := range weeks {
+		stats.Weeks = append(stats.Weeks, *w)
+	}
+	sort.Slice(stats.Weeks, func(i, j int) bool {
+		return stats.Weeks[i].Start.Before(stats.Weeks[j].Start)
+	})
+	return stats
+}
+
+// Ranked returns the keys of records, most played first, then by name.
+func Ranked(records map[string]Record) []string {
+	keys := make([]string, 0, len(records))
+	for k := range records {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if a, b := records[keys[i]].Played(), records[keys[j]].Played(); a != b {
+			return a > b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
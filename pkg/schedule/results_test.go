@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindMap(t *testing.T) {
+	for name, want := range map[string][2]string{
+		"lijiang":       {"Lijiang Tower", "Control"},
+		"kings row":     {"King's Row", "Hybrid"},
+		"Watchpoint":    {"Watchpoint: Gibraltar", "Escort"},
+		"h":             {"h", ""},
+		"Castillo":      {"Castillo", ""},
+		"route 66":      {"Route 66", "Escort"},
+		"TEMPLE OF ANU": {"Temple of Anubis", "Assault"},
+	} {
+		if m, mode := FindMap(name); m != want[0] || mode != want[1] {
+			t.Errorf("FindMap(%q) = %q, %q, expected %q, %q", name, m, mode, want[0], want[1])
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	if _, _, err := ParseScore("3-"); err == nil {
+		t.Error("expected an error parsing a score without the opponent's")
+	}
+	score, opponentScore, err := ParseScore("3-2")
+	if err != nil || score != 3 || opponentScore != 2 {
+		t.Errorf("ParseScore(3-2) = %d, %d, %v", score, opponentScore, err)
+	}
+
+	// a Sunday night, then the next Monday
+	sunday := time.Date(2018, 10, 14, 19, 0, 0, 0, time.UTC)
+	monday := sunday.AddDate(0, 0, 1)
+	results := []MapResult{
+		{Played: sunday, Opponent: "Inked", Map: "Lijiang Tower", Mode: "Control", Score: 2, OpponentScore: 1},
+		{Played: sunday, Opponent: "Inked", Map: "Hanamura", Mode: "Assault", Score: 1, OpponentScore: 2},
+		{Played: monday, Opponent: "Kungarna", Map: "Lijiang Tower", Mode: "Control", Score: 2, OpponentScore: 0},
+		{Played: monday, Opponent: "Kungarna", Map: "Castillo", Score: 1, OpponentScore: 1},
+	}
+	stats := NewStats(results, time.UTC)
+	if want := (Record{Wins: 2, Losses: 1, Draws: 1}); stats.Overall != want || stats.Overall.String() != "2-1-1 (50%)" {
+		t.Errorf("wrong overall record: %+v %s", stats.Overall, stats.Overall)
+	}
+	if r := stats.Maps["Lijiang Tower"]; r.Wins != 2 || r.String() != "2-0 (100%)" {
+		t.Errorf("wrong record on Lijiang Tower: %+v", r)
+	}
+	if _, ok := stats.Modes[""]; ok || len(stats.Modes) != 2 {
+		t.Errorf("expected only known modes: %+v", stats.Modes)
+	}
+	if ranked := Ranked(stats.Maps); len(ranked) != 3 || ranked[0] != "Lijiang Tower" || ranked[1] != "Castillo" {
+		t.Errorf("wrong order of maps: %v", ranked)
+	}
+	if len(stats.Weeks) != 2 || !stats.Weeks[0].Start.Equal(time.Date(2018, 10, 8, 0, 0, 0, 0, time.UTC)) || stats.Weeks[1].Record.Wins != 1 {
+		t.Errorf("expected Sunday and Monday to be in different weeks: %+v", stats.Weeks)
+	}
+}
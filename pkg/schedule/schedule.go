@@ -2,44 +2,63 @@ package schedule
 
 import (
 	"fmt"
-	"net/http"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/bigheadgeorge/spreadsheet"
 )
 
-// DriveScope is the scope that HTTP clients passed into New() should be authenticated with.
-const DriveScope = "https://www.googleapis.com/auth/drive.metadata.readonly"
-
-// Schedule wraps spreadsheet.Spreadsheet with more metadata like the last modified time etc.
+// Schedule holds a team's week and players along with metadata like the last modified time.
 // Schedules should be created with New() and populated with schedule.Update().
 type Schedule struct {
+	ID              string
 	Week            Week
 	ValidActivities []string
 	Players         []Player
 	LastModified    time.Time
-	updating        bool
-	client          *http.Client
-	service         *spreadsheet.Service
-	*spreadsheet.Spreadsheet
+	// Source is where the schedule is kept. Change it with SetSource.
+	Source Source
+	// Location is the team's timezone, which the week's blocks are in.
+	Location *time.Location
+
+	updating bool
+
+	mu        sync.Mutex
+	pending   []Edit
+	listeners []func(*Schedule)
 }
 
 // New returns a new Schedule with its last modified time populated.
-func New(service *spreadsheet.Service, client *http.Client, sheetID string) (*Schedule, error) {
-	spreadsheet, err := service.FetchSpreadsheet(sheetID)
-	if err != nil {
-		return nil, fmt.Errorf("error getting spreadsheet: %s", err)
-	}
-	s := &Schedule{Spreadsheet: &spreadsheet, client: client, service: service}
-	s.LastModified, err = lastModified(client, spreadsheet.ID)
+func New(source Source) (*Schedule, error) {
+	s := &Schedule{ID: source.ID(), Source: source}
+	var err error
+	s.LastModified, err = source.LastModified()
 	return s, err
 }
 
+// Link returns a link to view the schedule's source, if it has one.
+func (s *Schedule) Link() string {
+	return s.source().Link()
+}
+
+func (s *Schedule) source() Source {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Source
+}
+
 // Update repopulates the fields of a Schedule with updated values.
 func (s *Schedule) Update() error {
+	return s.update(s.source())
+}
+
+// SetSource switches the schedule to a new source, ex. the same spreadsheet with another layout, and repopulates it
+// from there. If the schedule can't be grabbed from the new source, the old one is kept.
+func (s *Schedule) SetSource(source Source) error {
+	return s.update(source)
+}
+
+func (s *Schedule) update(source Source) error {
 	if s.updating {
 		return fmt.Errorf("already updating schedule")
 	}
@@ -49,136 +68,97 @@ func (s *Schedule) Update() error {
 		s.updating = false
 	}()
 
-	err := s.getPlayers()
-	if err != nil {
-		return fmt.Errorf("error getting players: %s", err)
-	}
-
-	s.ValidActivities, err = validActivities(s.client, s.ID)
+	activities, err := source.ValidActivities()
 	if err != nil {
 		return fmt.Errorf("error getting valid activities: %s", err)
 	}
 
-	err = s.getWeek("Weekly Schedule")
+	week, players, err := source.Fetch(activities)
 	if err != nil {
-		return fmt.Errorf("error getting week: %s", err)
+		return fmt.Errorf("error getting schedule: %s", err)
 	}
 
-	s.LastModified, err = lastModified(s.client, s.ID)
+	modified, err := source.LastModified()
 	if err != nil {
 		return fmt.Errorf("error getting last modified time: %s", err)
 	}
 
-	return s.service.ReloadSpreadsheet(s.Spreadsheet)
+	s.mu.Lock()
+	s.Source = source
+	week.Location = s.Location
+	s.ValidActivities, s.Week, s.Players, s.LastModified = activities, week, players, modified
+	s.mu.Unlock()
+	s.changed()
+	return nil
 }
 
-// Updated returns whether the sheet is updated or not
-func (s *Schedule) Updated() (bool, error) {
-	modified, err := lastModified(s.client, s.ID)
-	if err != nil {
-		return false, err
-	}
-	return modified.Before(s.LastModified) || modified.Equal(s.LastModified), nil
+// OnChange adds a function that's called after the schedule's week or players change, either from
+// an Update or a Sync.
+func (s *Schedule) OnChange(f func(*Schedule)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, f)
 }
 
-// SyncSheet pushes all of the changes on a sheet and updates the modified time.
-func (s *Schedule) SyncSheet(sheet *spreadsheet.Sheet) (err error) {
-	err = s.service.SyncSheet(sheet)
-	if err != nil {
-		return
+func (s *Schedule) changed() {
+	s.mu.Lock()
+	listeners := s.listeners
+	s.mu.Unlock()
+	for _, f := range listeners {
+		f(s)
 	}
-	s.LastModified = time.Now().UTC()
-	return
 }
 
-// getPlayers returns all of the players on a sheet.
-func (s *Schedule) getPlayers() error {
-	sheet, err := s.SheetByTitle("Team Availability")
-	if err != nil {
-		return err
-	}
+// SetLocation sets the timezone the week's blocks are in.
+func (s *Schedule) SetLocation(loc *time.Location) {
+	s.Location = loc
+	s.Week.Location = loc
+}
 
-	var wg sync.WaitGroup
-	wg.Add(12)
-	pCh := make(chan Player, 12)
-	var currentRole string
-	var playerCount int
-	for i := 3; i < 15; i++ {
-		role := sheet.Rows[i][1].Value
-		if role != "" && currentRole != role {
-			currentRole = role
-		}
-
-		name := sheet.Rows[i][2].Value
-		if name != "" {
-			playerCount++
-			go func(name, role string) {
-				defer wg.Done()
-				// TODO: handle errors, probably
-				sheet, _ := s.SheetByTitle(name)
-				player := Player{
-					Name: name,
-					Role: role,
-				}
-				player.Fill(sheet, 2, 7, 2, 6)
-				pCh <- player
-			}(name, currentRole)
-			continue
-		}
-		wg.Done()
+// Updated returns whether the schedule is up to date with its source or not
+func (s *Schedule) Updated() (bool, error) {
+	modified, err := s.source().LastModified()
+	if err != nil {
+		return false, err
 	}
+	return modified.Before(s.LastModified) || modified.Equal(s.LastModified), nil
+}
 
-	wg.Wait()
-	close(pCh)
-
-	s.Players = nil
-	for i := 0; i < playerCount; i++ {
-		s.Players = append(s.Players, <-pCh)
-	}
-	return nil
+// SetValue changes the value of a cell on the week or a player's availability.
+// The change is written to the source with Sync.
+func (s *Schedule) SetValue(grid string, cell *spreadsheet.Cell, value string) {
+	cell.Value = value
+	s.queue(Edit{Grid: grid, Row: cell.Row, Column: cell.Column, Value: value})
 }
 
-// getWeek parses the week schedule.
-func (s *Schedule) getWeek(sheetName string) error {
-	sheet, err := s.SheetByTitle(sheetName)
-	if err != nil {
-		return err
-	}
+// SetNote changes the note on a cell on the week or a player's availability.
+// The change is written to the source with Sync.
+func (s *Schedule) SetNote(grid string, cell *spreadsheet.Cell, note string) {
+	cell.Note = note
+	s.queue(Edit{Grid: grid, Row: cell.Row, Column: cell.Column, Value: note, Note: true})
+}
 
-	s.Week.Date = strings.Split(sheet.Rows[2][1].Value, ", ")[1]
-
-	var blocks int
-	for {
-		valid := false
-		for _, activity := range s.ValidActivities {
-			if sheet.Rows[2][blocks+2].Value == activity {
-				valid = true
-				break
-			}
-		}
-		if !valid {
-			break
-		}
-		blocks++
-	}
-	s.Week.Fill(sheet, 2, 7, 2, blocks)
-	for i := 2; i < 9; i++ {
-		s.Week.Days[i-2] = sheet.Rows[i][1].Value
-	}
+func (s *Schedule) queue(e Edit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, e)
+}
 
-	blockRange := strings.Split(sheet.Rows[1][2].Value, "-")
-	s.Week.StartTime, err = strconv.Atoi(blockRange[0])
-	if err != nil {
-		return err
+// Sync writes all of the changes made with SetValue and SetNote to the source and updates the modified time.
+func (s *Schedule) Sync() (err error) {
+	s.mu.Lock()
+	edits := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	if len(edits) == 0 {
+		return
 	}
 
-	blockEnd, err := strconv.Atoi(blockRange[1])
+	err = s.source().Write(edits)
 	if err != nil {
-		return err
+		return
 	}
-	s.Week.BlockLength = blockEnd - s.Week.StartTime
-
-	s.Week.Timezone = sheet.Rows[1][8].Value
-
-	return err
+	s.LastModified = time.Now().UTC()
+	s.changed()
+	return
 }
@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScrimStatus is how far along booking a scrim is.
+type ScrimStatus string
+
+// Scrims are proposed when they're booked, until the other team confirms them or either team cancels.
+const (
+	ScrimProposed  ScrimStatus = "proposed"
+	ScrimConfirmed ScrimStatus = "confirmed"
+	ScrimCancelled ScrimStatus = "cancelled"
+)
+
+// Scrim is a scrim booked against another team.
+type Scrim struct {
+	ID   int `db:"id"`
+	Team int `db:"team"`
+	// Start and End are when the first block of the scrim starts and the last one ends.
+	Start time.Time `db:"starts"`
+	End   time.Time `db:"ends"`
+	// Opponent is the other team's name.
+	Opponent string `db:"opponent"`
+	// Contact is who to talk to on the other team, ex. their Discord tag.
+	Contact string `db:"contact"`
+	// Region is the server region it's played on, ex. NA.
+	Region string `db:"region"`
+	// Maps are the maps or modes being played, ex. "Control, Hybrid".
+	Maps   string      `db:"maps"`
+	Status ScrimStatus `db:"status"`
+}
+
+// Active returns whether the scrim hasn't been cancelled.
+func (s Scrim) Active() bool {
+	return s.Status != ScrimCancelled
+}
+
+// Note returns what's written in the notes of the scrim's blocks on the sheet, ex. "vs Inked (proposed) | NA | Control |
+// contact Taub#1234", or an empty string if it was cancelled.
+func (s Scrim) Note() string {
+	if !s.Active() {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("vs %s (%s)", s.Opponent, s.Status)}
+	for _, detail := range []string{s.Region, s.Maps} {
+		if detail != "" {
+			parts = append(parts, detail)
+		}
+	}
+	if s.Contact != "" {
+		parts = append(parts, "contact "+s.Contact)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Blocks returns the blocks a scrim covers on a week, as a day and a start and end index, or ok is false if the scrim
+// isn't on the week.
+func (s Scrim) Blocks(w *Week) (day, start, end int, ok bool) {
+	day, start, ok = w.BlockAt(s.Start)
+	if !ok {
+		return
+	}
+	end = start + 1
+	for end < len(w.Container[day]) && w.BlockStart(day, end).Before(s.End) {
+		end++
+	}
+	return
+}
+
+// Overlaps returns whether two scrims are on at the same time.
+func (s Scrim) Overlaps(other Scrim) bool {
+	return s.Start.Before(other.End) && other.Start.Before(s.End)
+}
+
+// ScrimRequest is a team asking a team in another guild for a scrim through the looking for scrim board. Requests are
+// proposed until the team they're sent to accepts them, which confirms them, or declines them, which cancels them.
+type ScrimRequest struct {
+	ID   int `db:"id"`
+	From int `db:"from_team"`
+	To   int `db:"to_team"`
+	// Start and End are when the scrim would start and end.
+	Start  time.Time   `db:"starts"`
+	End    time.Time   `db:"ends"`
+	Status ScrimStatus `db:"status"`
+}
@@ -0,0 +1,41 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScrim(t *testing.T) {
+	la, err := LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("no timezone database:", err)
+	}
+	defer setNow(time.Date(2018, 10, 8, 12, 0, 0, 0, la))()
+	w := testWeek(la, 16, 1, 6)
+
+	s := Scrim{Start: w.BlockStart(3, 2), End: w.BlockEnd(3, 3), Opponent: "Inked", Region: "NA", Contact: "Taub#1234", Status: ScrimProposed}
+	if day, start, end, ok := s.Blocks(w); !ok || day != 3 || start != 2 || end != 4 {
+		t.Errorf("wrong blocks: %d %d-%d (%t)", day, start, end, ok)
+	}
+	if note := s.Note(); note != "vs Inked (proposed) | NA | contact Taub#1234" {
+		t.Errorf("wrong note: %q", note)
+	}
+
+	later := Scrim{Start: w.BlockStart(3, 3), End: w.BlockEnd(3, 4)}
+	if !s.Overlaps(later) || !later.Overlaps(s) {
+		t.Error("expected scrims sharing a block to overlap")
+	}
+	later.Start = s.End
+	if s.Overlaps(later) {
+		t.Error("expected back to back scrims not to overlap")
+	}
+
+	s.Status = ScrimCancelled
+	if s.Note() != "" {
+		t.Errorf("cancelled scrims shouldn't have a note, got %q", s.Note())
+	}
+	s.Start = s.Start.AddDate(0, 0, 7)
+	if _, _, _, ok := s.Blocks(w); ok {
+		t.Error("expected a scrim next week not to be on the week")
+	}
+}
@@ -0,0 +1,258 @@
+package schedule
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bigheadgeorge/spreadsheet"
+)
+
+// DriveScope is the scope that HTTP clients passed into NewSheets() should be authenticated with.
+const DriveScope = "https://www.googleapis.com/auth/drive.metadata.readonly"
+
+// Sheets is a Source backed by a Google Sheets spreadsheet.
+type Sheets struct {
+	// Layout is where everything is on the spreadsheet.
+	Layout  Layout
+	client  *http.Client
+	service *spreadsheet.Service
+	*spreadsheet.Spreadsheet
+}
+
+// NewSheets fetches a spreadsheet laid out like the given layout to use as a Source.
+func NewSheets(service *spreadsheet.Service, client *http.Client, sheetID string, layout Layout) (*Sheets, error) {
+	spreadsheet, err := service.FetchSpreadsheet(sheetID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting spreadsheet: %s", err)
+	}
+	return &Sheets{Layout: layout, client: client, service: service, Spreadsheet: &spreadsheet}, nil
+}
+
+// WithLayout returns a Source for the same spreadsheet laid out like another layout.
+func (s *Sheets) WithLayout(layout Layout) *Sheets {
+	sheets := *s
+	sheets.Layout = layout
+	return &sheets
+}
+
+// ID returns the spreadsheet's ID.
+func (s *Sheets) ID() string {
+	return s.Spreadsheet.ID
+}
+
+// Link returns a link to the spreadsheet.
+func (s *Sheets) Link() string {
+	return "https://docs.google.com/spreadsheets/d/" + s.Spreadsheet.ID
+}
+
+// LastModified returns the last modified time of the spreadsheet on Google Drive.
+func (s *Sheets) LastModified() (time.Time, error) {
+	return lastModified(s.client, s.Spreadsheet.ID)
+}
+
+// ValidActivities returns the activities in the week sheet's conditional format rules.
+func (s *Sheets) ValidActivities() ([]string, error) {
+	return validActivities(s.client, s.Spreadsheet.ID, s.Layout.WeekSheet)
+}
+
+// Fetch reloads the spreadsheet and parses the week and players from it.
+// If the spreadsheet doesn't match the layout, the error is a *CellError saying which cell is wrong.
+func (s *Sheets) Fetch(activities []string) (week Week, players []Player, err error) {
+	err = s.service.ReloadSpreadsheet(s.Spreadsheet)
+	if err != nil {
+		return
+	}
+	week, err = s.getWeek(s.Layout.WeekSheet, activities)
+	if err != nil {
+		err = fmt.Errorf("error getting week: %s", err)
+		return
+	}
+	players, err = s.getPlayers(len(week.Container[0]))
+	if err != nil {
+		err = fmt.Errorf("error getting players: %s", err)
+	}
+	return
+}
+
+// Write updates cells on the week sheet and players' sheets, then pushes the changes.
+func (s *Sheets) Write(edits []Edit) error {
+	sheets := make(map[string]*spreadsheet.Sheet)
+	var order []string
+	for _, e := range edits {
+		title := e.Grid
+		if title == WeekGrid {
+			title = s.Layout.WeekSheet
+		}
+		sheet, ok := sheets[title]
+		if !ok {
+			var err error
+			sheet, err = s.SheetByTitle(title)
+			if err != nil {
+				return err
+			}
+			sheets[title] = sheet
+			order = append(order, title)
+		}
+		if e.Note {
+			sheet.UpdateNote(int(e.Row), int(e.Column), e.Value)
+		} else {
+			sheet.Update(int(e.Row), int(e.Column), e.Value)
+		}
+	}
+	for _, title := range order {
+		err := s.service.SyncSheet(sheets[title])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sheet returns the sheet with a title, with an error that says what was being looked for if it's missing.
+func (s *Sheets) sheet(title, purpose string) (*spreadsheet.Sheet, error) {
+	sheet, err := s.SheetByTitle(title)
+	if err != nil {
+		return nil, fmt.Errorf("no sheet titled %q for %s", title, purpose)
+	}
+	return sheet, nil
+}
+
+// getPlayers returns all of the players on the roster, with availability for the given amount of blocks.
+func (s *Sheets) getPlayers(blocks int) ([]Player, error) {
+	sheet, err := s.sheet(s.Layout.RosterSheet, "the roster")
+	if err != nil {
+		return nil, err
+	}
+	rosterRow, rosterCol, err := parseCell(s.Layout.Roster)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkRange(sheet, rosterRow, s.Layout.RosterSize, rosterCol, 2); err != nil {
+		return nil, err
+	}
+	availRow, availCol, err := parseCell(s.Layout.Availability)
+	if err != nil {
+		return nil, err
+	}
+
+	var players []Player
+	var currentRole string
+	for i := rosterRow; i < rosterRow+s.Layout.RosterSize; i++ {
+		role := sheet.Rows[i][rosterCol].Value
+		if role != "" && currentRole != role {
+			currentRole = role
+		}
+
+		name := sheet.Rows[i][rosterCol+1].Value
+		if name == "" {
+			continue
+		}
+		playerSheet, err := s.SheetByTitle(name)
+		if err != nil {
+			return nil, &CellError{sheet.Properties.Title, cellName(i, rosterCol+1), fmt.Sprintf("no sheet titled %q for this player", name)}
+		}
+		if err = checkRange(playerSheet, availRow, 7, availCol, blocks); err != nil {
+			return nil, err
+		}
+		for row := availRow; row < availRow+7; row++ {
+			for col := availCol; col < availCol+blocks; col++ {
+				switch v := playerSheet.Rows[row][col].Value; v {
+				case "", "Yes", "Maybe", "No":
+				default:
+					return nil, &CellError{name, cellName(row, col), fmt.Sprintf("%q isn't Yes, Maybe or No", v)}
+				}
+			}
+		}
+
+		player := Player{
+			Name: name,
+			Role: currentRole,
+		}
+		player.Fill(playerSheet, availRow, 7, availCol, blocks)
+		players = append(players, player)
+	}
+	return players, nil
+}
+
+// getWeek parses the week schedule.
+func (s *Sheets) getWeek(sheetName string, activities []string) (week Week, err error) {
+	sheet, err := s.sheet(sheetName, "the week schedule")
+	if err != nil {
+		return
+	}
+	title := sheet.Properties.Title
+
+	daysRow, daysCol, err := parseCell(s.Layout.Days)
+	if err != nil {
+		return
+	}
+	if err = checkRange(sheet, daysRow, 7, daysCol, 2); err != nil {
+		return
+	}
+	for i := 0; i < 7; i++ {
+		day := sheet.Rows[daysRow+i][daysCol].Value
+		if !strings.Contains(day, ", ") {
+			err = &CellError{title, cellName(daysRow+i, daysCol), fmt.Sprintf("expected a day and date like \"Monday, 10/08\", got %q", day)}
+			return
+		}
+		week.Days[i] = day
+	}
+	week.Date = strings.Split(week.Days[0], ", ")[1]
+
+	blocks := s.Layout.Blocks
+	if blocks == 0 {
+		for daysCol+1+blocks < len(sheet.Rows[daysRow]) {
+			valid := false
+			for _, activity := range activities {
+				if sheet.Rows[daysRow][daysCol+1+blocks].Value == activity {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				break
+			}
+			blocks++
+		}
+		if blocks == 0 {
+			err = &CellError{title, cellName(daysRow, daysCol+1), fmt.Sprintf("expected the first block to be a valid activity, got %q", sheet.Rows[daysRow][daysCol+1].Value)}
+			return
+		}
+	}
+	if err = checkRange(sheet, daysRow, 7, daysCol+1, blocks); err != nil {
+		return
+	}
+	week.Fill(sheet, daysRow, 7, daysCol+1, blocks)
+
+	timesRow, timesCol, err := parseCell(s.Layout.Times)
+	if err != nil {
+		return
+	}
+	times, err := cellAt(sheet, timesRow, timesCol)
+	if err != nil {
+		return
+	}
+	start, end, ambiguous, err := ParseHours(strings.Replace(times.Value, " ", "", -1))
+	if err != nil || start == end {
+		err = &CellError{title, cellName(timesRow, timesCol), fmt.Sprintf("expected a time range like \"4-5\" or \"16-17\", got %q", times.Value)}
+		return
+	}
+	if ambiguous && s.Layout.Afternoon {
+		start, end = start+12, end+12
+	}
+	week.StartTime, week.BlockLength = start, end-start
+
+	tzRow, tzCol, err := parseCell(s.Layout.Timezone)
+	if err != nil {
+		return
+	}
+	tz, err := cellAt(sheet, tzRow, tzCol)
+	if err != nil {
+		return
+	}
+	week.Timezone = tz.Value
+
+	return
+}
@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// maybeCredit is how much a "Maybe" counts towards filling a lineup, compared to a "Yes".
+const maybeCredit = 0.5
+
+// Slot is a block and how close a team is to having a full lineup for it.
+type Slot struct {
+	Day, Block int
+	// Score is how much of the lineup can play, from 0 to 1. Players who said maybe count for part of a player.
+	Score float64
+	// Full is whether the whole lineup said yes.
+	Full bool
+	// Yes and Maybe are the players in the lineup's roles who said yes or maybe.
+	Yes, Maybe []string
+	// Missing is how many players of each role are still needed after everyone who said yes or maybe.
+	Missing Lineup
+}
+
+// Open returns whether a block is free to book a scrim in. Blocks are open if they don't have a note, which is where
+// opponents are written, and are either empty, free, TBD or a scrim that hasn't been booked yet.
+func (w *Week) Open(day, block int) bool {
+	cell := w.Container[day][block]
+	if cell.Note != "" {
+		return false
+	}
+	switch strings.ToLower(cell.Value) {
+	case "", "free", "tbd", "scrim":
+		return true
+	}
+	return false
+}
+
+// FindSlots ranks the open blocks in a week that haven't started by now, best first, by how much of a lineup can play.
+// Blocks with the same score are in order.
+func FindSlots(week *Week, players []Player, lineup Lineup, now time.Time) []Slot {
+	var slots []Slot
+	for day := range week.Container {
+		for block := range week.Container[day] {
+			if !week.Open(day, block) || week.BlockStart(day, block).Before(now) {
+				continue
+			}
+			slots = append(slots, slotFor(players, lineup, day, block))
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Score > slots[j].Score
+	})
+	return slots
+}
+
+// slotFor scores a block by how much of the lineup PickLineup can fill with the players available.
+func slotFor(players []Player, lineup Lineup, day, block int) Slot {
+	pick := PickLineup(players, day, block, lineup, nil, nil)
+	slot := Slot{Day: day, Block: block, Missing: pick.Missing, Full: len(pick.Missing) == 0}
+	var credit float64
+	for _, p := range pick.Starters {
+		if p.Maybe {
+			credit += maybeCredit
+			slot.Full = false
+		} else {
+			credit++
+		}
+	}
+	for _, p := range append(pick.Starters, pick.Subs...) {
+		if p.Maybe {
+			slot.Maybe = append(slot.Maybe, p.Player)
+		} else {
+			slot.Yes = append(slot.Yes, p.Player)
+		}
+	}
+	slot.Score = credit / float64(lineup.Size())
+	return slot
+}
+
+// Window is a stretch of time, ex. a run of open blocks.
+type Window struct {
+	Start, End time.Time
+}
+
+// OpenWindows returns the runs of back to back open blocks in a week that haven't started by now, soonest first.
+func OpenWindows(week *Week, now time.Time) []Window {
+	var windows []Window
+	for day := range week.Container {
+		for block := range week.Container[day] {
+			start, end := week.BlockStart(day, block), week.BlockEnd(day, block)
+			if !week.Open(day, block) || start.Before(now) {
+				continue
+			}
+			if last := len(windows) - 1; last >= 0 && windows[last].End.Equal(start) {
+				windows[last].End = end
+			} else {
+				windows = append(windows, Window{start, end})
+			}
+		}
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+	return windows
+}
+
+// Overlap returns the times that are in both a and b, which are soonest first and don't overlap themselves. Times in
+// different timezones are compared as the same instant.
+func Overlap(a, b []Window) []Window {
+	var both []Window
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if start.Before(end) {
+			both = append(both, Window{start, end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return both
+}
+
+// OpenBetween returns whether a week has blocks from start to end and they're all open.
+func (w *Week) OpenBetween(start, end time.Time) bool {
+	day, block, ok := w.BlockAt(start)
+	if !ok {
+		return false
+	}
+	for ; block < len(w.Container[day]) && w.BlockStart(day, block).Before(end); block++ {
+		if !w.Open(day, block) {
+			return false
+		}
+	}
+	return w.BlockStart(day, block).Equal(end)
+}
@@ -0,0 +1,168 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLineup(t *testing.T) {
+	l, err := ParseLineup("tanks=2, dps=2 Supports=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Lineup{"tanks": 2, "dps": 2, "Supports": 1}); !reflect.DeepEqual(l, want) {
+		t.Errorf("%v != %v", l, want)
+	}
+	if l.Size() != 5 || l.String() != "1 Supports, 2 dps, 2 tanks" {
+		t.Errorf("wrong size or description: %d, %q", l.Size(), l)
+	}
+	for _, bad := range []string{"", "tanks", "tanks=0", "=2", "tanks=two"} {
+		if _, err := ParseLineup(bad); err == nil {
+			t.Errorf("expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestFindSlots(t *testing.T) {
+	// Wednesday at noon
+	defer setNow(time.Date(2018, 10, 10, 12, 0, 0, 0, time.UTC))()
+	w := testWeek(time.UTC, 16, 1, 2)
+	w.Container[2][0].Value = "Free"
+	w.Container[2][1].Value, w.Container[2][1].Note = "Scrim", "vs Inked"
+	w.Container[3][0].Value = "Player VOD"
+	w.Container[3][1].Value = "Scrim"
+
+	availability := func(answers map[[2]int]string) Player {
+		c := testWeek(time.UTC, 16, 1, 2).Container
+		for cell, answer := range answers {
+			c[cell[0]][cell[1]].Value = answer
+		}
+		return Player{Container: c}
+	}
+	players := []Player{
+		availability(map[[2]int]string{{2, 0}: "Yes", {3, 1}: "No"}),
+		availability(map[[2]int]string{{2, 0}: "Maybe", {3, 1}: "Yes"}),
+		availability(map[[2]int]string{{3, 1}: "yes"}),
+		availability(map[[2]int]string{{2, 0}: "Yes", {3, 1}: "Yes"}),
+	}
+	for i, role := range [][2]string{{"Taub", "Tanks"}, {"Tydra", "Supports"}, {"Lyar", "Flex"}, {"Coach", "Coaches"}} {
+		players[i].Name, players[i].Role = role[0], role[1]
+	}
+
+	slots := FindSlots(w, players, Lineup{"Tanks": 1, "Supports": 1}, w.Now())
+	// Wednesday and Thursday each have one open block, and the weekend's blocks are all open
+	if len(slots) != 8 {
+		t.Fatalf("wrong amount of open blocks: %d != 8: %+v", len(slots), slots)
+	}
+
+	best := slots[0]
+	if best.Day != 3 || best.Block != 1 || !best.Full || best.Score != 1 || len(best.Missing) != 0 {
+		t.Errorf("expected Thursday's scrim to have a full lineup with Lyar on flex: %+v", best)
+	}
+	if !reflect.DeepEqual(best.Yes, []string{"Tydra", "Lyar"}) {
+		t.Errorf("wrong players available on Thursday: %v", best.Yes)
+	}
+	second := slots[1]
+	if second.Day != 2 || second.Block != 0 || second.Full || second.Score != 0.75 || len(second.Missing) != 0 {
+		t.Errorf("expected Wednesday's free block to count Tydra's maybe as half: %+v", second)
+	}
+	if last := slots[7]; last.Score != 0 || !reflect.DeepEqual(last.Missing, Lineup{"Tanks": 1, "Supports": 1}) {
+		t.Errorf("expected an empty block to be missing everyone: %+v", last)
+	}
+}
+
+func TestOpenWindows(t *testing.T) {
+	// Wednesday at noon
+	defer setNow(time.Date(2018, 10, 10, 12, 0, 0, 0, time.UTC))()
+	utc := testWeek(time.UTC, 16, 1, 2)
+	utc.Container[4][1].Value = "Player VOD"
+	// an hour ahead, so its blocks are from 3 to 5 PM UTC
+	ahead := testWeek(time.FixedZone("UTC+1", 60*60), 16, 1, 2)
+
+	open := OpenWindows(utc, utc.Now())
+	if len(open) != 5 {
+		t.Fatalf("wrong amount of windows from Wednesday on: %d != 5: %+v", len(open), open)
+	}
+	wednesday := time.Date(2018, 10, 10, 16, 0, 0, 0, time.UTC)
+	if !open[0].Start.Equal(wednesday) || !open[0].End.Equal(wednesday.Add(2*time.Hour)) {
+		t.Errorf("expected Wednesday's blocks to be one window: %+v", open[0])
+	}
+	if friday := open[2]; friday.End.Sub(friday.Start) != time.Hour {
+		t.Errorf("expected Friday's VOD block to be left out: %+v", friday)
+	}
+
+	both := Overlap(open, OpenWindows(ahead, ahead.Now()))
+	if len(both) != 5 {
+		t.Fatalf("wrong amount of overlapping windows: %d != 5: %+v", len(both), both)
+	}
+	for _, w := range both {
+		if w.Start.In(time.UTC).Hour() != 16 || w.End.Sub(w.Start) != time.Hour {
+			t.Errorf("expected only 4 to 5 PM UTC to overlap: %+v", w)
+		}
+	}
+
+	if !utc.OpenBetween(wednesday, wednesday.Add(2*time.Hour)) {
+		t.Error("expected Wednesday to be open from 4 to 6 PM")
+	}
+	if utc.OpenBetween(wednesday.AddDate(0, 0, 2), wednesday.AddDate(0, 0, 2).Add(2*time.Hour)) {
+		t.Error("expected Friday not to be open over the VOD")
+	}
+	if !ahead.OpenBetween(wednesday, wednesday.Add(time.Hour)) || ahead.OpenBetween(wednesday, wednesday.Add(2*time.Hour)) {
+		t.Error("expected the week an hour ahead to be open from 4 to 5 PM UTC, and no later")
+	}
+}
+
+func TestPickLineup(t *testing.T) {
+	players := []Player{
+		{Name: "Taub", Role: "Tanks"},
+		{Name: "Tydra", Role: "Supports"},
+		{Name: "Lyar", Role: "Flex"},
+		{Name: "Nub", Role: "Tanks"},
+		{Name: "Kirby", Role: "Tanks"},
+		{Name: "Coach", Role: "Coaches"},
+	}
+	answers := []string{"Maybe", "Maybe", "Yes", "Yes", "Yes", "Yes"}
+	for i := range players {
+		c := testWeek(time.UTC, 16, 1, 1).Container
+		c[0][0].Value = answers[i]
+		players[i].Container = c
+	}
+	lineup := Lineup{"Tanks": 2, "Supports": 2}
+
+	pick := PickLineup(players, 0, 0, lineup, []string{"Kirby"}, nil)
+	// yeses in the lineup's roles are picked first, then flex players, then maybes
+	want := []Pick{
+		{Player: "Kirby", Role: "Tanks"},
+		{Player: "Nub", Role: "Tanks"},
+		{Player: "Lyar", Role: "Supports", Flex: true},
+		{Player: "Tydra", Role: "Supports", Maybe: true},
+	}
+	if !reflect.DeepEqual(pick.Starters, want) {
+		t.Errorf("wrong starters: %+v", pick.Starters)
+	}
+	if len(pick.Missing) != 0 {
+		t.Errorf("expected a full lineup: %+v", pick.Missing)
+	}
+	if !reflect.DeepEqual(pick.Subs, []Pick{{Player: "Taub", Role: "Tanks", Maybe: true}}) {
+		t.Errorf("expected Taub to be a sub: %+v", pick.Subs)
+	}
+
+	// without a preferred starter, Kirby's no-shows put Nub ahead of him
+	pick = PickLineup(players, 0, 0, Lineup{"Tanks": 1}, nil, map[string]float64{"Kirby": 0.5, "Nub": 0.9})
+	if len(pick.Starters) != 1 || pick.Starters[0].Player != "Nub" {
+		t.Errorf("expected Nub to start: %+v", pick.Starters)
+	}
+	var subs []string
+	for _, p := range pick.Subs {
+		subs = append(subs, p.Player)
+	}
+	if !reflect.DeepEqual(subs, []string{"Lyar", "Kirby", "Taub"}) {
+		t.Errorf("wrong subs: %+v", pick.Subs)
+	}
+
+	pick = PickLineup(players[:1], 0, 0, lineup, nil, nil)
+	if len(pick.Starters) != 1 || !reflect.DeepEqual(pick.Missing, Lineup{"Tanks": 1, "Supports": 2}) {
+		t.Errorf("expected missing roles to be flagged: %+v", pick)
+	}
+}
@@ -0,0 +1,33 @@
+package schedule
+
+import "time"
+
+// Source is where a schedule's week, players and activities are kept.
+type Source interface {
+	// ID identifies the source; schedules are cached and looked up by it.
+	ID() string
+	// Link returns a link people can follow to view the source, or an empty string if there isn't one.
+	Link() string
+	// LastModified returns when the source was last changed.
+	LastModified() (time.Time, error)
+	// ValidActivities returns the activities blocks in the week can be set to.
+	ValidActivities() ([]string, error)
+	// Fetch reads the week and players, using activities to find where the week ends.
+	Fetch(activities []string) (Week, []Player, error)
+	// Write saves changes to cells on the source.
+	Write(edits []Edit) error
+}
+
+// Edit is a change to one cell on a schedule.
+type Edit struct {
+	// Grid is what the cell belongs to: WeekGrid, or the name of a player.
+	Grid   string
+	Row    uint
+	Column uint
+	Value  string
+	// Note is whether Value replaces the cell's note instead of its value.
+	Note bool
+}
+
+// WeekGrid is the grid name edits to the week schedule are made on.
+const WeekGrid = "Weekly Schedule"
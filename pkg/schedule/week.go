@@ -1,24 +1,79 @@
 package schedule
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bigheadgeorge/thonky2/pkg/schedule/utils"
 )
 
+// now is replaced in tests.
+var now = time.Now
+
 // Week stores the schedule for the week.
 type Week struct {
 	Date     string
 	Days     [7]string
 	Timezone string
-	// StartTime is the hour (PM) that the schedule starts at.
+	// Location is the timezone block times are in. If it's nil, Timezone is used if it names a zone.
+	Location *time.Location `json:"-"`
+	// StartTime is the hour (24-hour) that the first block of a day starts at.
 	StartTime int
 	// BlockLength is the length of an activity block in hours.
 	BlockLength int
 	Container
 }
 
+// abbreviations are the timezones people tend to write on their sheets instead of zone names.
+var abbreviations = map[string]string{
+	"PST": "America/Los_Angeles", "PDT": "America/Los_Angeles", "PT": "America/Los_Angeles",
+	"MST": "America/Denver", "MDT": "America/Denver", "MT": "America/Denver",
+	"CST": "America/Chicago", "CDT": "America/Chicago", "CT": "America/Chicago",
+	"EST": "America/New_York", "EDT": "America/New_York", "ET": "America/New_York",
+	"GMT": "Europe/London", "BST": "Europe/London",
+	"CET": "Europe/Berlin", "CEST": "Europe/Berlin",
+	"AEST": "Australia/Sydney", "AEDT": "Australia/Sydney",
+}
+
+// LoadLocation loads a timezone from an IANA zone name, ex. America/Los_Angeles, or a common abbreviation like PST.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if zone, ok := abbreviations[strings.ToUpper(name)]; ok {
+		name = zone
+	}
+	if name == "" || strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("no timezone given")
+	}
+	return time.LoadLocation(name)
+}
+
+// location returns the timezone the week is in, falling back to the host's timezone if there isn't one.
+func (w *Week) location() *time.Location {
+	if w.Location != nil {
+		return w.Location
+	}
+	if loc, err := LoadLocation(w.Timezone); err == nil {
+		return loc
+	}
+	return time.Local
+}
+
+// Zone returns the name of the timezone block times are in.
+func (w *Week) Zone() string {
+	if w.Location == nil && w.Timezone != "" {
+		return w.Timezone
+	}
+	return w.location().String()
+}
+
+// Now returns the current time in the week's timezone.
+func (w *Week) Now() time.Time {
+	return now().In(w.location())
+}
+
 // ActivitiesOn returns the activities for a given day.
 func (w *Week) ActivitiesOn(day int) []string {
 	return w.Values()[day]
@@ -48,7 +103,185 @@ func (w *Week) Weekday(day int) int {
 	return utils.Weekday(day)
 }
 
-// Today returns today based on whether Sunday is first or not.
+// WeekdayOf returns the day of the week a day on the sheet is. It's the opposite of Weekday.
+func (w *Week) WeekdayOf(day int) time.Weekday {
+	if strings.HasPrefix(w.Days[0], "Sunday") {
+		return time.Weekday(day)
+	}
+	return time.Weekday((day + 1) % 7)
+}
+
+// Today returns today in the week's timezone, based on whether Sunday is first or not.
 func (w *Week) Today() int {
-	return w.Weekday(int(time.Now().Weekday()))
+	return w.Weekday(int(w.Now().Weekday()))
+}
+
+var dateRe = regexp.MustCompile(`(\d{1,2})/(\d{1,2})`)
+
+// DayDate returns midnight at the start of a day on the week in the week's timezone.
+// Dates are read from the day names, ex. "Monday, 10/08", and the year is whichever puts the date closest to now.
+// If a day doesn't have a date, the day in the current week is used.
+func (w *Week) DayDate(day int) time.Time {
+	current := w.Now()
+	if match := dateRe.FindStringSubmatch(w.Days[day]); match != nil {
+		month, _ := strconv.Atoi(match[1])
+		date, _ := strconv.Atoi(match[2])
+		var closest time.Time
+		for year := current.Year() - 1; year <= current.Year()+1; year++ {
+			t := time.Date(year, time.Month(month), date, 0, 0, 0, 0, current.Location())
+			if closest.IsZero() || abs(t.Sub(current)) < abs(closest.Sub(current)) {
+				closest = t
+			}
+		}
+		return closest
+	}
+	midnight := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, current.Location())
+	return midnight.AddDate(0, 0, day-w.Today())
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// BlockStart returns the time a block on a day starts at.
+func (w *Week) BlockStart(day, block int) time.Time {
+	d := w.DayDate(day)
+	return time.Date(d.Year(), d.Month(), d.Day(), w.StartTime+block*w.BlockLength, 0, 0, 0, d.Location())
+}
+
+// BlockEnd returns the time a block on a day ends at.
+func (w *Week) BlockEnd(day, block int) time.Time {
+	return w.BlockStart(day, block+1)
+}
+
+// BlockHours formats the hours a block covers, ex. "7-8pm" or "11am-12pm".
+func (w *Week) BlockHours(day, block int) string {
+	start, end := w.BlockStart(day, block), w.BlockEnd(day, block)
+	if start.Format("pm") == end.Format("pm") {
+		return start.Format("3") + "-" + end.Format("3pm")
+	}
+	return start.Format("3pm") + "-" + end.Format("3pm")
+}
+
+// BlockIndex returns the block on a day that starts at an hour (24-hour).
+// Hours before noon that no block starts at are tried in the afternoon, so 4 finds a 4 PM block.
+func (w *Week) BlockIndex(day, hour int) (int, bool) {
+	hours := []int{hour}
+	if hour < 12 {
+		hours = append(hours, hour+12)
+	}
+	for _, h := range hours {
+		for i := range w.Container[day] {
+			if w.BlockStart(day, i).Hour() == h%24 {
+				return i, true
+			}
+		}
+	}
+	return -1, false
+}
+
+// BlockRange returns the blocks on a day covered by a range of hours, as a start and end index.
+// If start and end are equal, only the block starting at start is covered.
+func (w *Week) BlockRange(day, start, end int) (int, int, error) {
+	first, ok := w.BlockIndex(day, start)
+	if !ok {
+		return -1, -1, fmt.Errorf("no block starts at %d", start)
+	}
+	if start == end {
+		return first, first + 1, nil
+	}
+	if (end-start)%w.BlockLength != 0 {
+		return -1, -1, fmt.Errorf("range does not conform to block length")
+	}
+	last := first + (end-start)/w.BlockLength
+	if last > len(w.Container[day]) {
+		return -1, -1, fmt.Errorf("range goes past the last block")
+	}
+	return first, last, nil
+}
+
+// BlockAt returns the day and block that start at a time.
+func (w *Week) BlockAt(t time.Time) (day, block int, ok bool) {
+	for day = range w.Container {
+		for block = range w.Container[day] {
+			if w.BlockStart(day, block).Equal(t) {
+				return day, block, true
+			}
+		}
+	}
+	return -1, -1, false
+}
+
+var hoursRe = regexp.MustCompile(`^(\d{1,2})(?::00)?(am|pm)?(?:-(\d{1,2})(?::00)?(am|pm)?)?$`)
+
+// ParseHours parses an hour or a range of hours into 24-hour time, ex. 4, 4-6, 4pm-6pm, 10am-2pm or 16-18.
+// Ranges that go past midnight end after 24. ambiguous is true if the start was a bare hour before noon,
+// which could be in the morning or the afternoon.
+func ParseHours(s string) (start, end int, ambiguous bool, err error) {
+	match := hoursRe.FindStringSubmatch(strings.ToLower(s))
+	if match == nil {
+		return 0, 0, false, fmt.Errorf("invalid time range %q", s)
+	}
+	startSuffix, endSuffix := match[2], match[4]
+	if match[3] == "" {
+		endSuffix = startSuffix
+	}
+	start, _ = strconv.Atoi(match[1])
+	end = start
+	if match[3] != "" {
+		end, _ = strconv.Atoi(match[3])
+	}
+
+	if end, err = hour(end, endSuffix); err != nil {
+		return 0, 0, false, err
+	}
+	if startSuffix == "" && endSuffix != "" {
+		// "4-6pm" means both are PM, unless that puts the start after the end, ex. "10-2pm"
+		startSuffix = endSuffix
+		if h, err := hour(start, startSuffix); err == nil && h > end && endSuffix == "pm" {
+			startSuffix = "am"
+		}
+	}
+	if start, err = hour(start, startSuffix); err != nil {
+		return 0, 0, false, err
+	}
+	ambiguous = startSuffix == "" && start > 0 && start < 12
+
+	if end < start {
+		if endSuffix == "" && end < 12 {
+			// "11-1" runs past noon
+			end += 12
+		} else if endSuffix != "" {
+			// "10pm-1am" runs past midnight
+			end += 24
+		}
+	}
+	if end < start {
+		return 0, 0, false, fmt.Errorf("invalid time range %q: first time > second time", s)
+	}
+	return start, end, ambiguous, nil
+}
+
+// hour converts an hour with an optional am/pm suffix into 24-hour time.
+func hour(h int, suffix string) (int, error) {
+	switch suffix {
+	case "":
+		if h > 24 {
+			return 0, fmt.Errorf("invalid hour %d", h)
+		}
+		return h, nil
+	case "am", "pm":
+		if h < 1 || h > 12 {
+			return 0, fmt.Errorf("invalid hour %d%s", h, suffix)
+		}
+		h %= 12
+		if suffix == "pm" {
+			h += 12
+		}
+		return h, nil
+	}
+	return 0, fmt.Errorf("invalid suffix %q", suffix)
 }
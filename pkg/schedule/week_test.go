@@ -0,0 +1,151 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bigheadgeorge/spreadsheet"
+)
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		s          string
+		start, end int
+		ambiguous  bool
+	}{
+		{"4", 4, 4, true},
+		{"4-6", 4, 6, true},
+		{"4pm", 16, 16, false},
+		{"4pm-6pm", 16, 18, false},
+		{"4-6pm", 16, 18, false},
+		{"10-2pm", 10, 14, false},
+		{"10am-2pm", 10, 14, false},
+		{"16-18", 16, 18, false},
+		{"16:00-18:00", 16, 18, false},
+		{"12am-2am", 0, 2, false},
+		{"11-1", 11, 13, true},
+		{"10pm-1am", 22, 25, false},
+		{"0-2", 0, 2, false},
+	}
+	for _, test := range tests {
+		start, end, ambiguous, err := ParseHours(test.s)
+		if err != nil {
+			t.Errorf("%s: %s", test.s, err)
+			continue
+		}
+		if start != test.start || end != test.end || ambiguous != test.ambiguous {
+			t.Errorf("%s: expected %d-%d (ambiguous %t), got %d-%d (ambiguous %t)", test.s, test.start, test.end, test.ambiguous, start, end, ambiguous)
+		}
+	}
+
+	for _, bad := range []string{"", "four", "13pm", "0am", "4-", "25", "18-16"} {
+		if _, _, _, err := ParseHours(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+// testWeek returns a week of the given blocks per day, with days dated from Monday 10/08.
+func testWeek(loc *time.Location, startTime, blockLength, blocks int) *Week {
+	w := &Week{
+		Days:        [7]string{"Monday, 10/08", "Tuesday, 10/09", "Wednesday, 10/10", "Thursday, 10/11", "Friday, 10/12", "Saturday, 10/13", "Sunday, 10/14"},
+		Location:    loc,
+		StartTime:   startTime,
+		BlockLength: blockLength,
+		Container:   make(Container, 7),
+	}
+	for i := range w.Container {
+		for j := 0; j < blocks; j++ {
+			w.Container[i] = append(w.Container[i], &spreadsheet.Cell{Row: uint(i), Column: uint(j)})
+		}
+	}
+	return w
+}
+
+// setNow makes now return t, and returns a function to put it back.
+func setNow(t time.Time) func() {
+	now = func() time.Time { return t }
+	return func() { now = time.Now }
+}
+
+func TestBlockTimes(t *testing.T) {
+	la, err := LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("no timezone database:", err)
+	}
+	berlin, err := LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no timezone database:", err)
+	}
+	// Wednesday 10/10/2018 at noon in Los Angeles
+	defer setNow(time.Date(2018, 10, 10, 19, 0, 0, 0, time.UTC))()
+
+	w := testWeek(la, 16, 1, 6)
+	if today := w.Today(); today != 2 {
+		t.Errorf("expected today to be Wednesday (2), got %d", today)
+	}
+	start := w.BlockStart(2, 0)
+	if expected := time.Date(2018, 10, 10, 23, 0, 0, 0, time.UTC); !start.Equal(expected) {
+		t.Errorf("expected Wednesday's first block to start at %s, got %s", expected, start.UTC())
+	}
+	if end := w.BlockEnd(6, 5); !end.Equal(time.Date(2018, 10, 14, 22, 0, 0, 0, la)) {
+		t.Errorf("expected Sunday's last block to end at 10 PM, got %s", end)
+	}
+	if day, block, ok := w.BlockAt(start); !ok || day != 2 || block != 0 {
+		t.Errorf("expected BlockAt to find (2, 0), got (%d, %d, %t)", day, block, ok)
+	}
+
+	// it's already Thursday in Berlin
+	w = testWeek(berlin, 10, 2, 6)
+	defer setNow(time.Date(2018, 10, 10, 23, 0, 0, 0, time.UTC))()
+	if today := w.Today(); today != 3 {
+		t.Errorf("expected today to be Thursday (3) in Berlin, got %d", today)
+	}
+	if last := w.BlockStart(0, 5); last.Hour() != 20 || last.Day() != 8 {
+		t.Errorf("expected Monday's last block to start at 20:00 on the 8th, got %s", last)
+	}
+	// blocks that run past midnight start the next day
+	w = testWeek(berlin, 22, 1, 4)
+	if late := w.BlockStart(0, 3); late.Day() != 9 || late.Hour() != 1 {
+		t.Errorf("expected Monday's last block to start at 01:00 on the 9th, got %s", late)
+	}
+}
+
+func TestBlockRange(t *testing.T) {
+	w := testWeek(time.UTC, 16, 1, 6)
+	tests := []struct {
+		start, end  int
+		first, last int
+	}{
+		{4, 4, 0, 1},
+		{16, 16, 0, 1},
+		{4, 6, 0, 2},
+		{16, 22, 0, 6},
+		{9, 9, 5, 6},
+	}
+	for _, test := range tests {
+		first, last, err := w.BlockRange(0, test.start, test.end)
+		if err != nil {
+			t.Errorf("%d-%d: %s", test.start, test.end, err)
+			continue
+		}
+		if first != test.first || last != test.last {
+			t.Errorf("%d-%d: expected blocks %d-%d, got %d-%d", test.start, test.end, test.first, test.last, first, last)
+		}
+	}
+
+	for _, bad := range [][2]int{{3, 4}, {4, 11}, {15, 16}} {
+		if _, _, err := w.BlockRange(0, bad[0], bad[1]); err == nil {
+			t.Errorf("expected an error for %d-%d", bad[0], bad[1])
+		}
+	}
+
+	// morning blocks are found before the afternoon
+	w = testWeek(time.UTC, 8, 2, 6)
+	if first, last, err := w.BlockRange(0, 10, 14); err != nil || first != 1 || last != 3 {
+		t.Errorf("expected 10-14 to be blocks 1-3, got %d-%d (%v)", first, last, err)
+	}
+	if _, _, err := w.BlockRange(0, 10, 11); err == nil {
+		t.Error("expected an error for a range that doesn't fit the block length")
+	}
+}
@@ -12,19 +12,25 @@ import (
 )
 
 // State holds all of the services needed for thonky to operate glued together.
+// Commands should talk to Discord through Messenger, which is usually the same as Session.
 type State struct {
 	Session   *discordgo.Session
-	DB        *db.Handler
+	Messenger Messenger
+	DB        db.Store
 	Client    *http.Client
 	Service   *spreadsheet.Service
 	Schedules map[string]*schedule.Schedule
+	// Voice is who's in which voice channel, for taking attendance.
+	Voice *Voice
+	// CalendarURL is where team calendar feeds are served, ex. "https://thonky.example.com/calendar/", or empty if
+	// they aren't.
+	CalendarURL string
 }
 
 // FindTeam finds a team in a channel in a guild.
 func (s *State) FindTeam(guildID, channelID string) team.Team {
-	var t team.Team
-	err := s.DB.Get(&t, "SELECT * FROM teams WHERE server_id = $1 AND $2 = ANY(channels)", guildID, channelID)
-	if err != nil && err == sql.ErrNoRows {
+	t, err := s.DB.ChannelTeam(guildID, channelID)
+	if err == sql.ErrNoRows {
 		return s.GuildTeam(guildID)
 	}
 	return t
@@ -32,8 +38,7 @@ func (s *State) FindTeam(guildID, channelID string) team.Team {
 
 // GuildTeam returns the guild's team.
 func (s *State) GuildTeam(guildID string) team.Team {
-	var t team.Team
-	err := s.DB.Get(&t, "SELECT * FROM teams WHERE server_id = $1 AND 0 = LENGTH(team_name)", guildID)
+	t, err := s.DB.GuildTeam(guildID)
 	if err != nil {
 		return team.Team{}
 	}
@@ -44,17 +49,44 @@ func (s *State) GuildTeam(guildID string) team.Team {
 func (s *State) FindSchedule(guildID, channelID string) *schedule.Schedule {
 	team := s.FindTeam(guildID, channelID)
 	if team.ID == 0 {
-		s.Session.ChannelMessageSend(channelID, "No team in this channel or server.")
+		s.Messenger.ChannelMessageSend(channelID, "No team in this channel or server.")
 		return nil
 	}
 	spreadsheetID, err := s.DB.SpreadsheetID(team.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			s.Session.ChannelMessageSend(channelID, "No spreadsheet for this team.")
+			s.Messenger.ChannelMessageSend(channelID, "No spreadsheet for this team.")
 		} else {
-			s.Session.ChannelMessageSend(channelID, "Error grabbing spreadsheet ID: "+err.Error())
+			s.Messenger.ChannelMessageSend(channelID, "Error grabbing spreadsheet ID: "+err.Error())
 		}
 		return nil
 	}
 	return s.Schedules[spreadsheetID]
 }
+
+// TeamSchedule returns a team's schedule, or nil if it doesn't have one or it hasn't been loaded.
+func (s *State) TeamSchedule(teamID int) *schedule.Schedule {
+	spreadsheetID, err := s.DB.SpreadsheetID(teamID)
+	if err != nil {
+		return nil
+	}
+	return s.Schedules[spreadsheetID]
+}
+
+// TeamName returns a team's name, or its guild's name if it's the guild's team.
+func (s *State) TeamName(t team.Team) string {
+	if t.Guild() {
+		if g, err := s.Messenger.Guild(t.GuildID); err == nil {
+			return g.Name
+		}
+	}
+	return t.Name
+}
+
+// BotID returns the bot's user ID, or an empty string if there's no session.
+func (s *State) BotID() string {
+	if s.Session == nil || s.Session.State.User == nil {
+		return ""
+	}
+	return s.Session.State.User.ID
+}
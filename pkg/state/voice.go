@@ -0,0 +1,44 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Voice keeps who's in which voice channel, from Discord's voice state updates.
+type Voice struct {
+	mu sync.Mutex
+	// channels are the voice channels users are in, by guild and user ID.
+	channels map[[2]string]string
+}
+
+// NewVoice returns a Voice with nobody in voice.
+func NewVoice() *Voice {
+	return &Voice{channels: make(map[[2]string]string)}
+}
+
+// Update records a user joining, moving between or leaving voice channels in a guild.
+func (v *Voice) Update(vs *discordgo.VoiceState) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := [2]string{vs.GuildID, vs.UserID}
+	if vs.ChannelID == "" {
+		delete(v.channels, key)
+	} else {
+		v.channels[key] = vs.ChannelID
+	}
+}
+
+// In returns the IDs of the users in a voice channel in a guild.
+func (v *Voice) In(guildID, channelID string) map[string]bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	users := make(map[string]bool)
+	for key, channel := range v.channels {
+		if key[0] == guildID && channel == channelID {
+			users[key[1]] = true
+		}
+	}
+	return users
+}
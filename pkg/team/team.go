@@ -1,16 +1,112 @@
 package team
 
-import "github.com/lib/pq"
+import (
+	"database/sql"
+	"strings"
+)
 
 // Team holds the config for a team in a guild.
 type Team struct {
-	ID       int            `db:"id"`
-	GuildID  string         `db:"server_id"`
-	Name     string         `db:"team_name"`
-	Channels pq.StringArray `db:"channels"`
+	ID       int      `db:"id"`
+	GuildID  string   `db:"server_id"`
+	Name     string   `db:"team_name"`
+	Channels []string `db:"channels"`
 }
 
 // Guild returns whether this team represents an entire Discord guild or not
 func (t *Team) Guild() bool {
 	return len(t.Name) == 0
 }
+
+// Roles holds the Discord roles that grant each permission level on a team.
+type Roles struct {
+	Team     int      `db:"team"`
+	Players  []string `db:"player_roles"`
+	Captains []string `db:"captain_roles"`
+	Admins   []string `db:"admin_roles"`
+}
+
+// PlayerLink links a Discord user to their name on a team's schedule.
+type PlayerLink struct {
+	Team   int    `db:"team"`
+	UserID string `db:"user_id"`
+	Player string `db:"player"`
+	// DMReminders is whether the user wants a DM before blocks they're available for.
+	DMReminders bool `db:"dm_reminders"`
+}
+
+// SchedulePost is a message in a channel that's kept up to date with a team's schedule.
+type SchedulePost struct {
+	Team      int    `db:"team"`
+	ChannelID string `db:"channel_id"`
+	MessageID string `db:"message_id"`
+	// Kind is what the message shows, either "week" or "today".
+	Kind string `db:"kind"`
+}
+
+// ScheduleConfig is where a team's schedule comes from and how it's read.
+type ScheduleConfig struct {
+	Team          int    `db:"team"`
+	SpreadsheetID string `db:"spreadsheet_id"`
+	// Source is where the schedule is kept, ex. "sheets" or "file".
+	Source string `db:"source"`
+	// Layout is where everything is on the spreadsheet as JSON, or nil for the default layout.
+	Layout []byte `db:"layout"`
+	// Timezone is the IANA timezone the schedule is in, or empty to read it from the sheet.
+	Timezone       string `db:"timezone"`
+	UpdateInterval int    `db:"update_interval"`
+}
+
+// ReminderConfig holds when a team is reminded of activities coming up.
+type ReminderConfig struct {
+	Team            int            `db:"team"`
+	Activities      []string       `db:"activities"`
+	AnnounceChannel string         `db:"announce_channel"`
+	RoleMention     sql.NullString `db:"role_mention"`
+	Intervals       []int64        `db:"intervals"`
+}
+
+// NagConfig holds when a team chases players who haven't filled in their availability.
+type NagConfig struct {
+	Team int `db:"team"`
+	// Hours are the hours of the day, in the team's timezone, that players are nagged at.
+	Hours []int64 `db:"hours"`
+	// Days is how many days ahead, including today, players need to have filled in.
+	Days int `db:"days"`
+	// Channel is where missing players are pinged. If it's empty, linked players are sent a DM instead.
+	Channel string `db:"channel"`
+	// DigestChannel is where managers are told who's still missing, if it's set.
+	DigestChannel string `db:"digest_channel"`
+}
+
+// Tournament sites teams can be in tournaments on.
+const (
+	Battlefy    = "battlefy"
+	Gamebattles = "gamebattles"
+)
+
+// Tournament is the tournament a team is in on a tournament site.
+type Tournament struct {
+	Team int `db:"team"`
+	// Site is Battlefy or Gamebattles.
+	Site string `db:"-"`
+	Link string `db:"tournament_link"`
+	// TeamID is the team's ID on the site, or empty if it hasn't been given.
+	TeamID string `db:"team_id"`
+}
+
+// LFSListing is a team on the bot-wide looking for scrim board, which teams in every guild can search.
+type LFSListing struct {
+	Team   int    `db:"team"`
+	Region string `db:"region"`
+	// MinSR and MaxSR are the range of skill ratings the team plays at.
+	MinSR int `db:"min_sr"`
+	MaxSR int `db:"max_sr"`
+	// ChannelID is where the team is sent requests for scrims.
+	ChannelID string `db:"channel_id"`
+}
+
+// Matches returns whether two listed teams play in the same region at overlapping skill ratings.
+func (l LFSListing) Matches(other LFSListing) bool {
+	return strings.EqualFold(l.Region, other.Region) && l.MinSR <= other.MaxSR && other.MinSR <= l.MaxSR
+}